@@ -0,0 +1,207 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AutoStop returns a stop function for the given value, which can be
+// used to register third-party clients uniformly. The value has to
+// implement one of the following methods (in order of precedence):
+//
+//	Shutdown(context.Context) error
+//	Close(context.Context) error
+//	Close() error
+//	Stop()
+//
+// AutoStop panics if the value does not implement any of them.
+func AutoStop(v any) Func {
+	switch c := v.(type) {
+	case interface{ Shutdown(context.Context) error }:
+		return c.Shutdown
+	case interface{ Close(context.Context) error }:
+		return c.Close
+	case interface{ Close() error }:
+		return CloseFunc(c.Close)
+	case interface{ Stop() }:
+		return StopFunc(c.Stop)
+	default:
+		panic(fmt.Sprintf("scope: cannot stop value of type %T", v))
+	}
+}
+
+// StopFunc converts a function without context and error into a Func.
+// See CloseFunc for details.
+func StopFunc(f func()) Func {
+	return CloseFunc(func() error {
+		f()
+		return nil
+	})
+}
+
+// CloseFunc converts a function without context into a Func. Since the
+// function cannot observe a cancellation, it is called in a separate
+// Goroutine and the returned Func returns the context's error as soon
+// as the context is done. Use Timeout to enforce a deadline. Within a
+// scope, the Goroutine is run by the scope's executor (see Executor).
+func CloseFunc(f func() error) Func {
+	return func(ctx context.Context) error {
+		if ctx.Done() == nil {
+			return f()
+		}
+
+		done := make(chan error, 1)
+		call := func() { done <- f() }
+		if s := scopeFromContext(ctx); s != nil {
+			s.spawn(call)
+		} else {
+			go call()
+		}
+
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Timeout returns a Func which calls f with a context that is cancelled
+// after the given duration.
+func Timeout(d time.Duration, f Func) Func {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+		return f(ctx)
+	}
+}
+
+// Retry returns a Func which calls f until it succeeds, e.g. to ride out
+// transient failures of a start or stop function. The delays between the
+// attempts are defined by the given backoff (see Backoff). The jitter
+// uses the source of randomness of the scope, which calls the returned
+// function (see WithRand). If f still fails after MaxAttempts retries,
+// the errors of all attempts are returned as Errors wrapped with
+// ErrRetriesExhausted. With MaxAttempts of zero, f is retried until the
+// context is done. If the context is done while waiting for the next
+// attempt, the errors so far are returned along with the context's
+// error.
+func Retry(f Func, b Backoff) Func {
+	return func(ctx context.Context) error {
+		rand := newRandom(nil)
+		if s := scopeFromContext(ctx); s != nil {
+			rand = s.rand
+		}
+
+		var errs Errors
+		for retry := 0; ; retry++ {
+			err := f(ctx)
+			if err == nil {
+				return nil
+			}
+			errs.append(err)
+			if b.MaxAttempts > 0 && retry >= b.MaxAttempts {
+				return fmt.Errorf("%w after %d retries: %w", ErrRetriesExhausted, retry, errs)
+			}
+
+			timer := time.NewTimer(b.delay(retry, rand))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				errs.append(ctx.Err())
+				return errs
+			}
+		}
+	}
+}
+
+// FromChanFunc converts a function, which observes a stop channel instead
+// of a context, into a Func. The stop channel is the context's Done
+// channel.
+func FromChanFunc(f func(stop <-chan struct{}) error) Func {
+	return func(ctx context.Context) error {
+		return f(ctx.Done())
+	}
+}
+
+// ToChanFunc converts a Func into a function, which observes a stop
+// channel instead of a context. The Func's context is cancelled when
+// the stop channel is closed.
+func ToChanFunc(f Func) func(stop <-chan struct{}) error {
+	return func(stop <-chan struct{}) error {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			select {
+			case <-stop:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		return f(ctx)
+	}
+}
+
+// ChanService returns a service, whose start function observes a stop
+// channel instead of a context. The stop channel is closed when the
+// service is stopped or the scope's context is done, whichever happens
+// first. The service's stop function waits until the start function
+// returned. Each run of the service, e.g. after a restart, gets a new
+// stop channel.
+func ChanService(name string, start func(stop <-chan struct{}) error) Service {
+	var (
+		mtx sync.Mutex
+		cur *chanRun // current run, nil before the first start
+	)
+	return Service{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			r := &chanRun{stop: make(chan struct{}), done: make(chan struct{})}
+			mtx.Lock()
+			cur = r
+			mtx.Unlock()
+
+			defer close(r.done)
+			go func() {
+				select {
+				case <-ctx.Done():
+					r.closeStop()
+				case <-r.done:
+				}
+			}()
+			return start(r.stop)
+		},
+		Stop: func(ctx context.Context) error {
+			mtx.Lock()
+			r := cur
+			mtx.Unlock()
+			if r == nil {
+				return nil
+			}
+
+			r.closeStop()
+			select {
+			case <-r.done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+// chanRun holds the channels of a single run of a ChanService.
+type chanRun struct {
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+}
+
+func (r *chanRun) closeStop() {
+	r.once.Do(func() { close(r.stop) })
+}
@@ -0,0 +1,226 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAutoStop(t *testing.T) {
+	errStop := errors.New("stop error")
+
+	cases := []struct {
+		name string
+		v    interface{ wasCalled() bool }
+		err  error
+	}{
+		{name: "shutdown", v: &shutdowner{err: errStop}, err: errStop},
+		{name: "close-ctx", v: &ctxCloser{}},
+		{name: "close", v: &closer{err: errStop}, err: errStop},
+		{name: "stop", v: &stopper{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := AutoStop(c.v)(context.Background()); err != c.err {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !c.v.wasCalled() {
+				t.Fatal("expected stop method to be called")
+			}
+		})
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected panic")
+			}
+		}()
+		AutoStop(struct{}{})
+	})
+}
+
+func TestCloseFunc(t *testing.T) {
+	t.Run("returns", func(t *testing.T) {
+		errClose := errors.New("close error")
+		f := CloseFunc(func() error { return errClose })
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		if err := f(ctx); err != errClose {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		block := make(chan struct{})
+		defer close(block)
+
+		f := Timeout(10*time.Millisecond, StopFunc(func() { <-block }))
+		if err := f(context.Background()); err != context.DeadlineExceeded {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestRetry(t *testing.T) {
+	errTransient := errors.New("transient error")
+	backoff := Backoff{Initial: time.Millisecond, MaxAttempts: 2}
+
+	t.Run("success", func(t *testing.T) {
+		calls := 0
+		f := Retry(func(context.Context) error {
+			if calls++; calls < 3 {
+				return errTransient
+			}
+			return nil
+		}, backoff)
+		if err := f(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if calls != 3 {
+			t.Fatalf("unexpected number of calls: %d", calls)
+		}
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		calls := 0
+		f := Retry(func(context.Context) error {
+			calls++
+			return errTransient
+		}, backoff)
+
+		err := f(context.Background())
+		var errs Errors
+		switch {
+		case !errors.Is(err, ErrRetriesExhausted) || !errors.Is(err, errTransient):
+			t.Fatalf("unexpected error: %v", err)
+		case !errors.As(err, &errs) || errs.Len() != 3:
+			t.Fatalf("unexpected errors: %v", errs)
+		case calls != 3:
+			t.Fatalf("unexpected number of calls: %d", calls)
+		}
+	})
+
+	t.Run("context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		f := Retry(func(context.Context) error {
+			cancel()
+			return errTransient
+		}, Backoff{Initial: time.Hour})
+
+		err := f(ctx)
+		if !errors.Is(err, context.Canceled) || !errors.Is(err, errTransient) || errors.Is(err, ErrRetriesExhausted) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+type recorder struct{ called bool }
+
+func (r *recorder) wasCalled() bool { return r.called }
+
+type shutdowner struct {
+	recorder
+	err error
+}
+
+func (s *shutdowner) Shutdown(context.Context) error {
+	s.called = true
+	return s.err
+}
+
+type ctxCloser struct{ recorder }
+
+func (c *ctxCloser) Close(context.Context) error {
+	c.called = true
+	return nil
+}
+
+type closer struct {
+	recorder
+	err error
+}
+
+func (c *closer) Close() error {
+	c.called = true
+	return c.err
+}
+
+type stopper struct{ recorder }
+
+func (s *stopper) Stop() { s.called = true }
+
+func TestChanFuncs(t *testing.T) {
+	t.Run("from", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		f := FromChanFunc(func(stop <-chan struct{}) error {
+			<-stop
+			return nil
+		})
+		cancel()
+		if err := f(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("to", func(t *testing.T) {
+		stop := make(chan struct{})
+		f := ToChanFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		close(stop)
+		if err := f(stop); err != context.Canceled {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestChanService(t *testing.T) {
+	t.Run("close", func(t *testing.T) {
+		stopped := make(chan struct{})
+		s := newScope(t)
+		s.Start(ChanService("legacy", func(stop <-chan struct{}) error {
+			<-stop
+			close(stopped)
+			return nil
+		}))
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		select {
+		case <-stopped:
+		default:
+			t.Fatal("expected service to be stopped")
+		}
+	})
+
+	t.Run("restart", func(t *testing.T) {
+		var runs atomic.Int32
+		s := newScope(t)
+		s.Start(ChanService("legacy", func(stop <-chan struct{}) error {
+			runs.Add(1)
+			<-stop
+			return nil
+		}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		for range 2 {
+			if err := s.Admin().Restart(ctx, "legacy"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := runs.Load(); n != 3 {
+			t.Fatalf("unexpected number of runs: %d", n)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Admin provides runtime control of the services of a scope, e.g. to
+// build an HTTP or gRPC admin endpoint. The services are addressed by
+// their names (see Service). If several services have the same name,
+// an operation applies to all of them.
+type Admin struct {
+	s *Scope
+}
+
+// Admin returns the control interface of the scope.
+func (s *Scope) Admin() *Admin {
+	return &Admin{s: s}
+}
+
+// Tasks returns the registered tasks in order of registration (see
+// Scope.Snapshot).
+func (a *Admin) Tasks() []TaskSnapshot {
+	return a.s.Snapshot()
+}
+
+// Stop stops the service for good: its stop function is called, its
+// context is cancelled with the cause Stopped, and Stop waits until its
+// start function returned. The service is neither restarted nor stopped
+// again when the scope is closed.
+func (a *Admin) Stop(ctx context.Context, name string) error {
+	return a.apply(name, func(t *task) error {
+		t.retired.Store(true)
+		if t.state.is(paused) {
+			// The stop function was already called.
+			a.s.setQuarantine(t, "")
+			t.setState(succeeded)
+			t.finish()
+			a.s.notify()
+			return nil
+		}
+
+		a.s.log(slog.LevelInfo, "stopping task", "task", t)
+		return a.s.halt(ctx, t, "stop", Stopped{})
+	})
+}
+
+// Restart restarts the service (see Scope.RollingRestart). Paused and
+// quarantined services cannot be restarted, they have to be resumed.
+func (a *Admin) Restart(ctx context.Context, name string) error {
+	return a.s.RollingRestart(ctx, []string{name}, 1)
+}
+
+// Pause stops the service until it is resumed (see Resume): its stop
+// function is called, its context is cancelled with the cause Paused,
+// and Pause waits until its start function returned. If the scope is
+// closed before the service is resumed, the service is not stopped
+// again.
+func (a *Admin) Pause(ctx context.Context, name string) error {
+	return a.pause(ctx, name, "pause", "")
+}
+
+// Quarantine pauses the service like Pause and records the reason, e.g.
+// because the service misbehaves and has to be inspected. The reason is
+// available in the snapshot of the service (see Tasks).
+func (a *Admin) Quarantine(ctx context.Context, name, reason string) error {
+	if reason == "" {
+		return fmt.Errorf("scope: no reason to quarantine service %q", name)
+	}
+	return a.pause(ctx, name, "quarantine", reason)
+}
+
+func (a *Admin) pause(ctx context.Context, name, op, reason string) error {
+	return a.apply(name, func(t *task) error {
+		if !t.state.is(running) {
+			return fmt.Errorf("scope: cannot %s service %q, which is %s", op, t, t.state.String())
+		}
+
+		a.s.log(slog.LevelInfo, "pausing task", "task", t, "reason", reason)
+		a.s.setQuarantine(t, reason)
+		if reason != "" {
+			a.s.warn(Warning{Kind: WarnQuarantined, Task: t.info(), Reason: reason})
+		}
+		return a.s.halt(ctx, t, op, Paused{Reason: reason})
+	})
+}
+
+// Resume starts a paused or quarantined service again and waits until
+// it is ready (see Service).
+func (a *Admin) Resume(ctx context.Context, name string) error {
+	return a.apply(name, func(t *task) error {
+		if !t.state.is(paused) {
+			return fmt.Errorf("scope: cannot resume service %q, which is %s", t, t.state.String())
+		}
+
+		a.s.log(slog.LevelInfo, "resuming task", "task", t)
+		a.s.setQuarantine(t, "")
+		return a.s.relaunch(ctx, t, "resume")
+	})
+}
+
+// apply calls f for all started services with the given name.
+func (a *Admin) apply(name string, f func(t *task) error) error {
+	tasks, err := a.s.lookup([]string{name})
+	if err != nil {
+		return err
+	}
+
+	var errs Errors
+	for _, t := range tasks {
+		errs.append(f(t))
+	}
+	return errs.err()
+}
+
+func (s *Scope) setQuarantine(t *task, reason string) {
+	s.mtx.Lock()
+	t.quarantine = reason
+	s.mtx.Unlock()
+}
@@ -0,0 +1,146 @@
+package scope
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAdmin(t *testing.T) {
+	type counters struct {
+		starts atomic.Int32
+		stops  atomic.Int32
+	}
+	service := func(name string, c *counters) Service {
+		return Service{
+			Name: name,
+			Start: func(ctx context.Context) error {
+				c.starts.Add(1)
+				<-ctx.Done()
+				return nil
+			},
+			Stop: func(context.Context) error {
+				c.stops.Add(1)
+				return nil
+			},
+		}
+	}
+	state := func(s *Scope, name string) string {
+		for _, ts := range s.Admin().Tasks() {
+			if ts.Name == name {
+				return ts.State
+			}
+		}
+		return ""
+	}
+
+	t.Run("pause-resume", func(t *testing.T) {
+		s := newScope(t)
+		admin := s.Admin()
+
+		var c counters
+		s.Start(service("svc", &c))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := s.WaitFor(ctx, "svc"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := admin.Pause(ctx, "svc"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if st := state(s, "svc"); st != "paused" {
+			t.Fatalf("unexpected state: %s", st)
+		}
+		if err := admin.Restart(ctx, "svc"); err == nil {
+			t.Fatal("expected paused service not to be restarted")
+		}
+
+		if err := admin.Resume(ctx, "svc"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if st := state(s, "svc"); st != "running" {
+			t.Fatalf("unexpected state: %s", st)
+		}
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := c.starts.Load(); n != 2 {
+			t.Fatalf("unexpected number of starts: %d", n)
+		}
+		if n := c.stops.Load(); n != 2 {
+			t.Fatalf("unexpected number of stops: %d", n)
+		}
+	})
+
+	t.Run("quarantine", func(t *testing.T) {
+		s := newScope(t)
+		admin := s.Admin()
+
+		var c counters
+		task := s.Start(service("svc", &c))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := s.WaitFor(ctx, "svc"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := admin.Quarantine(ctx, "svc", "corrupt cache"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		snap := admin.Tasks()
+		if snap[0].State != "quarantined" || snap[0].Reason != "corrupt cache" {
+			t.Fatalf("unexpected snapshot: %+v", snap[0])
+		}
+
+		// Quarantined services are not stopped again on close.
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := c.stops.Load(); n != 1 {
+			t.Fatalf("unexpected number of stops: %d", n)
+		}
+		<-task.Done()
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		s := newScope(t)
+		admin := s.Admin()
+
+		var c counters
+		task := s.Start(service("svc", &c))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := s.WaitFor(ctx, "svc"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := admin.Stop(ctx, "svc"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := task.Wait(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := admin.Pause(ctx, "svc"); err == nil {
+			t.Fatal("expected stopped service not to be paused")
+		}
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := c.stops.Load(); n != 1 {
+			t.Fatalf("unexpected number of stops: %d", n)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		s := newScope(t)
+		defer closeScope(s)
+
+		if err := s.Admin().Pause(context.Background(), "unknown"); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package scope
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// FileBackoffStore is a BackoffStore, which persists the failures of all
+// services as JSON in a single file. The file is replaced atomically on
+// every change, so it survives crashes of the process.
+type FileBackoffStore struct {
+	path string
+	mtx  sync.Mutex
+}
+
+// NewFileBackoffStore creates a store, which persists the failures in
+// the file with the given path. The file is created on the first save.
+func NewFileBackoffStore(path string) *FileBackoffStore {
+	return &FileBackoffStore{path: path}
+}
+
+// Load returns the number of consecutive failures of the service with
+// the given name.
+func (s *FileBackoffStore) Load(name string) (int, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	m, err := s.read()
+	return m[name], err
+}
+
+// Save stores the number of consecutive failures of the service with
+// the given name. Services without failures are removed from the file.
+func (s *FileBackoffStore) Save(name string, failures int) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	m, err := s.read()
+	if err != nil {
+		return err
+	}
+	if m[name] == failures {
+		return nil
+	}
+	if failures > 0 {
+		m[name] = failures
+	} else {
+		delete(m, name)
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *FileBackoffStore) read() (map[string]int, error) {
+	m := make(map[string]int)
+	data, err := os.ReadFile(s.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return m, nil
+	case err != nil:
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return make(map[string]int), fmt.Errorf("scope: invalid backoff store %s: %w", s.path, err)
+	}
+	return m, nil
+}
@@ -0,0 +1,130 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// The allocation budgets of the core operations. They are enforced by
+// TestAllocBudget, so regressions on the hot paths are noticed early.
+const (
+	goAllocBudget    = 4 // task, done channel, goroutine closure, and goroutine
+	deferAllocBudget = 1 // task
+)
+
+func TestAllocBudget(t *testing.T) {
+	switch {
+	case testing.Short():
+		t.Skip("skipping allocation budget in short mode")
+	case raceEnabled:
+		t.Skip("skipping allocation budget with race detector")
+	}
+
+	f := func(context.Context) error { return nil }
+
+	t.Run("go", func(t *testing.T) {
+		s := newScope(t)
+		defer closeScope(s)
+
+		// warm up, so the task slice is not grown during the measurement
+		for range 1000 {
+			s.Go(f)
+		}
+		if n := testing.AllocsPerRun(100, func() { s.Go(f) }); n > goAllocBudget {
+			t.Fatalf("Go exceeds its allocation budget: %v allocs/op > %d", n, goAllocBudget)
+		}
+	})
+
+	t.Run("defer", func(t *testing.T) {
+		s := newScope(t)
+		defer closeScope(s)
+
+		for range 1000 {
+			s.Defer(f)
+		}
+		if n := testing.AllocsPerRun(100, func() { s.Defer(f) }); n > deferAllocBudget {
+			t.Fatalf("Defer exceeds its allocation budget: %v allocs/op > %d", n, deferAllocBudget)
+		}
+	})
+}
+
+func BenchmarkScopeGo(b *testing.B) {
+	s := New()
+	f := func(context.Context) error { return nil }
+	b.ReportAllocs()
+	for b.Loop() {
+		s.Go(f)
+	}
+	b.StopTimer()
+	s.Close()
+}
+
+func BenchmarkScopeGoParallel(b *testing.B) {
+	s := New()
+	f := func(context.Context) error { return nil }
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.Go(f)
+		}
+	})
+	b.StopTimer()
+	s.Close()
+}
+
+func BenchmarkScopeStart(b *testing.B) {
+	s := New()
+	svc := Service{
+		Start: func(context.Context) error { return nil },
+		Stop:  func(context.Context) error { return nil },
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		s.Start(svc)
+	}
+	b.StopTimer()
+	s.Close()
+}
+
+func BenchmarkScopeDefer(b *testing.B) {
+	s := New()
+	f := func(context.Context) error { return nil }
+	b.ReportAllocs()
+	for b.Loop() {
+		s.Defer(f)
+	}
+	b.StopTimer()
+	s.Close()
+}
+
+func BenchmarkScopeDeferParallel(b *testing.B) {
+	s := New()
+	f := func(context.Context) error { return nil }
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.Defer(f)
+		}
+	})
+	b.StopTimer()
+	s.Close()
+}
+
+func BenchmarkScopeClose(b *testing.B) {
+	for _, n := range []int{10, 1000, 10000} {
+		b.Run(fmt.Sprintf("tasks=%d", n), func(b *testing.B) {
+			f := func(context.Context) error { return nil }
+			b.ReportAllocs()
+			for b.Loop() {
+				b.StopTimer()
+				s := New()
+				for range n {
+					s.Defer(f)
+				}
+				b.StartTimer()
+				s.Close()
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package scope
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BlockingStop describes a stop function, which was found blocked while
+// the scope was closing (see WithBlockingStopDetection).
+type BlockingStop struct {
+	Task     TaskInfo
+	Duration time.Duration // time the stop function was running when it was sampled
+	State    string        // state of the stop function's goroutine, e.g. "chan receive" or "IO wait"
+	Deadline bool          // reports whether the context of the stop function had a deadline
+	Stack    string        // stack of the stop function's goroutine
+}
+
+// watchStop samples the goroutine, which runs the task's stop function,
+// until the returned function is called. If the stop function runs
+// longer than the configured threshold and is blocked when sampled, it
+// is reported once (see WithBlockingStopDetection).
+func (s *Scope) watchStop(ctx context.Context, t *task) func() {
+	threshold := s.opts.blockingThreshold
+	if threshold <= 0 {
+		return func() {}
+	}
+
+	gid := goroutineID()
+	started := time.Now()
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(max(threshold/4, time.Millisecond))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			d := time.Since(started)
+			if d < threshold {
+				continue
+			}
+			state, stack, ok := goroutineState(gid)
+			if !ok || !isBlocked(state) {
+				continue
+			}
+
+			// The stop function may have returned in the meantime.
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			_, deadline := ctx.Deadline()
+			s.log(slog.LevelWarn, "stop function blocked", "task", t, "caller", (*lazyCaller)(t), "duration", d, "state", state, "deadline", deadline)
+			s.record("blocked", t, "duration", d, "state", state)
+			if s.opts.blockingReport != nil {
+				s.opts.blockingReport(BlockingStop{
+					Task:     t.info(),
+					Duration: d,
+					State:    state,
+					Deadline: deadline,
+					Stack:    stack,
+				})
+			}
+			return
+		}
+	}()
+	return func() { close(done) }
+}
+
+// goroutineID returns the ID of the calling goroutine.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// goroutineState returns the state and the stack of the goroutine with
+// the given ID. It reports false if the goroutine does not exist.
+func goroutineState(id uint64) (state, stack string, ok bool) {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	header := []byte("goroutine " + strconv.FormatUint(id, 10) + " [")
+	for block := range bytes.SplitSeq(buf, []byte("\n\n")) {
+		if !bytes.HasPrefix(block, header) {
+			continue
+		}
+		rest := block[len(header):]
+		end := bytes.IndexByte(rest, ']')
+		if end < 0 {
+			return "", "", false
+		}
+		// The state may be followed by the wait duration, e.g.
+		// "chan receive, 2 minutes".
+		state, _, _ = strings.Cut(string(rest[:end]), ",")
+		return state, string(bytes.TrimSpace(block)), true
+	}
+	return "", "", false
+}
+
+// isBlocked reports whether a goroutine with the given state waits for
+// something, e.g. a channel, a lock, or a system call.
+func isBlocked(state string) bool {
+	switch state {
+	case "running", "runnable", "preempted":
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,88 @@
+package scope
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithBlockingStopDetection(t *testing.T) {
+	t.Run("blocked", func(t *testing.T) {
+		reports := make(chan BlockingStop, 1)
+		s := New(WithBlockingStopDetection(10*time.Millisecond, func(b BlockingStop) { reports <- b }))
+
+		release := make(chan struct{})
+		s.Start(Service{
+			Name:  "svc",
+			Start: func(ctx context.Context) error { <-ctx.Done(); return nil },
+			Stop: func(context.Context) error {
+				<-release
+				return nil
+			},
+		})
+
+		closed := make(chan error, 1)
+		go func() { closed <- s.Close() }()
+
+		select {
+		case b := <-reports:
+			if b.Task.Name != "svc" || b.State != "chan receive" || b.Deadline {
+				t.Fatalf("unexpected report: %+v", b)
+			}
+			if b.Duration < 10*time.Millisecond {
+				t.Fatalf("unexpected duration: %v", b.Duration)
+			}
+			if !strings.Contains(b.Stack, "TestWithBlockingStopDetection") {
+				t.Fatalf("unexpected stack:\n%s", b.Stack)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected blocking stop function to be reported")
+		}
+
+		close(release)
+		if err := <-closed; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("fast", func(t *testing.T) {
+		s := New(WithBlockingStopDetection(10*time.Millisecond, func(b BlockingStop) {
+			t.Errorf("unexpected report: %+v", b)
+		}))
+		s.Defer(func(context.Context) error { return nil })
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestGoroutineState(t *testing.T) {
+	ch := make(chan struct{})
+	ids := make(chan uint64)
+	go func() {
+		ids <- goroutineID()
+		<-ch
+	}()
+	id := <-ids
+	defer close(ch)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		state, _, ok := goroutineState(id)
+		if !ok {
+			t.Fatalf("goroutine %d not found", id)
+		}
+		if state == "chan receive" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected state: %s", state)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, _, ok := goroutineState(0); ok {
+		t.Fatal("expected unknown goroutine not to be found")
+	}
+}
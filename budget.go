@@ -0,0 +1,65 @@
+package scope
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ShutdownBudget is a shutdown deadline shared by several scopes, e.g. the
+// scopes of a Manager or scopes created ad hoc (see WithShutdownBudget).
+// The budget starts when the first of the scopes is closed, and all
+// scopes, which are closed afterwards, have to finish within the same
+// deadline. This keeps the total shutdown within the grace period of an
+// orchestrator, regardless of how many scopes exist. The budget is safe
+// for concurrent use.
+type ShutdownBudget struct {
+	d time.Duration
+
+	mtx      sync.Mutex
+	deadline time.Time // zero until the budget started
+}
+
+// NewShutdownBudget creates a budget with the given duration.
+func NewShutdownBudget(d time.Duration) *ShutdownBudget {
+	if d <= 0 {
+		panic("scope: invalid shutdown budget")
+	}
+	return &ShutdownBudget{d: d}
+}
+
+// Start starts the budget, unless it already started, and returns its
+// deadline. It can be called when the shutdown of the process begins,
+// e.g. when a signal was received, to account for the time before the
+// first scope is closed.
+func (b *ShutdownBudget) Start() time.Time {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if b.deadline.IsZero() {
+		b.deadline = time.Now().Add(b.d)
+	}
+	return b.deadline
+}
+
+// Deadline returns the deadline of the budget. The boolean reports
+// whether the budget started.
+func (b *ShutdownBudget) Deadline() (time.Time, bool) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.deadline, !b.deadline.IsZero()
+}
+
+// Remaining returns the time left until the deadline, or the budget's
+// duration if it did not start yet.
+func (b *ShutdownBudget) Remaining() time.Duration {
+	if deadline, ok := b.Deadline(); ok {
+		return max(time.Until(deadline), 0)
+	}
+	return b.d
+}
+
+// context starts the budget and returns a context with its deadline.
+func (b *ShutdownBudget) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithDeadline(ctx, b.Start())
+}
@@ -0,0 +1,46 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithShutdownBudget(t *testing.T) {
+	const budget = 100 * time.Millisecond
+	b := NewShutdownBudget(budget)
+	if _, ok := b.Deadline(); ok {
+		t.Fatal("expected budget not to be started")
+	}
+	m := NewManager(WithShutdownBudget(b), WithErrorHandler(func(error) {}))
+
+	release := make(chan struct{})
+	defer close(release)
+	for _, name := range []string{"a", "b", "c"} {
+		s, err := m.Create(name)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		s.Defer(func(context.Context) error {
+			<-release
+			return nil
+		})
+	}
+
+	// The scopes are closed one after another, but share
+	// the budget.
+	started := time.Now()
+	for _, name := range []string{"a", "b", "c"} {
+		s, _ := m.Get(name)
+		if err := s.Close(); !errors.Is(err, ErrShutdownTimeout) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(started); elapsed > 2*budget {
+		t.Fatalf("shutdown exceeded the budget: %v", elapsed)
+	}
+	if b.Remaining() != 0 {
+		t.Fatalf("unexpected remaining budget: %v", b.Remaining())
+	}
+}
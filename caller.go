@@ -0,0 +1,86 @@
+package scope
+
+import (
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// pkgPrefix is the prefix of all functions of this package.
+var pkgPrefix = reflect.TypeFor[Scope]().PkgPath() + "."
+
+// callerFrames is the number of frames, which are recorded to find the
+// call site of a registration.
+const callerFrames = 16
+
+// callSite holds the program counters of the call site, which registered
+// a task. They are resolved lazily, since most call sites are never
+// reported.
+type callSite struct {
+	buf   [callerFrames]uintptr
+	pcs   []uintptr
+	depth int // number of frames of the stack (see WithCallerStacks)
+
+	resolved bool // guarded by the task's mutex
+	caller   string
+	stack    []string
+}
+
+// capture records the call site, which registers the given task.
+func (s *Scope) capture(t *task) {
+	cs := &t.site
+	cs.pcs = cs.buf[:]
+	if cs.depth = s.opts.callerDepth; cs.depth > 0 {
+		cs.pcs = make([]uintptr, callerFrames+cs.depth)
+	}
+	cs.pcs = cs.pcs[:runtime.Callers(3, cs.pcs)]
+}
+
+// callSite returns the call site, which registered the task, as
+// "file:line" and the stack of the call site up to the configured depth
+// (see WithCallerStacks). Frames of this package are skipped, so tasks
+// registered via helpers like Consume are attributed to their caller.
+func (t *task) callSite() (string, []string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	cs := &t.site
+	if cs.resolved {
+		return cs.caller, cs.stack
+	}
+	cs.resolved = true
+
+	frames := runtime.CallersFrames(cs.pcs)
+	for more := len(cs.pcs) > 0; more; {
+		var f runtime.Frame
+		f, more = frames.Next()
+		if cs.caller == "" && isInternalFrame(f) {
+			continue
+		}
+
+		loc := f.File + ":" + strconv.Itoa(f.Line)
+		if cs.caller == "" {
+			cs.caller = loc
+		}
+		if len(cs.stack) >= cs.depth {
+			break
+		}
+		cs.stack = append(cs.stack, f.Function+" "+loc)
+	}
+	return cs.caller, cs.stack
+}
+
+func isInternalFrame(f runtime.Frame) bool {
+	return strings.HasPrefix(f.Function, pkgPrefix) && !strings.HasSuffix(f.File, "_test.go")
+}
+
+// lazyCaller logs the call site of a task only if the log record is
+// actually written.
+type lazyCaller task
+
+func (c *lazyCaller) LogValue() slog.Value {
+	caller, _ := (*task)(c).callSite()
+	return slog.StringValue(caller)
+}
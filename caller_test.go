@@ -0,0 +1,39 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestTaskInfoCaller(t *testing.T) {
+	var lines []int
+	line := func() int {
+		_, _, l, _ := runtime.Caller(1)
+		return l
+	}
+
+	s := New(WithCallerStacks(2))
+	lines = append(lines, line()+1)
+	s.Go(func(context.Context) error { return nil })
+	lines = append(lines, line()+1)
+	s.Defer(func(context.Context) error { return nil })
+	lines = append(lines, line()+1)
+	Consume(s, make(chan int), func(context.Context, int) error { return nil })
+
+	for i, l := range lines {
+		info := s.tasks[i].info()
+		if expected := fmt.Sprintf("caller_test.go:%d", l); !strings.HasSuffix(info.Caller, expected) {
+			t.Fatalf("unexpected caller of task %d: %s", i, info.Caller)
+		}
+		if len(info.Stack) != 2 || !strings.Contains(info.Stack[0], "TestTaskInfoCaller") {
+			t.Fatalf("unexpected stack of task %d: %v", i, info.Stack)
+		}
+	}
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
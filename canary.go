@@ -0,0 +1,131 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ErrCanaryExited is the reason of a rollback when the canary's start
+// function returned during the evaluation (see Scope.StartCanary).
+var ErrCanaryExited = errors.New("scope: canary exited during evaluation")
+
+// Canary defines how a service, which is started in canary mode, is
+// evaluated (see Scope.StartCanary).
+type Canary struct {
+	// Window is the duration of the evaluation.
+	Window time.Duration
+	// Evaluate checks the health of the service. Its context is done
+	// when the window elapsed. It returns nil to promote the service,
+	// or an error to roll it back. If Evaluate is nil, the service is
+	// promoted if it is still running after the window.
+	Evaluate Func
+	// Promote is called when the service was promoted (optional).
+	Promote func()
+	// Rollback is called with the reason when the service was rolled
+	// back (optional).
+	Rollback func(error)
+}
+
+// StartCanary starts the given service like Start and evaluates it for
+// the canary's window. If the evaluation succeeds, the service is
+// promoted and keeps running under the scope's supervision. If the
+// evaluation fails, the service's start function returns during the
+// window, or the context is done, the service is rolled back: its stop
+// function is called, its context is cancelled with the cause
+// RolledBack, and StartCanary waits until its start function returned.
+// A rolled back service is not stopped again when the scope is closed.
+// The returned error contains the reason of the rollback.
+func (s *Scope) StartCanary(ctx context.Context, svc Service, c Canary, opts ...StartOption) error {
+	t := &task{name: svc.Name, svc: svc, stop: svc.Stop, isolated: true}
+	s.capture(t)
+
+	s.mtx.Lock()
+	switch {
+	case isClosed(s.closing):
+		s.mtx.Unlock()
+		return fmt.Errorf("scope: cannot start canary %q of a closing scope", t)
+	case s.frozen:
+		s.mtx.Unlock()
+		return fmt.Errorf("scope: cannot start canary %q of a frozen scope", t)
+	}
+	s.mtx.Unlock()
+
+	if !s.start(t, opts) {
+		if t.err == nil {
+			return fmt.Errorf("scope: cannot start canary %q of a frozen scope", t)
+		}
+		return fmt.Errorf("scope: canary %q not started: %w", t, t.err)
+	}
+
+	s.mtx.Lock()
+	done := t.done
+	s.mtx.Unlock()
+
+	s.log(slog.LevelInfo, "evaluating canary", "task", t, "window", c.Window)
+	err := s.evaluate(ctx, c, done)
+	if err == nil {
+		s.log(slog.LevelInfo, "canary promoted", "task", t)
+		if c.Promote != nil {
+			c.Promote()
+		}
+		return nil
+	}
+
+	s.log(slog.LevelWarn, "canary rolled back", "task", t, "error", err)
+	errs := Errors{fmt.Errorf("scope: canary %q rolled back: %w", t, err)}
+	errs.append(s.rollback(context.WithoutCancel(ctx), t, done, err))
+	if c.Rollback != nil {
+		c.Rollback(err)
+	}
+	return errs.err()
+}
+
+// evaluate runs the canary's evaluation and returns the reason of a
+// rollback, or nil if the canary can be promoted.
+func (s *Scope) evaluate(ctx context.Context, c Canary, done <-chan struct{}) error {
+	evalCtx, cancel := context.WithTimeout(ctx, c.Window)
+	defer cancel()
+
+	evaluate := c.Evaluate
+	if evaluate == nil {
+		evaluate = func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}
+	}
+
+	res := make(chan error, 1)
+	s.spawn(func() { res <- protect(evalCtx, evaluate) })
+
+	select {
+	case err := <-res:
+		if err == nil {
+			err = ctx.Err()
+		}
+		return err
+	case <-done:
+		return ErrCanaryExited
+	}
+}
+
+// rollback stops the canary and waits until its start function returned.
+func (s *Scope) rollback(ctx context.Context, t *task, done <-chan struct{}, reason error) error {
+	t.retired.Store(true)
+
+	var err error
+	if t.stop != nil && t.state.is(running) {
+		if serr := s.stopTask(ctx, t); serr != nil {
+			err = &TaskError{Task: t.info(), Err: serr}
+		}
+	}
+
+	s.mtx.Lock()
+	cancel := t.cancel
+	s.mtx.Unlock()
+	cancel(RolledBack{Err: reason})
+	<-done
+	return err
+}
@@ -0,0 +1,120 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScopeStartCanary(t *testing.T) {
+	run := func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}
+
+	t.Run("promote", func(t *testing.T) {
+		s := newScope(t)
+		stop := newCall(nil)
+
+		var promoted bool
+		err := s.StartCanary(context.Background(), Service{Name: "canary", Start: run, Stop: stop.f}, Canary{
+			Window:   time.Millisecond,
+			Promote:  func() { promoted = true },
+			Rollback: func(err error) { t.Fatalf("unexpected rollback: %v", err) },
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !promoted {
+			t.Fatal("expected canary to be promoted")
+		}
+		if stop.called() {
+			t.Fatal("expected stop function not to be called before close")
+		}
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !stop.called() {
+			t.Fatal("expected stop function to be called")
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		s := newScope(t)
+
+		var stops atomic.Int32
+		cause := make(chan CloseCause, 1)
+		svc := Service{
+			Name: "canary",
+			Start: func(ctx context.Context) error {
+				<-ctx.Done()
+				cause <- Cause(ctx)
+				return ctx.Err()
+			},
+			Stop: func(context.Context) error {
+				stops.Add(1)
+				return nil
+			},
+		}
+
+		errUnhealthy := errors.New("unhealthy")
+		var rolledBack error
+		err := s.StartCanary(context.Background(), svc, Canary{
+			Window:   time.Second,
+			Evaluate: func(context.Context) error { return errUnhealthy },
+			Promote:  func() { t.Fatal("unexpected promotion") },
+			Rollback: func(err error) { rolledBack = err },
+		})
+		if !errors.Is(err, errUnhealthy) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rolledBack != errUnhealthy {
+			t.Fatalf("unexpected rollback reason: %v", rolledBack)
+		}
+		if c := <-cause; c != (RolledBack{Err: errUnhealthy}) {
+			t.Fatalf("unexpected cause: %v", c)
+		}
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := stops.Load(); n != 1 {
+			t.Fatalf("unexpected number of stops: %d", n)
+		}
+	})
+
+	t.Run("exited", func(t *testing.T) {
+		var errs []error
+		s := New(WithErrorHandler(CollectErrors(&errs)))
+
+		errStart := errors.New("start error")
+		err := s.StartCanary(context.Background(), Service{
+			Start: func(context.Context) error { return errStart },
+		}, Canary{Window: time.Second})
+		if !errors.Is(err, ErrCanaryExited) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(errs) != 1 || errs[0] != errStart {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+	})
+
+	t.Run("closing", func(t *testing.T) {
+		s := newScope(t)
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		err := s.StartCanary(context.Background(), Service{Start: run}, Canary{})
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
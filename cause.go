@@ -0,0 +1,224 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// CloseCause describes why the context of a scope ended. It is one of
+// SignalReceived, FatalError, ParentCanceled, MaxLifetime, Triggered,
+// HandedOver, or Manual.
+// The context of a single service may also end with Restarted,
+// RolledBack, Stopped, or Paused.
+type CloseCause interface {
+	error
+	closeCause()
+}
+
+// Cause returns the reason why the given context, which is either a
+// scope's context or derived from it, ended. If the context is not
+// done yet, Cause returns nil.
+func Cause(ctx context.Context) CloseCause {
+	if ctx.Err() == nil {
+		return nil
+	}
+
+	err := context.Cause(ctx)
+	if c, ok := err.(CloseCause); ok {
+		return c
+	}
+	return ParentCanceled{Err: err}
+}
+
+// CauseLabel returns a short, constant label for the given cause, which
+// can be used e.g. as a metrics label: "signal", "fatal_error",
+// "parent_canceled", "max_lifetime", "triggered", "handed_over", "manual",
+// "restarted", "rolled_back", "stopped", or "paused". The label of a nil
+// cause is empty.
+func CauseLabel(c CloseCause) string {
+	switch c.(type) {
+	case SignalReceived:
+		return "signal"
+	case FatalError:
+		return "fatal_error"
+	case ParentCanceled:
+		return "parent_canceled"
+	case MaxLifetime:
+		return "max_lifetime"
+	case Triggered:
+		return "triggered"
+	case HandedOver:
+		return "handed_over"
+	case Manual:
+		return "manual"
+	case Restarted:
+		return "restarted"
+	case RolledBack:
+		return "rolled_back"
+	case Stopped:
+		return "stopped"
+	case Paused:
+		return "paused"
+	default:
+		return ""
+	}
+}
+
+// setCloseCause defines the cause the scope's context is cancelled with
+// when the scope is closed, unless the context is already done. Other
+// than cancelling the context right away, this keeps the context valid
+// for the stop functions.
+func (s *Scope) setCloseCause(c CloseCause) {
+	s.mtx.Lock()
+	s.cause = c
+	s.mtx.Unlock()
+}
+
+// SignalReceived reports that the scope ended because the process
+// received an operating system signal.
+type SignalReceived struct {
+	Signal os.Signal
+}
+
+func (c SignalReceived) Error() string {
+	return "scope: received signal " + c.Signal.String()
+}
+
+// SignalError is the error of a shutdown, which was caused or forced by
+// a signal (see RunUntilSignal). It can be matched with errors.As. It is
+// an alias of the cause SignalReceived, so it carries the signal in the
+// field Signal rather than in a separate Sig field, and the causes and
+// errors of signals match the same type.
+type SignalError = SignalReceived
+
+// FatalError reports that the scope ended because of a task error.
+type FatalError struct {
+	Err error
+}
+
+func (c FatalError) Error() string {
+	return "scope: fatal error: " + c.Err.Error()
+}
+
+// Unwrap returns the task error.
+func (c FatalError) Unwrap() error {
+	return c.Err
+}
+
+// ParentCanceled reports that the scope ended because its base context
+// (see WithContext) is done.
+type ParentCanceled struct {
+	Err error // the cause of the base context
+}
+
+func (c ParentCanceled) Error() string {
+	return "scope: parent context canceled: " + c.Err.Error()
+}
+
+// Unwrap returns the cause of the base context.
+func (c ParentCanceled) Unwrap() error {
+	return c.Err
+}
+
+// MaxLifetime reports that the scope ended because it reached its
+// maximum lifetime (see WithMaxLifetime).
+type MaxLifetime struct {
+	Lifetime time.Duration
+}
+
+func (c MaxLifetime) Error() string {
+	return fmt.Sprintf("scope: max lifetime of %v reached", c.Lifetime)
+}
+
+// Triggered reports that the scope ended because of a close trigger (see
+// Scope.CloseOn and Scope.CloseOnFunc).
+type Triggered struct {
+	Err error // error of the trigger function, if any
+}
+
+func (c Triggered) Error() string {
+	if c.Err != nil {
+		return "scope: close triggered: " + c.Err.Error()
+	}
+	return "scope: close triggered"
+}
+
+// Unwrap returns the error of the trigger function.
+func (c Triggered) Unwrap() error {
+	return c.Err
+}
+
+// HandedOver reports that the scope ended because its work was handed
+// over to another scope (see Handover).
+type HandedOver struct{}
+
+func (HandedOver) Error() string {
+	return "scope: handed over"
+}
+
+// Manual reports that the scope ended because it was closed.
+type Manual struct{}
+
+func (Manual) Error() string {
+	return "scope: closed"
+}
+
+// Restarted reports that the context of a service ended because the
+// service is restarted (see Scope.RollingRestart).
+type Restarted struct{}
+
+func (Restarted) Error() string {
+	return "scope: service restarted"
+}
+
+// RolledBack reports that the context of a service ended because the
+// service was rolled back after a failed canary evaluation (see
+// Scope.StartCanary).
+type RolledBack struct {
+	Err error // reason of the rollback
+}
+
+func (c RolledBack) Error() string {
+	return "scope: service rolled back: " + c.Err.Error()
+}
+
+// Unwrap returns the reason of the rollback.
+func (c RolledBack) Unwrap() error {
+	return c.Err
+}
+
+// Stopped reports that the context of a service ended because the
+// service was stopped individually (see Admin.Stop).
+type Stopped struct{}
+
+func (Stopped) Error() string {
+	return "scope: service stopped"
+}
+
+// Paused reports that the context of a service ended because the
+// service was paused or quarantined with the given reason (see
+// Admin.Pause and Admin.Quarantine).
+type Paused struct {
+	Reason string // reason of the quarantine, empty if paused
+}
+
+func (c Paused) Error() string {
+	if c.Reason != "" {
+		return "scope: service quarantined: " + c.Reason
+	}
+	return "scope: service paused"
+}
+
+func (SignalReceived) closeCause() {}
+func (FatalError) closeCause()     {}
+func (ParentCanceled) closeCause() {}
+func (MaxLifetime) closeCause()    {}
+func (Triggered) closeCause()      {}
+func (HandedOver) closeCause()     {}
+func (Manual) closeCause()         {}
+func (Restarted) closeCause()      {}
+func (RolledBack) closeCause()     {}
+func (Stopped) closeCause()        {}
+func (Paused) closeCause()         {}
@@ -0,0 +1,208 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCause(t *testing.T) {
+	t.Run("manual", func(t *testing.T) {
+		s := newScope(t)
+		if c := Cause(s.Ctx()); c != nil {
+			t.Fatalf("unexpected cause: %v", c)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c := Cause(s.Ctx()); c != (Manual{}) {
+			t.Fatalf("unexpected cause: %v", c)
+		}
+	})
+
+	t.Run("parent-canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		s := New(WithContext(ctx))
+		cancel()
+
+		c, ok := Cause(s.Ctx()).(ParentCanceled)
+		if !ok {
+			t.Fatalf("unexpected cause: %v", Cause(s.Ctx()))
+		}
+		if c.Err != context.Canceled {
+			t.Fatalf("unexpected parent cause: %v", c.Err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("max-lifetime", func(t *testing.T) {
+		s := New(WithMaxLifetime(time.Millisecond))
+		<-s.Ctx().Done()
+
+		if c := Cause(s.Ctx()); c != (MaxLifetime{Lifetime: time.Millisecond}) {
+			t.Fatalf("unexpected cause: %v", c)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("derived", func(t *testing.T) {
+		s := newScope(t)
+		ctx, cancel := context.WithTimeout(s.Ctx(), time.Hour)
+		defer cancel()
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c := Cause(ctx); c != (Manual{}) {
+			t.Fatalf("unexpected cause: %v", c)
+		}
+	})
+}
+
+func TestWithFailFast(t *testing.T) {
+	var errs []error
+	s := New(WithFailFast(), WithErrorHandler(CollectErrors(&errs)))
+
+	stopped := make(chan struct{})
+	s.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		close(stopped)
+		return nil
+	})
+
+	errTask := errors.New("task error")
+	s.Go(func(context.Context) error { return errTask })
+	<-stopped
+
+	if c, ok := Cause(s.Ctx()).(FatalError); !ok || c.Err != errTask {
+		t.Fatalf("unexpected cause: %v", Cause(s.Ctx()))
+	}
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(errs) != 1 || errs[0] != errTask {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+}
+
+func TestCloseCauseReport(t *testing.T) {
+	t.Run("manual", func(t *testing.T) {
+		s := newScope(t)
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		report, _ := s.ShutdownReport()
+		if report.Cause != (Manual{}) {
+			t.Fatalf("unexpected cause: %v", report.Cause)
+		}
+		if label := s.Metrics().CloseCause; label != "manual" {
+			t.Fatalf("unexpected cause label: %s", label)
+		}
+	})
+
+	t.Run("max-lifetime", func(t *testing.T) {
+		s := New(WithMaxLifetime(time.Millisecond))
+		<-s.Ctx().Done()
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		report, _ := s.ShutdownReport()
+		if _, ok := report.Cause.(MaxLifetime); !ok {
+			t.Fatalf("unexpected cause: %v", report.Cause)
+		}
+		if label := s.Metrics().CloseCause; label != "max_lifetime" {
+			t.Fatalf("unexpected cause label: %s", label)
+		}
+	})
+
+	t.Run("requested", func(t *testing.T) {
+		s := newScope(t)
+		s.setCloseCause(SignalReceived{Signal: os.Interrupt})
+		s.Defer(func(ctx context.Context) error {
+			return ctx.Err()
+		})
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c := Cause(s.Ctx()); c != (SignalReceived{Signal: os.Interrupt}) {
+			t.Fatalf("unexpected cause: %v", c)
+		}
+		if label := s.Metrics().CloseCause; label != "signal" {
+			t.Fatalf("unexpected cause label: %s", label)
+		}
+	})
+}
+
+func TestWithDoneContextPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	errShutdown := errors.New("shutdown")
+	cancel(errShutdown)
+
+	t.Run("run", func(t *testing.T) {
+		s := New(WithContext(ctx))
+		called := newCall(nil)
+		s.Go(called.f)
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !called.called() {
+			t.Fatal("expected function to be called")
+		}
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		s, err := NewE(WithContext(ctx), WithDoneContextPolicy(RejectDoneContext))
+		if s != nil || !errors.Is(err, ErrContextDone) || !errors.Is(err, errShutdown) {
+			t.Fatalf("unexpected result: %v, %v", s, err)
+		}
+
+		defer func() {
+			if v := recover(); v == nil {
+				t.Fatal("expected panic")
+			}
+		}()
+		New(WithContext(ctx), WithDoneContextPolicy(RejectDoneContext))
+	})
+
+	t.Run("close", func(t *testing.T) {
+		var errs []error
+		s := New(
+			WithContext(ctx),
+			WithDoneContextPolicy(CloseOnDoneContext),
+			WithErrorHandler(CollectErrors(&errs)),
+		)
+		if !s.IsClosed() {
+			t.Fatal("expected scope to be closed")
+		}
+
+		called := newCall(nil)
+		s.Go(called.f)
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if called.called() {
+			t.Fatal("expected function not to be called")
+		}
+		if len(errs) != 1 || !errors.Is(errs[0], ErrClosed) {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+	})
+
+	t.Run("not-done", func(t *testing.T) {
+		s, err := NewE(WithDoneContextPolicy(RejectDoneContext))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package scope
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// checkedContext records whether a start function observed its context
+// (see WithContextCheck).
+type checkedContext struct {
+	context.Context
+	observed atomic.Bool
+}
+
+func (c *checkedContext) Done() <-chan struct{} {
+	c.observed.Store(true)
+	return c.Context.Done()
+}
+
+func (c *checkedContext) Err() error {
+	c.observed.Store(true)
+	return c.Context.Err()
+}
+
+// checkContext reports the task if its start function returned without
+// observing its context, although the context was cancelled.
+func (s *Scope) checkContext(t *task, ctx *checkedContext) {
+	if ctx.observed.Load() || ctx.Context.Err() == nil {
+		return
+	}
+
+	info := t.info()
+	s.log(slog.LevelWarn, "task ignored its context", "task", t, "caller", info.Caller)
+	if s.opts.contextReport != nil {
+		s.opts.contextReport(info)
+	}
+	s.warn(Warning{Kind: WarnIgnoredContext, Task: info})
+}
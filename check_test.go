@@ -0,0 +1,73 @@
+package scope
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithContextCheck(t *testing.T) {
+	var (
+		mtx      sync.Mutex
+		reported []string
+	)
+	s := New(WithContextCheck(func(info TaskInfo) {
+		mtx.Lock()
+		reported = append(reported, info.Name)
+		mtx.Unlock()
+	}))
+
+	release := make(chan struct{})
+	s.Start(Service{
+		Name: "ignoring",
+		Start: func(context.Context) error {
+			<-release
+			return nil
+		},
+		Stop: func(context.Context) error {
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				close(release)
+			}()
+			return nil
+		},
+	})
+	s.Start(Service{
+		Name: "observing",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+	})
+	s.Start(Service{
+		Name: "derived",
+		Start: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, time.Hour)
+			defer cancel()
+			<-ctx.Done()
+			return nil
+		},
+	})
+	short := s.Start(Service{
+		Name:  "short",
+		Start: func(context.Context) error { return nil },
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.WaitFor(ctx, "ignoring", "observing", "derived"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The short task must return before the scope is closed, since
+	// it would not observe the cancelled context otherwise.
+	if err := short.Wait(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reported) != 1 || reported[0] != "ignoring" {
+		t.Fatalf("unexpected reports: %v", reported)
+	}
+}
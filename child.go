@@ -0,0 +1,84 @@
+package scope
+
+import (
+	"context"
+	"slices"
+)
+
+// Child creates a sub-scope, whose context is derived from the scope's
+// context. The child inherits the options of the scope, which can be
+// overridden by the given options. When the scope is closed, its open
+// children are closed in reverse order of creation before any of the
+// scope's stop functions is called, and the errors of the children are
+// part of the scope's errors. A child, which is created while the scope
+// is closing, is not closed automatically.
+//
+// The name, the readiness hooks, and the startup summary of the scope are
+// not inherited, since the readiness of the children is aggregated into
+// the readiness of the scope (see Scope.Ready). Neither is CloseOnExit,
+// since the children are closed with the scope, nor the signal policy,
+// since the signals are handled by the scope for its children.
+func (s *Scope) Child(o ...Option) *Scope {
+	opts := s.opts
+	opts.instruments = slices.Clip(opts.instruments)
+	opts.defaults = slices.Clip(opts.defaults)
+	opts.middleware = slices.Clip(opts.middleware)
+	opts.expected = slices.Clip(opts.expected)
+	opts.name = ""
+	opts.onReady, opts.onUnready = nil, nil
+	opts.summary, opts.onSummary = false, nil
+	opts.closeOnExit = false
+	opts.signals = nil
+	for _, apply := range o {
+		apply(&opts)
+	}
+	opts.ctx = s.ctx
+
+	child := build(opts)
+	child.parent = s
+	if opts.recorder == s.opts.recorder {
+		// The records of the child are part of the
+		// scope's timeline.
+		child.recorder = s.recorder
+	}
+
+	s.addChild(child)
+	return child
+}
+
+// addChild attaches a child, which is closed with the scope, unless the
+// scope is closing already.
+func (s *Scope) addChild(child *Scope) {
+	s.mtx.Lock()
+	if !isClosed(s.closing) {
+		s.children = append(s.children, child)
+	}
+	s.mtx.Unlock()
+}
+
+// removeChild detaches a child, which is closed on its own.
+func (s *Scope) removeChild(child *Scope) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if i := slices.Index(s.children, child); i >= 0 {
+		s.children = slices.Delete(s.children, i, i+1)
+	}
+}
+
+// closeChildren closes the given children in reverse order. They are
+// closed for the same cause as their parent.
+func (s *Scope) closeChildren(ctx context.Context, children []*Scope, cause CloseCause) error {
+	var errs Errors
+	for i := len(children) - 1; i >= 0; i-- {
+		child := children[i]
+		child.setCloseCause(cause)
+		if err := child.CloseContext(ctx); err != nil {
+			if child.module != "" {
+				err = &ModuleError{Module: child.module, Err: err}
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errs.err()
+}
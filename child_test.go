@@ -0,0 +1,98 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestScopeChild(t *testing.T) {
+	t.Run("close-order", func(t *testing.T) {
+		var (
+			mtx    sync.Mutex
+			events []string
+		)
+		record := func(ev string) Func {
+			return func(context.Context) error {
+				mtx.Lock()
+				events = append(events, ev)
+				mtx.Unlock()
+				return nil
+			}
+		}
+
+		s := newScope(t)
+		s.Defer(record("parent"))
+		first := s.Child()
+		first.Defer(record("first"))
+		second := s.Child()
+		second.Defer(record("second"))
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := fmt.Sprint(events); got != "[second first parent]" {
+			t.Fatalf("unexpected events: %s", got)
+		}
+		if c := Cause(first.Ctx()); c != (Manual{}) {
+			t.Fatalf("unexpected cause: %v", c)
+		}
+	})
+
+	t.Run("closed-separately", func(t *testing.T) {
+		closed := 0
+		s := newScope(t)
+		child := s.Child()
+		child.Defer(func(context.Context) error {
+			closed++
+			return nil
+		})
+
+		if err := closeScope(child); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if closed != 1 {
+			t.Fatalf("unexpected number of closes: %d", closed)
+		}
+	})
+
+	t.Run("closed-twice", func(t *testing.T) {
+		closed := 0
+		s := newScope(t)
+		child := s.Child()
+		child.Defer(func(context.Context) error {
+			closed++
+			return errors.New("defer error")
+		})
+
+		first := child.Close()
+		if first == nil {
+			t.Fatal("expected error")
+		}
+		if err := child.Close(); err == nil || err.Error() != first.Error() {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if closed != 1 {
+			t.Fatalf("unexpected number of closes: %d", closed)
+		}
+	})
+
+	t.Run("parent-context", func(t *testing.T) {
+		s := newScope(t)
+		child := s.Child()
+		s.cancel(Manual{})
+
+		<-child.Ctx().Done()
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,203 @@
+package scope
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+type commandOptions struct {
+	dir       string
+	env       []string
+	logger    *slog.Logger
+	killDelay time.Duration
+}
+
+// CommandOption represents an option which can be used to configure a
+// subprocess service (see Command).
+type CommandOption func(*commandOptions)
+
+// Dir defines the working directory of the subprocess. By default, the
+// subprocess runs in the working directory of the calling process.
+func Dir(dir string) CommandOption {
+	return func(o *commandOptions) {
+		o.dir = dir
+	}
+}
+
+// Env adds variables of the form "key=value" to the environment of the
+// subprocess, which inherits the environment of the calling process
+// otherwise. The values are expanded for each run of the subprocess
+// (see os.Expand): ${SCOPE_SERVICE} is replaced by the name of the
+// service, ${SCOPE_RUN} by the number of the run starting at 1, and
+// other variables by the ones of the calling process. The option can be
+// used multiple times.
+func Env(env ...string) CommandOption {
+	return func(o *commandOptions) {
+		o.env = append(o.env[:len(o.env):len(o.env)], env...)
+	}
+}
+
+// OutputLogger writes each line of the subprocess' stdout as info and
+// each line of its stderr as warning to the given logger, annotated
+// with the name of the service. By default, the output is written to
+// the scope's logger (see WithLogger), or to the stdout and stderr of the
+// calling process if the scope has no logger.
+func OutputLogger(l *slog.Logger) CommandOption {
+	return func(o *commandOptions) {
+		if l == nil {
+			panic("scope options: no output logger specified")
+		}
+		o.logger = l
+	}
+}
+
+// KillDelay defines the time the subprocess has to exit after it was
+// asked to stop, before it is killed. The default delay is 10 seconds.
+func KillDelay(d time.Duration) CommandOption {
+	return func(o *commandOptions) {
+		if d <= 0 {
+			panic("scope options: invalid kill delay")
+		}
+		o.killDelay = d
+	}
+}
+
+// Command returns a service, which runs the program at the given path
+// with the given arguments as a subprocess. The start function starts a
+// new subprocess and waits until it exits. An exit status other than
+// zero is returned as *exec.ExitError. When the start function's context
+// is done, the subprocess is asked to stop (SIGTERM, or killed on
+// Windows) and killed if it does not exit within the kill delay (see
+// KillDelay); this is not reported as an error.
+//
+// The returned service has no name and no restart policy, which can be
+// set before the service is started. This makes the scope a minimal
+// supervisor for sidecar processes:
+//
+//	svc := scope.Command("/usr/bin/envoy", []string{"-c", "envoy.yaml"},
+//		scope.Env("SERVICE_NODE=${SCOPE_SERVICE}-${SCOPE_RUN}"),
+//		scope.OutputLogger(logger),
+//	)
+//	svc.Name = "envoy"
+//	svc.Restart = scope.RestartOnFailure
+//	s.Start(svc)
+func Command(path string, args []string, opts ...CommandOption) Service {
+	o := commandOptions{killDelay: 10 * time.Second}
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	var runs atomic.Int64
+	return Service{
+		Start: func(ctx context.Context) error {
+			run := runs.Add(1)
+			var name string
+			if t := taskFromContext(ctx); t != nil {
+				name = t.name
+			}
+
+			cmd := exec.CommandContext(ctx, path, args...)
+			cmd.Dir = o.dir
+			cmd.Env = o.environ(name, run)
+			cmd.Cancel = func() error {
+				if err := cmd.Process.Signal(stopSignal); err != nil {
+					return cmd.Process.Kill()
+				}
+				return nil
+			}
+			cmd.WaitDelay = o.killDelay
+
+			logger := o.logger
+			if s := scopeFromContext(ctx); logger == nil && s != nil {
+				logger = s.logger
+			}
+			var stdout, stderr *logWriter
+			if logger == nil {
+				cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+			} else {
+				stdout = output(ctx, logger, slog.LevelInfo, name, "stdout")
+				stderr = output(ctx, logger, slog.LevelWarn, name, "stderr")
+				cmd.Stdout, cmd.Stderr = stdout, stderr
+			}
+
+			err := cmd.Run()
+			if stdout != nil {
+				stdout.flush()
+				stderr.flush()
+			}
+			if ctx.Err() != nil {
+				// The subprocess was stopped by the scope.
+				return nil
+			}
+			return err
+		},
+	}
+}
+
+// environ returns the environment of the given run of the subprocess.
+func (o *commandOptions) environ(name string, run int64) []string {
+	if len(o.env) == 0 {
+		return nil // inherit the environment
+	}
+	env := os.Environ()
+	for _, kv := range o.env {
+		env = append(env, os.Expand(kv, func(key string) string {
+			switch key {
+			case "SCOPE_SERVICE":
+				return name
+			case "SCOPE_RUN":
+				return strconv.FormatInt(run, 10)
+			default:
+				return os.Getenv(key)
+			}
+		}))
+	}
+	return env
+}
+
+func output(ctx context.Context, logger *slog.Logger, level slog.Level, name, stream string) *logWriter {
+	return &logWriter{log: func(line string) {
+		logger.Log(ctx, level, line, "service", name, "stream", stream)
+	}}
+}
+
+// maxLogLine is the maximum length of a logged line of a subprocess's
+// output. Longer lines are split.
+const maxLogLine = 64 << 10
+
+// logWriter logs the lines written to it. Incomplete lines are buffered
+// until they are completed or flushed, or until they exceed maxLogLine.
+type logWriter struct {
+	log func(line string)
+	buf []byte
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		switch {
+		case i >= 0 && i <= maxLogLine:
+			w.log(string(bytes.TrimSuffix(w.buf[:i], []byte{'\r'})))
+			w.buf = w.buf[i+1:]
+		case len(w.buf) > maxLogLine:
+			w.log(string(w.buf[:maxLogLine]))
+			w.buf = w.buf[maxLogLine:]
+		default:
+			return len(p), nil
+		}
+	}
+}
+
+func (w *logWriter) flush() {
+	if len(w.buf) > 0 {
+		w.log(string(w.buf))
+		w.buf = nil
+	}
+}
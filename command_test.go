@@ -0,0 +1,181 @@
+//go:build unix
+
+package scope
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const commandHelperEnv = "SCOPE_TEST_COMMAND"
+
+func TestCommand(t *testing.T) {
+	t.Run("output", func(t *testing.T) {
+		var (
+			mtx sync.Mutex
+			buf bytes.Buffer
+		)
+		logger := slog.New(slog.NewTextHandler(lockedWriter{&mtx, &buf}, nil))
+		dir := t.TempDir()
+
+		var errs []error
+		s := New(WithErrorHandler(CollectErrors(&errs)))
+		svc := commandHelper("exit",
+			Dir(dir),
+			Env("HELPER_NAME=${SCOPE_SERVICE}-${SCOPE_RUN}"),
+			OutputLogger(logger),
+		)
+		svc.Name = "helper"
+		svc.Restart = RestartOnFailure
+		svc.Backoff = Backoff{Initial: time.Millisecond, MaxAttempts: 1}
+		if err := s.Start(svc).Wait(context.Background()); err == nil {
+			t.Fatal("error expected")
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var exitErr *exec.ExitError
+		if len(errs) != 1 || !errors.As(errs[0], &exitErr) || exitErr.ExitCode() != 3 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+		mtx.Lock()
+		defer mtx.Unlock()
+		for _, msg := range []string{
+			fmt.Sprintf(`level=INFO msg="name=helper-1 dir=%s" service=helper stream=stdout`, dir),
+			fmt.Sprintf(`level=INFO msg="name=helper-2 dir=%s" service=helper stream=stdout`, dir),
+			`level=WARN msg=failing service=helper stream=stderr`,
+		} {
+			if !strings.Contains(buf.String(), msg) {
+				t.Fatalf("expected log message %q, got:\n%s", msg, buf.String())
+			}
+		}
+	})
+
+	t.Run("scope-logger", func(t *testing.T) {
+		var (
+			mtx sync.Mutex
+			buf bytes.Buffer
+		)
+		logger := slog.New(slog.NewTextHandler(lockedWriter{&mtx, &buf}, nil))
+
+		var errs []error
+		s := New(WithErrorHandler(CollectErrors(&errs)), WithLogger(logger))
+		svc := commandHelper("exit", Dir(t.TempDir()))
+		svc.Name = "helper"
+		if err := s.Start(svc).Wait(context.Background()); err == nil {
+			t.Fatal("error expected")
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		mtx.Lock()
+		defer mtx.Unlock()
+		if msg := `level=WARN msg=failing service=helper stream=stderr`; !strings.Contains(buf.String(), msg) {
+			t.Fatalf("expected log message %q, got:\n%s", msg, buf.String())
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		dir := t.TempDir()
+		s := newScope(t)
+		svc := commandHelper("wait", Dir(dir), KillDelay(5*time.Second))
+		svc.Ready = func(ctx context.Context) error {
+			for {
+				if _, err := os.Stat(filepath.Join(dir, "ready")); err == nil {
+					return nil
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(time.Millisecond):
+				}
+			}
+		}
+		s.Start(svc)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.WaitReady(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		start := time.Now()
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if d := time.Since(start); d >= 5*time.Second {
+			t.Fatalf("subprocess was killed after %v", d)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "stopped")); err != nil {
+			t.Fatalf("subprocess was not stopped gracefully: %v", err)
+		}
+	})
+}
+
+func TestLogWriter(t *testing.T) {
+	var lines []string
+	w := &logWriter{log: func(line string) { lines = append(lines, line) }}
+
+	long := strings.Repeat("x", maxLogLine+10)
+	w.Write([]byte("first\r\nsec"))
+	w.Write([]byte("ond\n" + long))
+	w.Write([]byte("\nlast"))
+	w.flush()
+
+	want := []string{"first", "second", long[:maxLogLine], long[maxLogLine:], "last"}
+	if len(lines) != len(want) {
+		t.Fatalf("unexpected number of lines: %d", len(lines))
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("unexpected line %d: %.20q", i, lines[i])
+		}
+	}
+}
+
+func TestCommandHelper(t *testing.T) {
+	switch os.Getenv(commandHelperEnv) {
+	case "exit":
+		dir, _ := os.Getwd()
+		fmt.Printf("name=%s dir=%s\n", os.Getenv("HELPER_NAME"), dir)
+		fmt.Fprint(os.Stderr, "failing")
+		os.Exit(3)
+	case "wait":
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGTERM)
+		os.WriteFile("ready", nil, 0o600)
+		<-sigs
+		os.WriteFile("stopped", nil, 0o600)
+		os.Exit(0)
+	}
+}
+
+func commandHelper(mode string, opts ...CommandOption) Service {
+	return Command(os.Args[0], []string{"-test.run=^TestCommandHelper$"},
+		append([]CommandOption{Env(commandHelperEnv + "=" + mode)}, opts...)...,
+	)
+}
+
+type lockedWriter struct {
+	mtx *sync.Mutex
+	buf *bytes.Buffer
+}
+
+func (w lockedWriter) Write(p []byte) (int, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.buf.Write(p)
+}
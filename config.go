@@ -0,0 +1,71 @@
+package scope
+
+import (
+	"cmp"
+	"maps"
+	"os"
+	"slices"
+	"time"
+)
+
+// OptionsView is a read-only view of the effective configuration of a
+// scope (see Scope.Options). It allows frameworks, which embed a scope
+// provided by the user, to validate or log the scope's lifecycle policy.
+// Durations are zero and counts are zero if the respective option is not
+// configured.
+type OptionsView struct {
+	Name            string        // name of the scope (see WithName)
+	ShutdownTimeout time.Duration // maximum duration of Close (see WithShutdownTimeout)
+	SoftCancel      time.Duration // soft cancellation period (see WithSoftCancel)
+	GracePeriod     time.Duration // grace period of the process (see WithGracePeriod)
+	MaxLifetime     time.Duration // maximum lifetime of the scope (see WithMaxLifetime)
+	Limit           int           // maximum number of concurrently running start functions (see WithLimit)
+	AdaptiveLimit   bool          // whether the limit adapts to the load (see WithAdaptiveLimit)
+	ParallelStops   int           // number of concurrent stop functions (see WithParallelShutdown)
+	FailFast        bool          // whether the first error closes the scope (see WithFailFast)
+	Retain          int           // number of retained finished tasks (see WithBoundedMemory)
+	ProbeTimeout    time.Duration // maximum duration of a health check (see WithProbeTimeout)
+	FinalTimeout    time.Duration // maximum duration of a final function (see WithFinalTimeout)
+
+	// Phases holds the phases of Close in order of execution (see
+	// PhaseReport). Optional phases are only included if configured.
+	Phases []string
+
+	// Signals holds the signals, which the scope reacts to, sorted by
+	// name. These are the signals of the scope's signal policy (see
+	// WithSignalPolicy and WithCancelOnSignal), or the default termination
+	// signals, which make RunUntilSignal close the scope, if the scope has
+	// no signal policy.
+	Signals []os.Signal
+}
+
+// Options returns the effective configuration of the scope.
+func (s *Scope) Options() OptionsView {
+	o := &s.opts
+	v := OptionsView{
+		Name:            o.name,
+		ShutdownTimeout: o.shutdownTimeout,
+		SoftCancel:      o.softCancel,
+		GracePeriod:     o.grace,
+		MaxLifetime:     o.maxLifetime,
+		Limit:           o.limit,
+		AdaptiveLimit:   o.adaptive != nil,
+		ParallelStops:   o.parallelStops,
+		FailFast:        o.failFast,
+		Retain:          o.retain,
+		ProbeTimeout:    o.probeTimeout,
+		FinalTimeout:    o.finalTimeout,
+	}
+	if len(o.signals) == 0 {
+		v.Signals = slices.Clone(defaultSignals)
+	} else {
+		v.Signals = slices.SortedFunc(maps.Keys(o.signals), func(a, b os.Signal) int {
+			return cmp.Compare(a.String(), b.String())
+		})
+	}
+	if o.softCancel > 0 {
+		v.Phases = append(v.Phases, PhaseSoftCancel)
+	}
+	v.Phases = append(v.Phases, PhaseChildren, PhaseStop, PhaseCancel)
+	return v
+}
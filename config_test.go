@@ -0,0 +1,62 @@
+package scope
+
+import (
+	"os"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestScopeOptions(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		s := newScope(t)
+		defer closeScope(s)
+
+		v := s.Options()
+		switch {
+		case v.ShutdownTimeout != 0 || v.Limit != 0 || v.FailFast || v.ProbeTimeout != defaultProbeTimeout:
+			t.Fatalf("unexpected options: %+v", v)
+		case !slices.Equal(v.Phases, []string{PhaseChildren, PhaseStop, PhaseCancel}):
+			t.Fatalf("unexpected phases: %v", v.Phases)
+		case !slices.Equal(v.Signals, defaultSignals):
+			t.Fatalf("unexpected signals: %v", v.Signals)
+		}
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		s := New(
+			WithName("svc"),
+			WithShutdownTimeout(time.Minute),
+			WithSoftCancel(time.Second),
+			WithAdaptiveLimit(2, 8, 0),
+			WithFailFast(),
+			WithProbeTimeout(time.Second),
+			WithFinalTimeout(time.Second),
+			WithCancelOnSignal(syscall.SIGHUP),
+		)
+		defer closeScope(s)
+
+		v := s.Options()
+		switch {
+		case v.Name != "svc":
+			t.Fatalf("unexpected name: %q", v.Name)
+		case v.ShutdownTimeout != time.Minute:
+			t.Fatalf("unexpected shutdown timeout: %v", v.ShutdownTimeout)
+		case v.SoftCancel != time.Second:
+			t.Fatalf("unexpected soft cancel period: %v", v.SoftCancel)
+		case v.Limit != 8 || !v.AdaptiveLimit:
+			t.Fatalf("unexpected limit: %d (adaptive=%t)", v.Limit, v.AdaptiveLimit)
+		case !v.FailFast:
+			t.Fatal("fail fast expected")
+		case v.ProbeTimeout != time.Second:
+			t.Fatalf("unexpected probe timeout: %v", v.ProbeTimeout)
+		case v.FinalTimeout != time.Second:
+			t.Fatalf("unexpected final timeout: %v", v.FinalTimeout)
+		case !slices.Equal(v.Phases, []string{PhaseSoftCancel, PhaseChildren, PhaseStop, PhaseCancel}):
+			t.Fatalf("unexpected phases: %v", v.Phases)
+		case !slices.Equal(v.Signals, []os.Signal{syscall.SIGHUP}):
+			t.Fatalf("unexpected signals: %v", v.Signals)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package scope
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// DrainPolicy defines how a consumer treats the items, which are still
+// buffered in its channel when the scope is closed.
+type DrainPolicy int
+
+const (
+	// Drain processes all buffered items before the consumer stops.
+	Drain DrainPolicy = iota
+	// Abandon stops the consumer immediately and leaves all buffered
+	// items unprocessed.
+	Abandon
+)
+
+type consumeOptions struct {
+	drain DrainPolicy
+}
+
+// ConsumeOption represents an option which can be used to configure
+// a consumer (see Consume).
+type ConsumeOption func(*consumeOptions)
+
+// WithDrainPolicy defines how buffered items are treated when the
+// scope is closed. The default policy is Drain.
+func WithDrainPolicy(p DrainPolicy) ConsumeOption {
+	return func(o *consumeOptions) {
+		o.drain = p
+	}
+}
+
+// Consumer is a consumer of a channel, which is managed by a scope (see
+// Consume).
+type Consumer struct {
+	queued   func() int
+	inFlight atomic.Int64
+}
+
+// Stats returns the pending work of the consumer. The queued items are
+// buffered in the consumer's channel, the item in flight is handled.
+func (c *Consumer) Stats() WorkStats {
+	return WorkStats{Queued: c.queued(), InFlight: int(c.inFlight.Load())}
+}
+
+// Consume starts a service which calls handle for each item received
+// from the given channel until the channel is closed or the scope is
+// closed. If handle returns an error, the consumer stops and the error
+// will be reported by the scope's error handler. When the scope is
+// closed, the consumer's stop function waits until the consumer has
+// finished according to its drain policy.
+func Consume[T any](s *Scope, ch <-chan T, handle func(context.Context, T) error, o ...ConsumeOption) *Consumer {
+	opts := consumeOptions{drain: Drain}
+	for _, apply := range o {
+		apply(&opts)
+	}
+
+	c := &Consumer{queued: func() int { return len(ch) }}
+	handle = track(c, handle)
+	var once sync.Once
+	stop := make(chan struct{})
+	closeStop := func() { once.Do(func() { close(stop) }) }
+
+	done := make(chan struct{})
+	s.Start(Service{
+		Start: func(ctx context.Context) error {
+			defer close(done)
+			s.addConsumer(c)
+			defer s.removeConsumer(c)
+			for {
+				select {
+				case v, ok := <-ch:
+					if !ok {
+						return nil
+					}
+					if err := handle(ctx, v); err != nil {
+						return err
+					}
+				case <-stop:
+					if opts.drain == Abandon {
+						return nil
+					}
+					return drain(ctx, ch, handle)
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		},
+		Stop: func(ctx context.Context) error {
+			closeStop()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+	return c
+}
+
+// track returns a handle function, which counts the items of the
+// consumer in flight.
+func track[T any](c *Consumer, handle func(context.Context, T) error) func(context.Context, T) error {
+	return func(ctx context.Context, v T) error {
+		c.inFlight.Add(1)
+		defer c.inFlight.Add(-1)
+		return handle(ctx, v)
+	}
+}
+
+func (s *Scope) addConsumer(c *Consumer) {
+	s.mtx.Lock()
+	if s.consumers == nil {
+		s.consumers = make(map[*Consumer]struct{})
+	}
+	s.consumers[c] = struct{}{}
+	s.mtx.Unlock()
+}
+
+func (s *Scope) removeConsumer(c *Consumer) {
+	s.mtx.Lock()
+	delete(s.consumers, c)
+	s.mtx.Unlock()
+}
+
+func drain[T any](ctx context.Context, ch <-chan T, handle func(context.Context, T) error) error {
+	for {
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := handle(ctx, v); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package scope
+
+import (
+	"context"
+	"testing"
+)
+
+func TestConsume(t *testing.T) {
+	t.Run("channel-closed", func(t *testing.T) {
+		ch := make(chan int, 3)
+		ch <- 1
+		ch <- 2
+		ch <- 3
+		close(ch)
+
+		var sum int
+		done := make(chan struct{})
+		s := newScope(t)
+		Consume(s, ch, func(_ context.Context, v int) error {
+			if sum += v; sum == 6 {
+				close(done)
+			}
+			return nil
+		})
+
+		<-done
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("drain", func(t *testing.T) {
+		ch := make(chan int, 3)
+		ch <- 1
+		ch <- 2
+		ch <- 3
+
+		var handled int
+		block := make(chan struct{})
+		s := newScope(t)
+		Consume(s, ch, func(context.Context, int) error {
+			if handled++; handled == 1 {
+				<-block
+			}
+			return nil
+		})
+
+		closed := make(chan error, 1)
+		go func() { closed <- closeScope(s) }()
+		close(block)
+
+		if err := <-closed; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if handled != 3 {
+			t.Fatalf("unexpected number of handled items: %d", handled)
+		}
+	})
+
+	t.Run("abandon", func(t *testing.T) {
+		ch := make(chan int, 3)
+
+		s := newScope(t)
+		Consume(s, ch, func(context.Context, int) error {
+			t.Fatal("unexpected call")
+			return nil
+		}, WithDrainPolicy(Abandon))
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ch <- 1
+	})
+}
@@ -0,0 +1,207 @@
+package scope
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed cron expression with the five fields minute,
+// hour, day of month, month, and day of week. Each field is a bit set of
+// the allowed values.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool // the field was "*", which affects the day matching
+	loc                           *time.Location
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	dayNames   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// parseCron parses a cron expression, e.g. "0 3 * * *". The fields may
+// hold values, ranges ("1-5"), lists ("1,15"), steps ("*/10" or "0-30/5"),
+// and the names of months and days ("jan", "mon"). Instead of the fields,
+// one of the descriptors "@yearly", "@monthly", "@weekly", "@daily", or
+// "@hourly" may be used. The schedule is evaluated in the given location.
+func parseCron(spec string, loc *time.Location) (*cronSchedule, error) {
+	expr := strings.TrimSpace(spec)
+	if d, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("scope: invalid cron expression %q: expected 5 fields", spec)
+	}
+
+	c := &cronSchedule{
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+		loc:    loc,
+	}
+	var err error
+	for _, f := range []struct {
+		dst      *uint64
+		min, max int
+		names    []string
+	}{
+		{&c.minute, 0, 59, nil},
+		{&c.hour, 0, 23, nil},
+		{&c.dom, 1, 31, nil},
+		{&c.month, 1, 12, monthNames},
+		{&c.dow, 0, 7, dayNames},
+	} {
+		field := fields[0]
+		fields = fields[1:]
+		if *f.dst, err = parseCronField(field, f.min, f.max, f.names); err != nil {
+			return nil, fmt.Errorf("scope: invalid cron expression %q: %w", spec, err)
+		}
+	}
+	// Sunday is either 0 or 7.
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	return c, nil
+}
+
+// parseCronField parses a comma-separated list of values, ranges, and
+// steps into a bit set.
+func parseCronField(field string, min, max int, names []string) (uint64, error) {
+	var set uint64
+	for part := range strings.SplitSeq(field, ",") {
+		rng, step, hasStep := strings.Cut(part, "/")
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = parseCronValue(a, min, max, names); err != nil {
+				return 0, err
+			}
+			if hi, err = parseCronValue(b, min, max, names); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		default:
+			v, err := parseCronValue(rng, min, max, names)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			if !hasStep {
+				hi = v
+			}
+		}
+
+		n := 1
+		if hasStep {
+			var err error
+			if n, err = strconv.Atoi(step); err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", step)
+			}
+		}
+		for v := lo; v <= hi; v += n {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func parseCronValue(s string, min, max int, names []string) (int, error) {
+	for i, name := range names {
+		if name != "" && strings.EqualFold(s, name) {
+			return i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	return v, nil
+}
+
+// next returns the first time after t, which matches the schedule. It
+// returns the zero time if there is no such time within five years,
+// e.g. for "0 0 30 2 *".
+//
+// Minutes and hours are advanced in absolute time, so the wall clock
+// skips the hour lost to daylight saving time and passes the repeated
+// hour twice.
+func (c *cronSchedule) next(t time.Time) time.Time {
+	t = t.In(c.loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		y, m, d := t.Date()
+		switch {
+		case !has(c.month, int(m)):
+			t = forward(t, time.Date(y, m+1, 1, 0, 0, 0, 0, c.loc))
+		case !c.dayMatches(t):
+			t = forward(t, time.Date(y, m, d+1, 0, 0, 0, 0, c.loc))
+		case !has(c.hour, t.Hour()):
+			t = nextHour(t)
+		case !has(c.minute, t.Minute()):
+			t = t.Add(time.Duration(c.minutesUntilMatch(t.Minute())) * time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches reports whether the day of t matches the schedule. If both,
+// the day of month and the day of week, are restricted, either of them
+// has to match.
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	dom := has(c.dom, t.Day())
+	dow := has(c.dow, int(t.Weekday()))
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// minutesUntilMatch returns the number of minutes until the next allowed
+// minute or the next full hour.
+func (c *cronSchedule) minutesUntilMatch(minute int) int {
+	rest := c.minute >> (minute + 1)
+	if rest == 0 {
+		return 60 - minute
+	}
+	return bits.TrailingZeros64(rest) + 1
+}
+
+// forward returns u if it is after t, and the next full hour after t
+// otherwise. The latter happens when u was normalized backwards because
+// its wall clock time does not exist in the location.
+func forward(t, u time.Time) time.Time {
+	if u.After(t) {
+		return u
+	}
+	return nextHour(t)
+}
+
+// nextHour returns the next full hour after t, which has no seconds.
+func nextHour(t time.Time) time.Time {
+	return t.Add(time.Duration(60-t.Minute()) * time.Minute)
+}
+
+func has(set uint64, v int) bool {
+	return set&(1<<v) != 0
+}
@@ -0,0 +1,88 @@
+package scope
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone not available: %v", err)
+	}
+	now := time.Date(2026, time.March, 28, 23, 59, 30, 0, berlin) // Saturday
+
+	tests := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2026, time.March, 29, 0, 0, 0, 0, berlin)},
+		{"0 3 * * *", time.Date(2026, time.March, 29, 3, 0, 0, 0, berlin)},
+		{"*/15 9-17 * * mon-fri", time.Date(2026, time.March, 30, 9, 0, 0, 0, berlin)},
+		{"30 8 1,15 * *", time.Date(2026, time.April, 1, 8, 30, 0, 0, berlin)},
+		{"0 0 1 jan *", time.Date(2027, time.January, 1, 0, 0, 0, 0, berlin)},
+		{"0 12 * * 7", time.Date(2026, time.March, 29, 12, 0, 0, 0, berlin)},
+		{"0 12 13 * fri", time.Date(2026, time.April, 3, 12, 0, 0, 0, berlin)},
+		{"@hourly", time.Date(2026, time.March, 29, 0, 0, 0, 0, berlin)},
+		{"@monthly", time.Date(2026, time.April, 1, 0, 0, 0, 0, berlin)},
+		// 02:30 does not exist on the day of the switch to daylight saving time.
+		{"30 2 29 3 *", time.Date(2027, time.March, 29, 2, 30, 0, 0, berlin)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, test := range tests {
+		c, err := parseCron(test.spec, berlin)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.spec, err)
+		}
+		if next := c.next(now); !next.Equal(test.expected) {
+			t.Errorf("%s: unexpected next time: %v", test.spec, next)
+		}
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "* * * foo *"} {
+		if _, err := parseCron(spec, berlin); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
+
+func TestParseCronDaylightSaving(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone not available: %v", err)
+	}
+
+	t.Run("spring forward", func(t *testing.T) {
+		c, err := parseCron("30 2 * * *", newYork)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// 02:30 does not exist on 2026-03-08.
+		now := time.Date(2026, time.March, 7, 12, 0, 0, 0, newYork)
+		expected := time.Date(2026, time.March, 9, 2, 30, 0, 0, newYork)
+		if next := c.next(now); !next.Equal(expected) {
+			t.Fatalf("unexpected next time: %v", next)
+		}
+	})
+
+	t.Run("fall back", func(t *testing.T) {
+		c, err := parseCron("0 * * * *", newYork)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// 01:00 occurs twice on 2026-11-01, first in EDT (05:00 UTC)
+		// and then in EST (06:00 UTC).
+		now := time.Date(2026, time.November, 1, 4, 30, 0, 0, time.UTC)
+		for _, expected := range []time.Time{
+			time.Date(2026, time.November, 1, 5, 0, 0, 0, time.UTC),
+			time.Date(2026, time.November, 1, 6, 0, 0, 0, time.UTC),
+			time.Date(2026, time.November, 1, 7, 0, 0, 0, time.UTC),
+		} {
+			next := c.next(now)
+			if !next.Equal(expected) {
+				t.Fatalf("unexpected next time after %v: %v", now, next)
+			}
+			now = next
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package scope
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// strictPanic is called with the diagnostic message of a strict scope.
+// It can be replaced in tests.
+var strictPanic = func(msg string) { panic(msg) }
+
+// warnDeadline reports the tasks, which are still running shortly
+// before the end of the grace period.
+func (s *Scope) warnDeadline() {
+	running := s.runningTasks()
+	names := make([]string, len(running))
+	for i, t := range running {
+		names[i] = t.String()
+	}
+
+	s.log(slog.LevelWarn, "shutdown deadline approaching", "margin", s.opts.warnMargin, "running", names)
+	s.record("timeout", nil, "deadline", "approaching", "running", strings.Join(names, ","))
+	if s.opts.warn != nil {
+		s.opts.warn(running)
+	}
+}
+
+// runningTasks returns the info of all tasks, whose start function is
+// still running.
+func (s *Scope) runningTasks() []TaskInfo {
+	s.mtx.Lock()
+	tasks := s.tasks
+	s.mtx.Unlock()
+
+	var infos []TaskInfo
+	for _, t := range tasks {
+		if t.state.is(running) {
+			infos = append(infos, t.info())
+		}
+	}
+	return infos
+}
+
+// unfinishedTasks returns the info of all tasks, whose start or stop
+// function is still running.
+func (s *Scope) unfinishedTasks() []TaskInfo {
+	s.mtx.Lock()
+	tasks := s.tasks
+	s.mtx.Unlock()
+
+	var infos []TaskInfo
+	for _, t := range tasks {
+		if t.state.is(running) || t.stopping.Load() {
+			infos = append(infos, t.info())
+		}
+	}
+	return infos
+}
+
+// failStrict panics with a diagnostic message, which lists the tasks
+// still running at the end of the grace period (see WithStrictClose).
+func (s *Scope) failStrict() {
+	running := s.runningTasks()
+	if len(running) == 0 {
+		return
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "scope: %d task(s) still running after the grace period of %v:\n", len(running), s.opts.grace)
+	for _, t := range running {
+		fmt.Fprintf(&buf, "\t%s (registered at %s)\n", t, t.Caller)
+	}
+	buf.WriteString("\ngoroutines:\n\n")
+	if err := s.DumpGoroutines(&buf); err != nil {
+		fmt.Fprintf(&buf, "\t%v\n", err)
+	}
+	strictPanic(buf.String())
+}
+
+// StallInfo describes a shutdown, which exceeded the stall threshold
+// (see WithStallHandler).
+type StallInfo struct {
+	Duration   time.Duration // time since Close was called
+	Running    []TaskInfo    // tasks, whose start function is still running
+	Stopping   []TaskInfo    // tasks, whose stop function is still running
+	Goroutines []byte        // stacks of the scope's goroutines (see Scope.DumpGoroutines)
+}
+
+// watchStall reports a stall of the shutdown, which started at the given
+// time (see WithStallHandler). The returned function stops watching and
+// waits for a report in flight, so the stall handler is not called after
+// Close returned.
+func (s *Scope) watchStall(started time.Time) (stop func()) {
+	d := s.opts.stallThreshold
+	if d <= 0 {
+		return func() {}
+	}
+
+	reported := make(chan struct{})
+	timer := time.AfterFunc(d, func() {
+		defer close(reported)
+		s.reportStall(started)
+	})
+	return func() {
+		if !timer.Stop() {
+			<-reported
+		}
+	}
+}
+
+// reportStall reports the tasks, which keep the shutdown from finishing
+// (see WithStallHandler).
+func (s *Scope) reportStall(started time.Time) {
+	s.mtx.Lock()
+	tasks := s.tasks
+	s.mtx.Unlock()
+
+	info := StallInfo{Duration: time.Since(started)}
+	var names []string
+	for _, t := range tasks {
+		switch {
+		case t.stopping.Load():
+			info.Stopping = append(info.Stopping, t.info())
+		case t.state.is(running):
+			info.Running = append(info.Running, t.info())
+		default:
+			continue
+		}
+		names = append(names, t.String())
+	}
+
+	var buf bytes.Buffer
+	if err := s.DumpGoroutines(&buf); err != nil {
+		fmt.Fprintf(&buf, "%v\n", err)
+	}
+	info.Goroutines = buf.Bytes()
+
+	s.log(slog.LevelWarn, "shutdown stalled", "duration", info.Duration, "unfinished", names)
+	s.record("timeout", nil, "stalled", info.Duration, "unfinished", strings.Join(names, ","))
+	if s.opts.stallHandler != nil {
+		s.opts.stallHandler(info)
+	}
+}
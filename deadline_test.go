@@ -0,0 +1,167 @@
+package scope
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScopeDeadlineWarning(t *testing.T) {
+	warned := make(chan []TaskInfo, 1)
+	s := New(
+		WithErrorHandler(func(err error) { t.Fatalf("unexpected error: %v", err) }),
+		WithGracePeriod(30*time.Millisecond),
+		WithDeadlineWarning(20*time.Millisecond, func(running []TaskInfo) { warned <- running }),
+	)
+
+	s.Start(Service{
+		Name: "stubborn",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Stop: func(context.Context) error {
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		},
+	})
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case running := <-warned:
+		if len(running) != 1 || running[0].Name != "stubborn" {
+			t.Fatalf("unexpected running tasks: %v", running)
+		}
+	default:
+		t.Fatal("expected deadline warning")
+	}
+}
+
+func TestScopeStrictClose(t *testing.T) {
+	diagnostic := make(chan string, 1)
+	defer func(f func(string)) { strictPanic = f }(strictPanic)
+	strictPanic = func(msg string) { diagnostic <- msg }
+
+	s := New(
+		WithErrorHandler(func(err error) { t.Fatalf("unexpected error: %v", err) }),
+		WithGracePeriod(10*time.Millisecond),
+		WithStrictClose(),
+	)
+
+	release := make(chan struct{})
+	s.Start(Service{
+		Name: "stubborn",
+		Start: func(context.Context) error {
+			<-release
+			return nil
+		},
+	})
+
+	closed := make(chan error, 1)
+	go func() { closed <- closeScope(s) }()
+
+	msg := <-diagnostic
+	close(release)
+	if err := <-closed; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	switch {
+	case !strings.Contains(msg, "1 task(s) still running"):
+		t.Fatalf("unexpected diagnostic:\n%s", msg)
+	case !strings.Contains(msg, "stubborn"):
+		t.Fatalf("expected task name in diagnostic:\n%s", msg)
+	case !strings.Contains(msg, "TestScopeStrictClose"):
+		t.Fatalf("expected goroutine in diagnostic:\n%s", msg)
+	}
+}
+
+func TestWithStallHandler(t *testing.T) {
+	stalled := make(chan StallInfo, 1)
+	s := New(
+		WithErrorHandler(func(err error) { t.Errorf("unexpected error: %v", err) }),
+		WithStallHandler(10*time.Millisecond, func(info StallInfo) { stalled <- info }),
+	)
+
+	release := make(chan struct{})
+	s.Start(Service{
+		Name: "stubborn",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Stop: func(context.Context) error {
+			<-release
+			return nil
+		},
+	})
+	s.Start(Service{
+		Name: "worker",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+	})
+
+	closed := make(chan error, 1)
+	go func() { closed <- s.Close() }()
+
+	info := <-stalled
+	close(release)
+	if err := <-closed; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(info.Stopping) != 1 || info.Stopping[0].Name != "stubborn" {
+		t.Fatalf("unexpected stopping tasks: %v", info.Stopping)
+	}
+	if len(info.Running) != 1 || info.Running[0].Name != "worker" {
+		t.Fatalf("unexpected running tasks: %v", info.Running)
+	}
+	if info.Duration < 10*time.Millisecond || !strings.Contains(string(info.Goroutines), "TestWithStallHandler") {
+		t.Fatalf("unexpected stall info: %v\n%s", info.Duration, info.Goroutines)
+	}
+}
+
+func TestWithStallHandlerInFlight(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	var handled atomic.Bool
+	s := New(
+		WithErrorHandler(func(err error) { t.Errorf("unexpected error: %v", err) }),
+		WithStallHandler(time.Millisecond, func(StallInfo) {
+			close(entered)
+			<-release
+			handled.Store(true)
+		}),
+	)
+
+	stop := make(chan struct{})
+	s.Defer(func(context.Context) error {
+		<-stop
+		return nil
+	})
+
+	closed := make(chan error, 1)
+	go func() { closed <- s.Close() }()
+
+	<-entered
+	close(stop)
+	select {
+	case err := <-closed:
+		t.Fatalf("Close returned before the stall handler: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-closed; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !handled.Load() {
+		t.Fatal("stall handler not finished")
+	}
+}
@@ -0,0 +1,42 @@
+package scope
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime/pprof"
+	"strconv"
+	"sync/atomic"
+)
+
+const (
+	scopeLabel = "scope"
+	taskLabel  = "scope.task"
+)
+
+var lastScopeID atomic.Uint64
+
+// DumpGoroutines writes the stacks of all goroutines, which belong to
+// the scope's tasks, to w. Tasks are identified by their pprof labels
+// "scope" and "scope.task", which are also inherited by goroutines
+// spawned from within a task.
+func (s *Scope) DumpGoroutines(w io.Writer) error {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		return err
+	}
+
+	label := fmt.Sprintf("%q:%q", scopeLabel, s.id)
+	for _, block := range bytes.Split(buf.Bytes(), []byte("\n\n")) {
+		if bytes.Contains(block, []byte(label)) {
+			if _, err := fmt.Fprintf(w, "%s\n\n", bytes.TrimSpace(block)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Scope) labels(idx int) pprof.LabelSet {
+	return pprof.Labels(scopeLabel, s.id, taskLabel, strconv.Itoa(idx))
+}
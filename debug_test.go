@@ -0,0 +1,44 @@
+package scope
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestScopeDumpGoroutines(t *testing.T) {
+	running := make(chan struct{})
+	s := newScope(t)
+	s.Go(func(ctx context.Context) error {
+		close(running)
+		<-ctx.Done()
+		return nil
+	})
+	other := newScope(t)
+	other.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	<-running
+
+	var buf bytes.Buffer
+	if err := s.DumpGoroutines(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dump := buf.String()
+	if !strings.Contains(dump, "TestScopeDumpGoroutines.func1") {
+		t.Fatalf("expected task in goroutine dump, got:\n%s", dump)
+	}
+	if strings.Contains(dump, "TestScopeDumpGoroutines.func2") {
+		t.Fatalf("unexpected task of other scope in goroutine dump:\n%s", dump)
+	}
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := closeScope(other); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// ErrDependencyCycle is reported when a service is started, whose
+// dependencies depend on the service itself (see Service.DependsOn).
+var ErrDependencyCycle = errors.New("scope: dependency cycle")
+
+// addDependencies adds the dependencies of the task to the dependency
+// graph of the scope. If they would introduce a cycle, the graph is left
+// unchanged and an error is returned, which describes the cycle.
+func (s *Scope) addDependencies(t *task) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if slices.Contains(t.svc.DependsOn, t.name) {
+		return fmt.Errorf("%w: %s -> %[2]s", ErrDependencyCycle, t.name)
+	}
+	if t.name == "" {
+		// Unnamed services cannot be depended upon.
+		return nil
+	}
+	for _, dep := range t.svc.DependsOn {
+		if path := s.dependencyPath(dep, t.name, nil); path != nil {
+			path = append([]string{t.name}, path...)
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(path, " -> "))
+		}
+	}
+
+	if s.deps == nil {
+		s.deps = make(map[string][]string)
+	}
+	s.deps[t.name] = append(s.deps[t.name], t.svc.DependsOn...)
+	return nil
+}
+
+// dependencyPath returns the path of dependencies from one service to
+// another, or nil if there is none. It must be called with the scope's
+// mutex held.
+func (s *Scope) dependencyPath(from, to string, visited map[string]bool) []string {
+	if from == to {
+		return []string{to}
+	}
+	if visited[from] {
+		return nil
+	}
+	if visited == nil {
+		visited = make(map[string]bool)
+	}
+	visited[from] = true
+
+	for _, dep := range s.deps[from] {
+		if path := s.dependencyPath(dep, to, visited); path != nil {
+			return append([]string{from}, path...)
+		}
+	}
+	return nil
+}
+
+// launchAfterDependencies launches the registered task as soon as its
+// dependencies are ready (see awaitDependenciesLocked). The task is
+// skipped if the scope was closed before.
+func (s *Scope) launchAfterDependencies(t *task) {
+	s.mtx.Lock()
+	if isClosed(s.closing) {
+		s.mtx.Unlock()
+		t.setState(skipped)
+		s.notify()
+		t.finish()
+		return
+	}
+	run := s.awaitDependenciesLocked(t)
+	s.mtx.Unlock()
+	s.spawn(run)
+}
+
+// awaitDependenciesLocked returns the function, which launches the task
+// as soon as its dependencies are ready. The task is skipped if the scope
+// is closed or the task is retired before (see StartGroup), and fails if
+// one of the dependencies fails. It must be called with the scope's mutex
+// held.
+func (s *Scope) awaitDependenciesLocked(t *task) func() {
+	closing := s.closing
+	// See prepareLocked for why the wait group
+	// is modified with the mutex held.
+	s.wg.Add(1)
+
+	// The function reports the error of a failed dependency, so it
+	// is run by the executor.
+	return func() {
+		defer s.wg.Done()
+
+		ctx, cancel := context.WithCancel(s.ctx)
+		defer cancel()
+		go func() {
+			select {
+			case <-closing:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		s.log(slog.LevelDebug, "task waiting for dependencies", "task", t, "dependencies", t.svc.DependsOn)
+		err := s.WaitFor(ctx, t.svc.DependsOn...)
+
+		switch {
+		case ctx.Err() != nil || t.retired.Load():
+			t.setState(skipped)
+			s.notify()
+			t.finish()
+		case err != nil:
+			s.fail(t, err, 0)
+			t.finish()
+		default:
+			s.launch(t)
+		}
+	}
+}
+
+// dependencyOrder returns the tasks in registration order, but with the
+// dependencies of a task moved before the task itself. Dependencies are
+// declared explicitly (see Service.DependsOn) or inferred from lookups
+// (see Get).
+func dependencyOrder(tasks []*task) []*task {
+	byName := make(map[string][]*task)
+	for _, t := range tasks {
+		if t.name != "" {
+			byName[t.name] = append(byName[t.name], t)
+		}
+	}
+
+	ordered := make([]*task, 0, len(tasks))
+	visited := make(map[*task]bool, len(tasks))
+	for _, t := range tasks {
+		visited[t] = false
+	}
+	var visit func(t *task)
+	visit = func(t *task) {
+		if done, ok := visited[t]; done || !ok {
+			return // visited or not part of the tasks
+		}
+		visited[t] = true
+		for _, dep := range t.svc.DependsOn {
+			for _, d := range byName[dep] {
+				visit(d)
+			}
+		}
+		t.mtx.Lock()
+		providers := slices.Clone(t.providers)
+		t.mtx.Unlock()
+		for _, p := range providers {
+			visit(p)
+		}
+		ordered = append(ordered, t)
+	}
+	for _, t := range tasks {
+		visit(t)
+	}
+	return ordered
+}
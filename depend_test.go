@@ -0,0 +1,155 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestServiceDependsOn(t *testing.T) {
+	t.Run("order", func(t *testing.T) {
+		s := newScope(t)
+
+		var (
+			mtx    sync.Mutex
+			events []string
+		)
+		event := func(e string) {
+			mtx.Lock()
+			events = append(events, e)
+			mtx.Unlock()
+		}
+		service := func(name string, ready Func, deps ...string) Service {
+			return Service{
+				Name: name,
+				Start: func(ctx context.Context) error {
+					event("start " + name)
+					<-ctx.Done()
+					return nil
+				},
+				Stop: func(context.Context) error {
+					event("stop " + name)
+					return nil
+				},
+				Ready:     ready,
+				DependsOn: deps,
+			}
+		}
+
+		release := make(chan struct{})
+		s.Start(service("api", nil, "db", "cache"))
+		s.Start(service("cache", nil, "db"))
+		s.Start(service("db", func(context.Context) error {
+			<-release
+			return nil
+		}))
+
+		// The dependent services are not started before the
+		// database is ready.
+		short, cancelShort := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancelShort()
+		if err := s.WaitFor(short, "cache"); err != context.DeadlineExceeded {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		close(release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := s.WaitFor(ctx, "api"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := []string{
+			"start db", "start cache", "start api",
+			"stop api", "stop cache", "stop db",
+		}
+		if !slices.Equal(events, expected) {
+			t.Fatalf("unexpected events: %v", events)
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		var errs []error
+		s := New(WithErrorHandler(CollectErrors(&errs)))
+
+		start := func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}
+		s.Start(Service{Name: "a", Start: start, DependsOn: []string{"b"}})
+		s.Start(Service{Name: "b", Start: start, DependsOn: []string{"c"}})
+		task := s.Start(Service{Name: "c", Start: start, DependsOn: []string{"a"}})
+
+		if err := task.Err(); !errors.Is(err, ErrDependencyCycle) || !strings.HasSuffix(err.Error(), "c -> a -> b -> c") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := s.Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// The services depending on the rejected one fail as well,
+		// unless the scope was closed before.
+		for _, err := range errs {
+			if !errors.Is(err, ErrDependencyCycle) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	})
+
+	t.Run("dependency-failed", func(t *testing.T) {
+		var errs []error
+		s := New(WithErrorHandler(CollectErrors(&errs)))
+
+		errStart := errors.New("start error")
+		s.Start(Service{
+			Name:  "db",
+			Start: func(context.Context) error { return errStart },
+			Ready: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+		})
+		start := newCall(nil)
+		task := s.Start(Service{Name: "api", Start: start.f, DependsOn: []string{"db"}})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := task.Wait(ctx); !errors.Is(err, errStart) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if start.called() {
+			t.Fatal("expected start function not to be called")
+		}
+		if err := s.Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(errs) != 2 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		s := newScope(t)
+
+		start := newCall(nil)
+		stop := newCall(nil)
+		task := s.Start(Service{Name: "api", Start: start.f, Stop: stop.f, DependsOn: []string{"db"}})
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		<-task.Done()
+		if err := task.Err(); err != ErrNotStarted {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if start.called() || stop.called() {
+			t.Fatal("expected service not to be started or stopped")
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package scope
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The core package must not depend on anything but the standard library.
+// Integrations with third-party packages belong into sub-packages.
+func TestStdlibOnly(t *testing.T) {
+	for file, path := range imports(t, ".", false) {
+		if !isStdlib(path) {
+			t.Errorf("%s: non-stdlib import %q", file, path)
+		}
+	}
+}
+
+// Sub-packages with third-party imports have to be separate modules, so
+// their dependencies do not end up in the core module's dependency graph.
+func TestIntegrationModules(t *testing.T) {
+	dirs, err := os.ReadDir(".")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() || !strings.HasPrefix(dir.Name(), "scope") {
+			continue
+		}
+		for file, path := range imports(t, dir.Name(), true) {
+			if isStdlib(path) || path == "github.com/tsne/scope" || strings.HasPrefix(path, "github.com/tsne/scope/") {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(dir.Name(), "go.mod")); err != nil {
+				t.Errorf("%s: third-party import %q without a separate module", file, path)
+			}
+			break
+		}
+	}
+}
+
+// The core module must not require any other module. Unlike the imports,
+// this covers the requirements of the go.mod file as well.
+func TestNoModuleRequirements(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not available")
+	}
+	out, err := exec.Command("go", "env", "GOMOD").Output()
+	if gomod := strings.TrimSpace(string(out)); err != nil || gomod == "" || gomod == os.DevNull {
+		t.Skip("not built as a module")
+	}
+
+	out, err = exec.Command("go", "list", "-m", "all").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			t.Fatalf("unexpected error: %v\n%s", err, exitErr.Stderr)
+		}
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mods := strings.Fields(string(out)); len(mods) != 1 {
+		t.Fatalf("unexpected modules: %v", mods)
+	}
+}
+
+// imports returns the import paths of the Go files in the given
+// directory together with the importing file. Test files are only
+// included if tests is set.
+func imports(t *testing.T, dir string, tests bool) func(yield func(string, string) bool) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fset := token.NewFileSet()
+	return func(yield func(string, string) bool) {
+		for _, file := range files {
+			if !tests && strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+
+			f, err := parser.ParseFile(fset, file, nil, parser.ImportsOnly)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, imp := range f.Imports {
+				path, _ := strconv.Unquote(imp.Path.Value)
+				if !yield(file, path) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func isStdlib(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
@@ -0,0 +1,37 @@
+// Package scope extends the scopes of deferred executions in Go. A scope
+// runs functions concurrently (see Scope.Go and Scope.Start) and calls
+// registered clean-up functions when it is closed (see Scope.Defer and
+// Scope.Close).
+//
+// # Memory model
+//
+// The scope provides the following happens-before guarantees, which can
+// be relied upon when building synchronization on top of it. They hold
+// for start functions (see Scope.Go and Scope.Start) and stop functions
+// (see Service and Scope.Defer), which are registered before Close is
+// called:
+//
+//   - Everything before the registration of a function happens before
+//     the function is called. This also holds for services, which wait
+//     for a free slot (see WithLimit) or are held by a frozen scope (see
+//     Scope.Freeze).
+//   - The return of a start function happens before the restart of the
+//     same service (see Service and Scope.RollingRestart).
+//   - The return of a stop function happens before the next stop
+//     function is called. With a parallel shutdown, the return of all
+//     stop functions of a stop order happens before the stop functions
+//     of the next stop order are called (see WithParallelShutdown).
+//   - The return of all stop functions happens before the scope's
+//     context is done, unless the shutdown timed out (see
+//     Scope.CloseContext).
+//   - The return of all start and stop functions and of all calls of the
+//     error handler happens before Close returns. If CloseContext returns
+//     with an error of type *ShutdownTimeoutError, this only holds for
+//     the tasks, which are not listed in the error.
+//   - The return of a start function and the call of the error handler
+//     for its error happen before the task's handle is done (see Task).
+//   - A service reporting ready happens before WaitFor returns for the
+//     service (see Scope.WaitFor).
+//
+// These guarantees are verified by race-detector stress tests.
+package scope
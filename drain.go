@@ -0,0 +1,63 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// ErrDraining is reported when a function is started while the scope
+// is draining (see Scope.Drain).
+var ErrDraining = errors.New("scope: scope is draining")
+
+// Drain quiesces the scope as a softer alternative to Close, e.g. before
+// the process is shut down during a rolling deploy. Afterwards, new
+// functions and services are rejected with ErrDraining (see Scope.Go and
+// Scope.Start), while the running start functions are allowed to finish
+// naturally: their contexts are not cancelled, they are not restarted,
+// and no stop function is called. Deferred functions are still accepted.
+// Drain blocks until all start functions have returned and reports
+// their errors like Scope.Wait. If the context is done before, the
+// context's error is returned and the scope keeps draining. The scope
+// still has to be closed afterwards.
+func (s *Scope) Drain(ctx context.Context) error {
+	s.mtx.Lock()
+	s.refuseLocked()
+	drained := s.draining.Swap(true)
+	s.mtx.Unlock()
+	if !drained {
+		s.log(slog.LevelInfo, "draining scope")
+		s.record("draining", nil)
+	}
+	return s.Wait(ctx)
+}
+
+// IsDraining reports whether Drain was called.
+func (s *Scope) IsDraining() bool {
+	return s.draining.Load()
+}
+
+// Accepting reports whether the scope accepts new functions and
+// services, i.e. whether neither Close nor Drain was called. Producers
+// like HTTP handlers or queue pollers can use it to stop submitting
+// background work instead of racing ErrClosed or ErrDraining.
+func (s *Scope) Accepting() bool {
+	return !isClosed(s.Rejecting())
+}
+
+// Rejecting returns a channel, which is closed as soon as the scope
+// stops accepting new functions and services, i.e. when Close or Drain
+// begins (see Accepting).
+func (s *Scope) Rejecting() <-chan struct{} {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.refuse
+}
+
+// refuseLocked closes the channel returned by Rejecting. It must be
+// called with the scope's mutex held.
+func (s *Scope) refuseLocked() {
+	if !isClosed(s.refuse) {
+		close(s.refuse)
+	}
+}
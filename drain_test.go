@@ -0,0 +1,115 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScopeDrain(t *testing.T) {
+	var errs []error
+	s := New(WithErrorHandler(CollectErrors(&errs)))
+
+	finish := make(chan struct{})
+	var cancelled, stopped, restarted atomic.Bool
+	var runs atomic.Int32
+	s.Start(Service{
+		Start: func(ctx context.Context) error {
+			if runs.Add(1) > 1 {
+				restarted.Store(true)
+			}
+			select {
+			case <-finish:
+				return nil
+			case <-ctx.Done():
+				cancelled.Store(true)
+				return nil
+			}
+		},
+		Stop: func(context.Context) error {
+			stopped.Store(true)
+			return nil
+		},
+		Restart: RestartAlways,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !s.IsDraining() {
+		t.Fatal("draining scope expected")
+	}
+
+	if _, err := s.TryStart(Service{Start: func(context.Context) error { return nil }}); err != ErrDraining {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rejected := newCall(nil)
+	if err := s.Go(rejected.f).Wait(context.Background()); err != ErrDraining {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rejected.called() {
+		t.Fatal("rejected function was called")
+	}
+
+	close(finish)
+	if err := s.Drain(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	switch {
+	case cancelled.Load():
+		t.Fatal("start function was cancelled")
+	case restarted.Load():
+		t.Fatal("start function was restarted")
+	case stopped.Load():
+		t.Fatal("stop function was called before close")
+	}
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !stopped.Load() {
+		t.Fatal("stop function was not called")
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrDraining) {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+}
+
+func TestScopeAccepting(t *testing.T) {
+	t.Run("drain", func(t *testing.T) {
+		s := newScope(t)
+		rejecting := s.Rejecting()
+		if !s.Accepting() || isClosed(rejecting) {
+			t.Fatal("accepting scope expected")
+		}
+		if err := s.Drain(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if s.Accepting() || !isClosed(rejecting) {
+			t.Fatal("rejecting scope expected")
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("close", func(t *testing.T) {
+		s := newScope(t)
+		rejecting := s.Rejecting()
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if s.Accepting() || !isClosed(rejecting) {
+			t.Fatal("rejecting scope expected")
+		}
+
+		s.Reset()
+		if !s.Accepting() || s.IsDraining() {
+			t.Fatal("accepting scope expected after reset")
+		}
+	})
+}
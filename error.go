@@ -1,23 +1,76 @@
 package scope
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
-type errorlist []error
+// The sentinel errors allow to branch on the outcome of a lifecycle with
+// errors.Is instead of matching the error messages.
+var (
+	// ErrShutdownTimeout is matched by a *ShutdownTimeoutError, i.e. when
+	// the shutdown did not finish in time (see Scope.CloseContext).
+	ErrShutdownTimeout = errors.New("scope: shutdown timeout")
+	// ErrStartupFailed is wrapped by the errors of services, which failed
+	// or were not started before they were ready (see Scope.WaitFor).
+	ErrStartupFailed = errors.New("scope: startup failed")
+	// ErrRestartBudgetExceeded is wrapped by the error of a service,
+	// which failed after its maximum number of restarts (see Backoff).
+	ErrRestartBudgetExceeded = errors.New("scope: restart budget exceeded")
+	// ErrRetriesExhausted is wrapped by the error of a function, which
+	// failed after its maximum number of retries (see Retry).
+	ErrRetriesExhausted = errors.New("scope: retries exhausted")
+)
 
-func (e *errorlist) append(err error) {
+// Errors holds the errors, which occurred while closing a scope. The
+// errors of stop functions are of type *TaskError. Errors can be
+// inspected with errors.Is and errors.As, which consider all errors.
+type Errors []error
+
+// Len returns the number of errors.
+func (e Errors) Len() int {
+	return len(e)
+}
+
+// All returns all errors in order of occurrence.
+func (e Errors) All() []error {
+	return append([]error(nil), e...)
+}
+
+// Unwrap returns all errors, so the errors can be inspected with
+// errors.Is and errors.As.
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// ByTask returns the errors of tasks (see TaskError) mapped by the task's
+// name, or by its index (e.g. "#3") if the task has no name.
+func (e Errors) ByTask() map[string][]error {
+	m := make(map[string][]error)
+	for _, err := range e {
+		if te, ok := err.(*TaskError); ok {
+			key := te.Task.String()
+			m[key] = append(m[key], te.Err)
+		}
+	}
+	return m
+}
+
+func (e *Errors) append(err error) {
 	if err != nil {
 		*e = append(*e, err)
 	}
 }
 
-func (e errorlist) err() error {
+func (e Errors) err() error {
 	if len(e) == 0 {
 		return nil
 	}
 	return e
 }
 
-func (e errorlist) Error() string {
+func (e Errors) Error() string {
 	switch len(e) {
 	case 0:
 		return "no error"
@@ -27,3 +80,46 @@ func (e errorlist) Error() string {
 		return fmt.Sprintf("%v (and %d more errors)", e[0], len(e)-1)
 	}
 }
+
+// TaskError is an error returned by a function of a specific task.
+type TaskError struct {
+	Task TaskInfo
+	Err  error
+}
+
+func (e *TaskError) Error() string {
+	if e.Task.Name != "" {
+		return e.Task.Name + ": " + e.Err.Error()
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the task's error.
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// ShutdownTimeoutError is returned when a scope could not be closed
+// before its deadline (see Scope.CloseContext and WithShutdownTimeout).
+type ShutdownTimeoutError struct {
+	Tasks []TaskInfo // tasks, whose start or stop function did not return
+	Err   error      // cause of the shutdown context
+}
+
+func (e *ShutdownTimeoutError) Error() string {
+	names := make([]string, len(e.Tasks))
+	for i, t := range e.Tasks {
+		names[i] = t.String()
+	}
+	return fmt.Sprintf("scope: shutdown not finished, %d task(s) still running [%s]: %v", len(e.Tasks), strings.Join(names, ", "), e.Err)
+}
+
+// Unwrap returns the cause of the shutdown context.
+func (e *ShutdownTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is ErrShutdownTimeout.
+func (e *ShutdownTimeoutError) Is(target error) bool {
+	return target == ErrShutdownTimeout
+}
@@ -0,0 +1,63 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestScopeCloseErrors(t *testing.T) {
+	errDB := errors.New("db error")
+	errCache := errors.New("cache error")
+
+	s := newScope(t)
+	s.Start(Service{
+		Name:  "db",
+		Start: func(context.Context) error { return nil },
+		Stop:  func(context.Context) error { return errDB },
+	})
+	s.Defer(func(context.Context) error { return errCache })
+	s.Defer(func(context.Context) error { return nil })
+
+	err := closeScope(s)
+	errs, ok := err.(Errors)
+	switch {
+	case !ok:
+		t.Fatalf("unexpected error: %v", err)
+	case errs.Len() != 2:
+		t.Fatalf("unexpected number of errors: %d", errs.Len())
+	case errs.Error() != "cache error (and 1 more errors)":
+		t.Fatalf("unexpected error message: %s", errs.Error())
+	}
+
+	byTask := errs.ByTask()
+	if len(byTask["db"]) != 1 || byTask["db"][0] != errDB {
+		t.Fatalf("unexpected db errors: %v", byTask["db"])
+	}
+	if len(byTask["#1"]) != 1 || byTask["#1"][0] != errCache {
+		t.Fatalf("unexpected cache errors: %v", byTask["#1"])
+	}
+}
+
+func TestErrorsUnwrap(t *testing.T) {
+	errDB := errors.New("db error")
+	errCache := errors.New("cache error")
+
+	s := newScope(t)
+	s.Start(Service{
+		Name:  "db",
+		Start: func(context.Context) error { return nil },
+		Stop:  func(context.Context) error { return errDB },
+	})
+	s.Defer(func(context.Context) error { return errCache })
+
+	err := closeScope(s)
+	if !errors.Is(err, errDB) || !errors.Is(err, errCache) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var te *TaskError
+	if !errors.As(err, &te) || te.Err != errCache {
+		t.Fatalf("unexpected task error: %v", te)
+	}
+}
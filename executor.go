@@ -0,0 +1,36 @@
+package scope
+
+// Executor runs the goroutines, which call the functions of a scope's
+// tasks (see WithExecutor). This allows to route the tasks through
+// custom worker pools, thread-affinity layers, or instrumentation.
+// Besides the start functions, the executor runs the shutdown of the
+// scope, which calls the stop and final functions, the launch of services
+// waiting for their dependencies, and the functions adapted with
+// CloseFunc. Internal goroutines of the scope, which only wait for
+// events, are not run by the executor.
+type Executor interface {
+	// Go runs the given function asynchronously. The function may block
+	// for the lifetime of a task, so Go must not wait for it to return.
+	// The function must be run eventually, since the scope waits for it
+	// when it is closed. Go must be safe for concurrent use.
+	Go(f func())
+}
+
+// ExecutorFunc is an adapter to allow the use of an ordinary function
+// as an executor.
+type ExecutorFunc func(f func())
+
+// Go calls e(f).
+func (e ExecutorFunc) Go(f func()) {
+	e(f)
+}
+
+// spawn runs the given function via the scope's executor, or in a new
+// goroutine if there is none.
+func (s *Scope) spawn(f func()) {
+	if e := s.opts.executor; e != nil {
+		e.Go(f)
+		return
+	}
+	go f()
+}
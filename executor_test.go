@@ -0,0 +1,49 @@
+package scope
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWithExecutor(t *testing.T) {
+	var spawned atomic.Int32
+	s := New(WithExecutor(ExecutorFunc(func(f func()) {
+		spawned.Add(1)
+		go f()
+	})))
+
+	started := newCall(nil)
+	s.Start(Service{
+		Start: started.f,
+		Ready: func(context.Context) error { return nil },
+	})
+	p := s.Pool(1)
+	pooled := newCall(nil)
+	p.Go(pooled.f)
+	var closed atomic.Bool
+	s.Defer(CloseFunc(func() error {
+		closed.Store(true)
+		return nil
+	}))
+
+	if err := s.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	switch {
+	case !started.called():
+		t.Fatal("start function was not called")
+	case !pooled.called():
+		t.Fatal("pooled function was not called")
+	case !closed.Load():
+		t.Fatal("close function was not called")
+	}
+	// The start function, its ready function, the pool's worker, the
+	// shutdown, and the close function were run by the executor.
+	if n := spawned.Load(); n != 5 {
+		t.Fatalf("unexpected number of goroutines: %d", n)
+	}
+}
@@ -0,0 +1,56 @@
+package scope
+
+import (
+	"context"
+	"os"
+	"slices"
+	"sync"
+)
+
+// osExit terminates the process. It can be replaced in tests.
+var osExit = os.Exit
+
+var exitScopes struct {
+	mtx    sync.Mutex
+	scopes []*Scope
+}
+
+// Exit closes all open scopes, which were created with CloseOnExit, and
+// terminates the process with the given status code afterwards. The
+// scopes are closed in reverse order of creation, each bounded by its
+// shutdown timeout (see WithShutdownTimeout). Their errors are logged
+// (see WithLogger), but do not change the status code.
+//
+// Scope-managed applications should call Exit instead of os.Exit, which
+// terminates the process immediately without calling any stop function.
+func Exit(code int) {
+	exitScopes.mtx.Lock()
+	scopes := exitScopes.scopes
+	exitScopes.scopes = nil
+	exitScopes.mtx.Unlock()
+
+	for _, s := range slices.Backward(scopes) {
+		s.CloseContext(context.Background())
+	}
+	osExit(code)
+}
+
+// CloseOnExit registers the scope to be closed by Exit. The scope is
+// deregistered when it is closed.
+func CloseOnExit() Option {
+	return func(o *options) {
+		o.closeOnExit = true
+	}
+}
+
+func registerExit(s *Scope) {
+	exitScopes.mtx.Lock()
+	exitScopes.scopes = append(exitScopes.scopes, s)
+	exitScopes.mtx.Unlock()
+}
+
+func deregisterExit(s *Scope) {
+	exitScopes.mtx.Lock()
+	defer exitScopes.mtx.Unlock()
+	exitScopes.scopes = slices.DeleteFunc(exitScopes.scopes, func(x *Scope) bool { return x == s })
+}
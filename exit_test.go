@@ -0,0 +1,47 @@
+package scope
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestExit(t *testing.T) {
+	var code int
+	osExit = func(c int) { code = c }
+	defer func() { osExit = os.Exit }()
+
+	var order []string
+	deferred := func(name string) Func {
+		return func(context.Context) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	first := New(CloseOnExit())
+	first.Defer(deferred("first"))
+	second := New(CloseOnExit())
+	second.Defer(deferred("second"))
+	closed := New(CloseOnExit())
+	if err := closeScope(closed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	unregistered := newScope(t)
+	unregistered.Defer(deferred("unregistered"))
+
+	Exit(3)
+	if code != 3 {
+		t.Fatalf("unexpected exit code: %d", code)
+	}
+	if !slices.Equal(order, []string{"second", "first"}) {
+		t.Fatalf("unexpected close order: %v", order)
+	}
+	if !first.IsClosed() || !second.IsClosed() || unregistered.IsClosed() {
+		t.Fatal("expected only the registered scopes to be closed")
+	}
+	if err := closeScope(unregistered); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package scope
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// Finally registers a function, which is called at the very end of
+// Close, i.e. after all stop and deferred functions were called and all
+// start functions returned, even if some of them failed. In contrast to
+// deferred functions, it does not interleave with the stop functions,
+// so it is the place to flush telemetry exporters, e.g. of traces,
+// metrics, or logs. The functions are called in reverse order of
+// registration with a context, which holds the values of the scope's
+// context and carries the deadline of the shutdown (see CloseContext and
+// WithFinalTimeout). Their errors are returned by Close.
+//
+// If Close gives up before the shutdown finished, the functions are
+// called right away, and their errors are reported by the error handler
+// instead. Close waits for them until the deadline of the shutdown, so
+// a final timeout should be reserved for them (see WithFinalTimeout).
+// Either way, each function is called only once. Functions registered
+// after the scope was closed are never called; ErrClosed is reported by
+// the error handler instead.
+func (s *Scope) Finally(f Func) {
+	t := &task{stop: f, state: succeeded}
+	s.capture(t)
+	if s.rejectClosed(t) {
+		return
+	}
+
+	s.mtx.Lock()
+	finalized := s.finalized
+	if !finalized {
+		s.finally = append(s.finally, t)
+	}
+	s.mtx.Unlock()
+	if finalized {
+		s.reject(t, ErrClosed)
+	}
+}
+
+// finalize calls the functions registered with Finally, unless they were
+// called already, and returns their errors. The functions' contexts are
+// derived from the given shutdown context (see shutdownContext).
+func (s *Scope) finalize(ctx context.Context, report *ShutdownReport) Errors {
+	s.mtx.Lock()
+	tasks := s.finally
+	s.finally = nil
+	s.finalized = true
+	s.mtx.Unlock()
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	if report != nil {
+		end := s.phase(report, PhaseFinally)
+		defer end()
+	}
+	var errs Errors
+	for _, t := range slices.Backward(tasks) {
+		if err := s.callFinal(ctx, t); err != nil {
+			s.log(slog.LevelError, "final function failed", "task", t, "caller", (*lazyCaller)(t), "error", err)
+			errs.append(&TaskError{Task: t.info(), Err: err})
+		}
+	}
+	return errs
+}
+
+// callFinal calls the given final function with a context, which expires
+// with the shutdown, but not later than the final timeout.
+func (s *Scope) callFinal(ctx context.Context, t *task) error {
+	ctx, cancel := s.shutdownContext(ctx)
+	defer cancel()
+	if d := s.opts.finalTimeout; d > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, d)
+		defer cancelTimeout()
+	}
+	return protect(s.withValues(ctx), t.stop)
+}
+
+// finalizeAsync calls the functions registered with Finally like
+// finalize, but only waits until the given shutdown context is done.
+// Their errors are reported by the error handler.
+func (s *Scope) finalizeAsync(ctx context.Context) {
+	done := make(chan struct{})
+	s.spawn(func() {
+		defer close(done)
+		for _, err := range s.finalize(ctx, nil) {
+			s.onError(err)
+		}
+	})
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,148 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestScopeFinally(t *testing.T) {
+	t.Run("order", func(t *testing.T) {
+		errStop := errors.New("stop failed")
+		errFinal := errors.New("flush failed")
+		var errs []error
+		s := New(WithErrorHandler(CollectErrors(&errs)))
+
+		var mtx sync.Mutex
+		var calls []string
+		call := func(name string, err error) Func {
+			return func(context.Context) error {
+				mtx.Lock()
+				calls = append(calls, name)
+				mtx.Unlock()
+				return err
+			}
+		}
+		s.Finally(call("flush-traces", errFinal))
+		s.Finally(call("flush-metrics", nil))
+		s.Start(Service{
+			Start: func(ctx context.Context) error {
+				<-ctx.Done()
+				time.Sleep(10 * time.Millisecond)
+				return call("exited", nil)(ctx)
+			},
+			Stop:      call("stop", errStop),
+			StopOrder: 1,
+		})
+		s.Defer(call("defer", nil))
+
+		err := closeScope(s)
+		if !errors.Is(err, errStop) || !errors.Is(err, errFinal) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{"defer", "stop", "exited", "flush-metrics", "flush-traces"}
+		if !slices.Equal(calls, want) {
+			t.Fatalf("unexpected calls: %v", calls)
+		}
+
+		report, _ := s.ShutdownReport()
+		if p := report.Phases[len(report.Phases)-1]; p.Name != PhaseFinally {
+			t.Fatalf("unexpected last phase: %s", p.Name)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		var errs []error
+		s := New(
+			WithErrorHandler(CollectErrors(&errs)),
+			WithFinalTimeout(time.Second),
+		)
+
+		release := make(chan struct{})
+		defer close(release)
+		s.Defer(func(context.Context) error {
+			<-release
+			return nil
+		})
+		var finalDeadline time.Time
+		s.Finally(func(ctx context.Context) error {
+			finalDeadline, _ = ctx.Deadline()
+			return nil
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second+20*time.Millisecond)
+		defer cancel()
+		deadline, _ := ctx.Deadline()
+		var timeout *ShutdownTimeoutError
+		if err := s.CloseContext(ctx); !errors.As(err, &timeout) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if finalDeadline.IsZero() || finalDeadline.After(deadline) {
+			t.Fatalf("unexpected deadline of the final function: %v", finalDeadline)
+		}
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		var errs []error
+		s := New(WithErrorHandler(CollectErrors(&errs)))
+
+		release := make(chan struct{})
+		defer close(release)
+		s.Defer(func(context.Context) error {
+			<-release
+			return nil
+		})
+		s.Finally(func(context.Context) error {
+			<-release
+			return nil
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		closed := make(chan error, 1)
+		go func() { closed <- s.CloseContext(ctx) }()
+		select {
+		case err := <-closed:
+			var timeout *ShutdownTimeoutError
+			if !errors.As(err, &timeout) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("close not bounded by the shutdown deadline")
+		}
+	})
+
+	t.Run("final-timeout", func(t *testing.T) {
+		s := New(
+			WithErrorHandler(func(err error) { t.Errorf("unexpected error: %v", err) }),
+			WithFinalTimeout(10*time.Millisecond),
+		)
+		s.Finally(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		var errs []error
+		s := New(WithErrorHandler(CollectErrors(&errs)))
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		flushed := newCall(nil)
+		s.Finally(flushed.f)
+		if len(errs) != 1 || !errors.Is(errs[0], ErrClosed) {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+		if flushed.called() {
+			t.Fatal("unexpected call of the final function")
+		}
+	})
+}
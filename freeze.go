@@ -0,0 +1,40 @@
+package scope
+
+// Freeze pauses the scheduling of new tasks. Services and functions,
+// which are started while the scope is frozen, are registered but their
+// Start functions are not called before Unfreeze. Together with Snapshot
+// this allows to test the wiring of an application deterministically,
+// e.g. against a golden file. Tasks, which are still held when the scope
+// is closed, are never started.
+func (s *Scope) Freeze() {
+	s.mtx.Lock()
+	s.frozen = true
+	s.mtx.Unlock()
+}
+
+// Unfreeze resumes the scheduling of new tasks and starts all tasks,
+// which were held since the scope was frozen, in order of registration.
+func (s *Scope) Unfreeze() {
+	s.mtx.Lock()
+	held := s.held
+	s.frozen, s.held = false, nil
+	s.mtx.Unlock()
+
+	for _, t := range held {
+		if len(t.svc.DependsOn) > 0 {
+			s.launchAfterDependencies(t)
+		} else {
+			s.launch(t)
+		}
+	}
+}
+
+// skipHeldLocked marks all held tasks as skipped. It must be called
+// with the scope's mutex held.
+func (s *Scope) skipHeldLocked() {
+	for _, t := range s.held {
+		t.setState(skipped)
+		t.finish()
+	}
+	s.frozen, s.held = false, nil
+}
@@ -0,0 +1,56 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestScopeFreeze(t *testing.T) {
+	t.Run("snapshot", func(t *testing.T) {
+		started := newCall(nil)
+		s := newScope(t)
+		s.Freeze()
+		s.Start(Service{Name: "db", Start: started.f, Stop: nopFunc})
+		s.Go(nopFunc)
+		s.Defer(nopFunc)
+
+		expected := "[#0 db pending stop #1 pending #2 deferred stop]"
+		if got := fmt.Sprint(s.Snapshot()); got != expected {
+			t.Fatalf("unexpected snapshot: %s", got)
+		}
+		if started.called() {
+			t.Fatal("expected start function not to be called")
+		}
+
+		s.Unfreeze()
+		if err := s.WaitFor(context.Background(), "db"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !started.called() {
+			t.Fatal("expected start function to be called")
+		}
+	})
+
+	t.Run("close-frozen", func(t *testing.T) {
+		started := newCall(nil)
+		s := newScope(t)
+		s.Freeze()
+		s.Go(started.f)
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if started.called() {
+			t.Fatal("expected start function not to be called")
+		}
+		if got := fmt.Sprint(s.Snapshot()); got != "[#0 skipped]" {
+			t.Fatalf("unexpected snapshot: %s", got)
+		}
+	})
+}
+
+func nopFunc(context.Context) error { return nil }
@@ -0,0 +1,70 @@
+package scope
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// StartGroup starts the given services together like Start and waits
+// until all of them are ready (see Service). The context defines the
+// window, in which the services have to become ready. If one of the
+// services fails or the context is done before all of them are ready,
+// the group is rolled back: the services, which were started, are
+// stopped in reverse order like a canary (see StartCanary), so no half
+// initialized subsystem lingers. The returned error contains the reason
+// of the rollback and the errors of the stop functions.
+func (s *Scope) StartGroup(ctx context.Context, svcs ...Service) error {
+	s.mtx.Lock()
+	switch {
+	case isClosed(s.closing):
+		s.mtx.Unlock()
+		return fmt.Errorf("scope: cannot start group of a closing scope")
+	case s.frozen:
+		s.mtx.Unlock()
+		return fmt.Errorf("scope: cannot start group of a frozen scope")
+	}
+	s.mtx.Unlock()
+
+	var err error
+	tasks := make([]*task, 0, len(svcs))
+	for _, svc := range svcs {
+		t := &task{name: svc.Name, svc: svc, stop: svc.Stop, isolated: true}
+		s.capture(t)
+		tasks = append(tasks, t)
+		if !s.start(t, nil) {
+			err = fmt.Errorf("scope: service %q not started: %w", t, cmp.Or(t.err, ErrNotStarted))
+			break
+		}
+	}
+	if err == nil {
+		err = s.await(ctx, func() (bool, error) {
+			for _, t := range tasks {
+				if ok, err := t.readyLocked(); err != nil || !ok {
+					return false, err
+				}
+			}
+			return true, nil
+		})
+	}
+	if err == nil {
+		return nil
+	}
+
+	s.log(slog.LevelWarn, "group rolled back", "services", len(tasks), "error", err)
+	errs := Errors{fmt.Errorf("scope: group rolled back: %w", err)}
+	for i := len(tasks) - 1; i >= 0; i-- {
+		t := tasks[i]
+		s.mtx.Lock()
+		done := t.done
+		s.mtx.Unlock()
+		if done == nil {
+			// The service was never launched.
+			t.retired.Store(true)
+			continue
+		}
+		errs.append(s.rollback(context.WithoutCancel(ctx), t, done, err))
+	}
+	return errs.err()
+}
@@ -0,0 +1,106 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestScopeStartGroup(t *testing.T) {
+	var (
+		mtx   sync.Mutex
+		stops []string
+	)
+	service := func(name string, ready Func) Service {
+		return Service{
+			Name: name,
+			Start: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			Stop: func(context.Context) error {
+				mtx.Lock()
+				stops = append(stops, name)
+				mtx.Unlock()
+				return nil
+			},
+			Ready: ready,
+		}
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		stops = nil
+		s := newScope(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := s.StartGroup(ctx, service("a", nil), service("b", nil)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ready, _ := s.Ready(); !ready {
+			t.Fatal("expected scope to be ready")
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(stops, []string{"b", "a"}) {
+			t.Fatalf("unexpected stops: %v", stops)
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		stops = nil
+		s := newScope(t)
+
+		errReady := errors.New("ready error")
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		err := s.StartGroup(ctx,
+			service("a", nil),
+			service("b", nil),
+			service("c", func(context.Context) error { return errReady }),
+		)
+		if !errors.Is(err, errReady) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// The service, which is not ready, was started as well.
+		if !slices.Equal(stops, []string{"c", "b", "a"}) {
+			t.Fatalf("unexpected stops: %v", stops)
+		}
+
+		// Rolled back services are not stopped again.
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(stops) != 3 {
+			t.Fatalf("unexpected stops: %v", stops)
+		}
+	})
+
+	t.Run("window", func(t *testing.T) {
+		stops = nil
+		s := newScope(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := s.StartGroup(ctx,
+			service("a", nil),
+			service("b", func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			}),
+		)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(stops, []string{"b", "a"}) {
+			t.Fatalf("unexpected stops: %v", stops)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// ErrNotStarted is returned by Task.Err if the task's start function was
+// never called, because the scope was closed before (see WithLimit and
+// Scope.Freeze).
+var ErrNotStarted = errors.New("scope: task was never started")
+
+// Task is a handle of a task started by Go or Start. It allows to wait
+// for a single task without closing the scope.
+type Task task
+
+// Done returns a channel, which is closed when the task finished, i.e.
+// its start function returned and the task is not restarted anymore, or
+// the task will never be started.
+func (h *Task) Done() <-chan struct{} {
+	t := (*task)(h)
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if t.finishedCh == nil {
+		t.finishedCh = make(chan struct{})
+		if t.finished {
+			close(t.finishedCh)
+		}
+	}
+	return t.finishedCh
+}
+
+// Err returns the error of the task's start function after the task
+// finished (see Done). It returns nil if the task is still running or
+// succeeded, and ErrNotStarted if it was never started.
+func (h *Task) Err() error {
+	t := (*task)(h)
+	t.mtx.Lock()
+	finished := t.finished
+	t.mtx.Unlock()
+
+	switch {
+	case !finished:
+		return nil
+	case t.state.is(failed):
+		return t.err
+	case t.state.is(skipped):
+		return ErrNotStarted
+	default:
+		return nil
+	}
+}
+
+// Wait blocks until the task finished and returns its error (see Err).
+// If the context is done before, the context's error is returned.
+func (h *Task) Wait(ctx context.Context) error {
+	select {
+	case <-h.Done():
+		return h.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// finish marks the task as finished and wakes up all waiters (see
+// Task.Done). All calls of the error handler for the task have to
+// complete before.
+func (t *task) finish() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if !t.finished {
+		t.finished = true
+		if t.finishedCh != nil {
+			close(t.finishedCh)
+		}
+		t.closeSubsLocked()
+	}
+}
+
+// Wait blocks until the start functions of all tasks registered so far
+// and during the wait have returned, without calling any stop function
+// (see Task.Done). This supports launching a number of workers and
+// waiting for them without closing the scope. The errors of the failed
+// tasks are returned as Errors of type *TaskError; they are reported by
+// the error handler nevertheless. If the context is done before, the
+// context's error is returned.
+func (s *Scope) Wait(ctx context.Context) error {
+	var errs Errors
+	for waited := 0; ; {
+		// The tasks are searched by index, since finished tasks
+		// may have been pruned (see WithBoundedMemory).
+		s.mtx.Lock()
+		i, _ := slices.BinarySearchFunc(s.tasks, waited, func(t *task, idx int) int { return t.idx - idx })
+		tasks := s.tasks[i:]
+		s.mtx.Unlock()
+		if len(tasks) == 0 {
+			return errs.err()
+		}
+
+		for _, t := range tasks {
+			if t.deferred() {
+				continue
+			}
+			h := (*Task)(t)
+			select {
+			case <-h.Done():
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if err := h.Err(); err != nil && err != ErrNotStarted {
+				errs.append(&TaskError{Task: t.info(), Err: err})
+			}
+		}
+		waited = tasks[len(tasks)-1].idx + 1
+	}
+}
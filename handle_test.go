@@ -0,0 +1,224 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTask(t *testing.T) {
+	t.Run("succeeded", func(t *testing.T) {
+		s := newScope(t)
+		defer closeScope(s)
+
+		release := make(chan struct{})
+		task := s.Go(func(context.Context) error {
+			<-release
+			return nil
+		})
+
+		select {
+		case <-task.Done():
+			t.Fatal("expected task to be running")
+		default:
+		}
+		if err := task.Err(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		close(release)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := task.Wait(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		var errs []error
+		s := New(WithErrorHandler(CollectErrors(&errs)))
+
+		errStart := errors.New("start error")
+		task := s.Start(Service{
+			Name:  "svc",
+			Start: func(context.Context) error { return errStart },
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := task.Wait(ctx); err != errStart {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// The error handler was called before the task finished.
+		if len(errs) != 1 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("not-started", func(t *testing.T) {
+		s := newScope(t)
+		s.Freeze()
+		task := s.Go(func(context.Context) error { return nil })
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		<-task.Done()
+		if err := task.Err(); err != ErrNotStarted {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("context-done", func(t *testing.T) {
+		s := newScope(t)
+		task := s.Go(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := task.Wait(ctx); err != context.Canceled {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		<-task.Done()
+	})
+}
+
+func TestScopeWait(t *testing.T) {
+	t.Run("workers", func(t *testing.T) {
+		var errs []error
+		s := New(WithErrorHandler(CollectErrors(&errs)))
+
+		stop := newCall(nil)
+		s.Defer(stop.f)
+
+		var done [5]bool
+		errWorker := errors.New("worker error")
+		for i := range done {
+			s.Go(func(context.Context) error {
+				time.Sleep(time.Millisecond)
+				done[i] = true
+				if i == 3 {
+					return errWorker
+				}
+				return nil
+			})
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		err := s.Wait(ctx)
+		if !errors.Is(err, errWorker) || err.(Errors).Len() != 1 {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for i, ok := range done {
+			if !ok {
+				t.Fatalf("worker #%d not done", i)
+			}
+		}
+		if stop.called() {
+			t.Fatal("expected stop function not to be called")
+		}
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(errs) != 1 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+	})
+
+	t.Run("context-done", func(t *testing.T) {
+		s := newScope(t)
+		s.Go(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		if err := s.Wait(ctx); err != context.DeadlineExceeded {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestTaskSubscribe(t *testing.T) {
+	t.Run("transitions", func(t *testing.T) {
+		var errs []error
+		s := New(WithErrorHandler(CollectErrors(&errs)))
+		defer closeScope(s)
+
+		s.Freeze()
+		release := make(chan struct{})
+		task := s.Go(func(context.Context) error {
+			<-release
+			return errors.New("task error")
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		states := task.Subscribe(ctx)
+		if st := <-states; st != TaskPending {
+			t.Fatalf("unexpected state: %s", st)
+		}
+
+		s.Unfreeze()
+		if st := <-states; st != TaskRunning {
+			t.Fatalf("unexpected state: %s", st)
+		}
+		close(release)
+		var last TaskState
+		for st := range states {
+			last = st
+		}
+		if last != TaskFailed {
+			t.Fatalf("unexpected state: %s", last)
+		}
+		if ctx.Err() != nil {
+			t.Fatal("expected channel to be closed when the task finished")
+		}
+	})
+
+	t.Run("finished", func(t *testing.T) {
+		s := newScope(t)
+		task := s.Go(func(context.Context) error { return nil })
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		states := task.Subscribe(context.Background())
+		if st := <-states; st != TaskSucceeded {
+			t.Fatalf("unexpected state: %s", st)
+		}
+		if _, ok := <-states; ok {
+			t.Fatal("expected channel to be closed")
+		}
+	})
+
+	t.Run("context-done", func(t *testing.T) {
+		s := newScope(t)
+		defer closeScope(s)
+
+		task := s.Go(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		states := task.Subscribe(ctx)
+		cancel()
+		for range states {
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package scope
+
+import (
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// FatalOnError returns an error handler, which logs the error with the
+// given logger and exits the process with status 1. If the logger is
+// nil, slog.Default() is used.
+func FatalOnError(l *slog.Logger) func(error) {
+	log := LogErrors(l)
+	return func(err error) {
+		log(err)
+		os.Exit(1)
+	}
+}
+
+// LogErrors returns an error handler, which logs the error with the
+// given logger. If the logger is nil, slog.Default() is used.
+func LogErrors(l *slog.Logger) func(error) {
+	return func(err error) {
+		if l == nil {
+			slog.Default().Error("scope task failed", "error", err)
+		} else {
+			l.Error("scope task failed", "error", err)
+		}
+	}
+}
+
+// CollectErrors returns an error handler, which appends the errors to
+// the given slice. The handler is safe for concurrent use. Since all
+// tasks have completed when the scope is closed, the slice can be read
+// safely after Close returned.
+func CollectErrors(errs *[]error) func(error) {
+	var mtx sync.Mutex
+	return func(err error) {
+		mtx.Lock()
+		*errs = append(*errs, err)
+		mtx.Unlock()
+	}
+}
+
+// ErrorHandlers composes the given error handlers into a single one,
+// which calls them in the given order.
+func ErrorHandlers(handlers ...func(error)) func(error) {
+	return func(err error) {
+		for _, h := range handlers {
+			h(err)
+		}
+	}
+}
+
+// OverflowPolicy defines how an error stream treats errors, which do not
+// fit into the stream's buffer (see StreamErrors).
+type OverflowPolicy int
+
+const (
+	// DropNewest drops the error, which does not fit into the buffer.
+	DropNewest OverflowPolicy = iota
+	// DropOldest drops the oldest buffered error to make room for the
+	// new one.
+	DropOldest
+	// Block blocks the failed task until the error fits into the
+	// buffer. Since Close waits for all calls of the error handler,
+	// the stream must be consumed until the scope is closed.
+	Block
+)
+
+// StreamErrors returns an error handler, which sends the errors to the
+// given channel. This allows to consume the failures in an application's
+// own control loop, e.g. to aggregate, deduplicate, or rate-limit them.
+// The channel's buffer absorbs bursts of errors; errors exceeding it are
+// treated according to the policy. The handler can be composed with
+// other handlers (see ErrorHandlers). The channel is not closed by the
+// scope.
+//
+//	errs := make(chan error, 64)
+//	s := scope.New(scope.WithErrorHandler(scope.StreamErrors(errs, scope.DropOldest)))
+func StreamErrors(ch chan error, policy OverflowPolicy) func(error) {
+	var mtx sync.Mutex
+	return func(err error) {
+		switch policy {
+		case Block:
+			ch <- err
+		case DropOldest:
+			// The mutex ensures that the room made by
+			// dropping an error is not taken by another
+			// failed task.
+			mtx.Lock()
+			defer mtx.Unlock()
+			for {
+				select {
+				case ch <- err:
+					return
+				default:
+				}
+				select {
+				case <-ch:
+				default:
+				}
+			}
+		default:
+			select {
+			case ch <- err:
+			default:
+			}
+		}
+	}
+}
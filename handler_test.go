@@ -0,0 +1,139 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCollectErrors(t *testing.T) {
+	var errs []error
+	s := New(WithErrorHandler(CollectErrors(&errs)))
+	s.Go(func(context.Context) error { return errors.New("error 1") })
+	s.Go(func(context.Context) error { return errors.New("error 2") })
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("unexpected number of errors: %d", len(errs))
+	}
+}
+
+func TestErrorHandlerBeforeClose(t *testing.T) {
+	var handled atomic.Bool
+	s := New(WithErrorHandler(func(error) {
+		time.Sleep(20 * time.Millisecond)
+		handled.Store(true)
+	}))
+	s.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return errors.New("final error")
+	})
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !handled.Load() {
+		t.Fatal("expected error handler to complete before Close returns")
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	errs := []error{errors.New("error 1"), errors.New("error 2"), errors.New("error 3")}
+	stream := func(policy OverflowPolicy) []error {
+		ch := make(chan error, 2)
+		handle := StreamErrors(ch, policy)
+		for _, err := range errs {
+			handle(err)
+		}
+		close(ch)
+
+		var received []error
+		for err := range ch {
+			received = append(received, err)
+		}
+		return received
+	}
+
+	if received := stream(DropNewest); len(received) != 2 || received[0] != errs[0] || received[1] != errs[1] {
+		t.Fatalf("unexpected errors: %v", received)
+	}
+	if received := stream(DropOldest); len(received) != 2 || received[0] != errs[1] || received[1] != errs[2] {
+		t.Fatalf("unexpected errors: %v", received)
+	}
+
+	t.Run("block", func(t *testing.T) {
+		ch := make(chan error)
+		s := New(WithErrorHandler(StreamErrors(ch, Block)))
+		errTask := errors.New("task error")
+		s.Go(func(context.Context) error { return errTask })
+
+		select {
+		case err := <-ch:
+			if !errors.Is(err, errTask) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected error to be streamed")
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestServiceOnError(t *testing.T) {
+	var scopeErrs, svcErrs []error
+	s := New(WithErrorHandler(CollectErrors(&scopeErrs)))
+
+	errStart := errors.New("start error")
+	s.Start(Service{
+		Start:   func(context.Context) error { return errStart },
+		OnError: CollectErrors(&svcErrs),
+	})
+	s.Go(func(context.Context) error { return errStart })
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(svcErrs) != 1 || len(scopeErrs) != 1 {
+		t.Fatalf("unexpected errors: %v, %v", svcErrs, scopeErrs)
+	}
+}
+
+func TestWithExpectedErrors(t *testing.T) {
+	errExpected := errors.New("expected error")
+	s := New(
+		WithErrorHandler(func(err error) { t.Errorf("unexpected error: %v", err) }),
+		WithExpectedErrors(context.Canceled, errExpected),
+	)
+
+	stop := newCall(nil)
+	task := s.Start(Service{
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		Stop: stop.f,
+	})
+	worker := s.Go(func(context.Context) error { return fmt.Errorf("wrapped: %w", errExpected) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := worker.Wait(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := task.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !stop.called() {
+		t.Fatal("expected stop function to be called")
+	}
+}
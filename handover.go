@@ -0,0 +1,43 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Handover hands the work of the old scope over to the next one, e.g. for
+// an in-process blue/green reload of a configuration. It waits until all
+// services of the next scope are ready (see WaitReady), calls cutover to
+// switch the traffic to the next scope (e.g. by swapping a router), and
+// closes the old scope with the cause HandedOver afterwards.
+//
+// If the next scope does not become ready or the cutover fails, the next
+// scope is closed and the old one keeps running. The returned error then
+// combines the error of the failed step and the error of closing the next
+// scope. Otherwise the error of closing the old scope is returned. The
+// context bounds the handover including the shutdown of the old scope.
+// The next scope is closed regardless of the context on a rollback, since
+// the context may have ended the handover (see WithShutdownTimeout).
+func Handover(ctx context.Context, old, next *Scope, cutover func(context.Context) error) error {
+	if err := next.WaitReady(ctx); err != nil {
+		return rollbackHandover(ctx, next, fmt.Errorf("scope: next scope not ready: %w", err))
+	}
+	if err := protect(ctx, cutover); err != nil {
+		return rollbackHandover(ctx, next, fmt.Errorf("scope: cutover failed: %w", err))
+	}
+
+	old.log(slog.LevelInfo, "scope handed over", "to", next.id)
+	old.setCloseCause(HandedOver{})
+	return old.CloseContext(ctx)
+}
+
+// rollbackHandover closes the next scope of a failed handover.
+func rollbackHandover(ctx context.Context, next *Scope, err error) error {
+	next.log(slog.LevelWarn, "handover failed", "error", err)
+
+	var errs Errors
+	errs.append(err)
+	errs.append(next.CloseContext(context.WithoutCancel(ctx)))
+	return errs.err()
+}
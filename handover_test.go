@@ -0,0 +1,97 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHandover(t *testing.T) {
+	service := func(ready Func) Service {
+		return Service{
+			Name: "api",
+			Start: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			Ready: ready,
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		old, next := newScope(t), newScope(t)
+		old.Start(service(nil))
+		next.Start(service(nil))
+
+		var active atomic.Pointer[Scope]
+		active.Store(old)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		err := Handover(ctx, old, next, func(context.Context) error {
+			active.Store(next)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if active.Load() != next {
+			t.Fatal("expected cutover to the next scope")
+		}
+		if c := Cause(old.Ctx()); c != (HandedOver{}) {
+			t.Fatalf("unexpected cause: %v", c)
+		}
+		if next.IsClosed() {
+			t.Fatal("expected next scope to be open")
+		}
+		if err := closeScope(next); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("not-ready", func(t *testing.T) {
+		old, next := newScope(t), newScope(t)
+		old.Start(service(nil))
+		next.Start(service(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}))
+
+		cutover := newCall(nil)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := Handover(ctx, old, next, cutover.f); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cutover.called() {
+			t.Fatal("expected cutover not to be called")
+		}
+		if old.IsClosed() || !next.IsClosed() {
+			t.Fatal("expected next scope to be closed")
+		}
+		if err := closeScope(old); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("cutover-failed", func(t *testing.T) {
+		old, next := newScope(t), newScope(t)
+		old.Start(service(nil))
+		next.Start(service(nil))
+
+		errCutover := errors.New("cutover error")
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		err := Handover(ctx, old, next, func(context.Context) error { return errCutover })
+		if !errors.Is(err, errCutover) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if old.IsClosed() || !next.IsClosed() {
+			t.Fatal("expected next scope to be closed")
+		}
+		if err := closeScope(old); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package scope
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultProbeTimeout bounds a health check by default (see
+// WithProbeTimeout).
+const defaultProbeTimeout = 5 * time.Second
+
+// HealthReport describes the health of the services of a scope (see
+// Scope.Health).
+type HealthReport struct {
+	Live     bool            `json:"live"`  // whether all services are alive
+	Ready    bool            `json:"ready"` // whether the scope is open and all services are ready
+	Services []ServiceHealth `json:"services"`
+}
+
+// ServiceHealth describes the health of a single service.
+type ServiceHealth struct {
+	Name  string `json:"name"`
+	State string `json:"state"` // state of the task (see TaskSnapshot), or "restarting"
+	Live  bool   `json:"live"`
+	Ready bool   `json:"ready"`
+	Err   string `json:"error,omitempty"` // error of the failed service or health check
+}
+
+// Health checks the health of the named services of the scope. A service
+// is alive unless it failed for good or its health check failed (see
+// Service). A service is ready when it reported ready (see WaitFor), is
+// running, and its health check succeeded. Services, which are waiting
+// for a restart (see Service.Restart), are alive, but not ready. Paused
+// services are alive, but not ready. The health checks are only called
+// for running services.
+//
+// Each health check is called with a context, which is derived from the
+// given context and bounded by the probe timeout (see WithProbeTimeout).
+// If the given context belongs to a scope, e.g. a task's context, its
+// cancellation is ignored, so the health checks keep working while the
+// scope is draining or closing.
+func (s *Scope) Health(ctx context.Context) HealthReport {
+	s.mtx.Lock()
+	closing := isClosed(s.closing)
+	var tasks []*task
+	var health []ServiceHealth
+	for _, t := range s.tasks {
+		if t.name == "" || t.deferred() {
+			continue
+		}
+		h := ServiceHealth{Name: t.name, State: t.state.String(), Live: true, Ready: t.ready}
+		if t.quarantine != "" && t.state.is(paused) {
+			h.State = "quarantined"
+		}
+		tasks = append(tasks, t)
+		health = append(health, h)
+	}
+	s.mtx.Unlock()
+
+	report := HealthReport{Live: true, Ready: !closing}
+	for i, t := range tasks {
+		h := health[i]
+		switch {
+		case t.restarting.Load():
+			h.State, h.Ready = "restarting", false
+		case t.state.is(failed):
+			h.Live, h.Ready, h.Err = false, false, t.err.Error()
+		case !t.state.is(running):
+			h.Ready = false
+		case t.svc.Health != nil:
+			if err := s.probe(ctx, t); err != nil {
+				h.Live, h.Ready, h.Err = false, false, err.Error()
+			}
+		}
+		report.Live = report.Live && h.Live
+		report.Ready = report.Ready && h.Ready
+		report.Services = append(report.Services, h)
+	}
+	return report
+}
+
+// probe calls the health check of the task with a probe context, which is
+// not cancelled with the scope's context (see Health).
+func (s *Scope) probe(ctx context.Context, t *task) error {
+	if ctx.Value(closingKey{}) != nil {
+		// The context belongs to a scope, which may be
+		// closing the very services we are probing.
+		ctx = context.WithoutCancel(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.opts.probeTimeout)
+	defer cancel()
+	return protect(t.context(ctx), t.svc.Health)
+}
+
+// HealthHandler returns an HTTP handler, which reports the health of the
+// scope's services as JSON (see Health). Requests for a path ending in
+// "/livez" are answered according to the liveness, all other requests
+// according to the readiness. The handler responds with 200 OK if the
+// probe succeeds, and with 503 Service Unavailable otherwise. This allows
+// to use the handler for Kubernetes probes:
+//
+//	h := s.HealthHandler()
+//	mux.Handle("/livez", h)
+//	mux.Handle("/readyz", h)
+func (s *Scope) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := s.Health(r.Context())
+		ok := report.Ready
+		if strings.HasSuffix(r.URL.Path, "/livez") {
+			ok = report.Live
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if !ok {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	})
+}
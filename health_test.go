@@ -0,0 +1,131 @@
+package scope
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScopeHealth(t *testing.T) {
+	s := New(WithErrorHandler(func(error) {}))
+
+	var unhealthy atomic.Bool
+	s.Start(Service{
+		Name: "db",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Health: func(context.Context) error {
+			if unhealthy.Load() {
+				return errors.New("connection lost")
+			}
+			return nil
+		},
+	})
+	cache := s.Start(Service{
+		Name:    "cache",
+		Start:   func(context.Context) error { return errors.New("start error") },
+		Restart: RestartOnFailure,
+		Backoff: Backoff{Initial: time.Hour},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.WaitFor(ctx, "db"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for !(*task)(cache).restarting.Load() {
+		time.Sleep(time.Millisecond)
+	}
+
+	h := s.HealthHandler()
+	probe := func(path string) (int, HealthReport) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var report HealthReport
+		if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return rec.Code, report
+	}
+
+	// The restarting service is alive, but not ready.
+	code, report := probe("/readyz")
+	if code != http.StatusServiceUnavailable || report.Ready || !report.Live {
+		t.Fatalf("unexpected readiness: %d %+v", code, report)
+	}
+	if len(report.Services) != 2 || report.Services[1].State != "restarting" || !report.Services[0].Ready {
+		t.Fatalf("unexpected services: %+v", report.Services)
+	}
+	if code, _ := probe("/livez"); code != http.StatusOK {
+		t.Fatalf("unexpected liveness: %d", code)
+	}
+
+	unhealthy.Store(true)
+	code, report = probe("/livez")
+	if code != http.StatusServiceUnavailable || report.Services[0].Err != "connection lost" {
+		t.Fatalf("unexpected liveness: %d %+v", code, report)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report := s.Health(context.Background()); report.Ready {
+		t.Fatalf("expected closed scope not to be ready: %+v", report)
+	}
+}
+
+func TestScopeHealthProbeContext(t *testing.T) {
+	s := New(WithProbeTimeout(20 * time.Millisecond))
+
+	var slow atomic.Bool
+	probed := make(chan error, 1)
+	s.Start(Service{
+		Name: "db",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Health: func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); !ok {
+				t.Error("probe context without deadline")
+			}
+			if slow.Load() {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			probed <- ctx.Err()
+			return nil
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.WaitFor(ctx, "db"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The probes are not wedged by the cancellation of a scope.
+	other := New()
+	other.Close()
+	if report := s.Health(other.Ctx()); !report.Live {
+		t.Fatalf("unexpected health: %+v", report)
+	}
+	if err := <-probed; err != nil {
+		t.Fatalf("unexpected probe context error: %v", err)
+	}
+
+	slow.Store(true)
+	if report := s.Health(context.Background()); report.Live || report.Services[0].Err != context.DeadlineExceeded.Error() {
+		t.Fatalf("unexpected health: %+v", report)
+	}
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
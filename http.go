@@ -0,0 +1,164 @@
+package scope
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// The stages of the stop function of an HTTP server (see HTTPServer).
+const (
+	StageRefuse = "refuse" // disabling keep-alives and closing the listener
+	StageDrain  = "drain"  // waiting for the in-flight requests
+	StageClose  = "close"  // closing the remaining connections forcibly
+)
+
+type httpOptions struct {
+	drainTimeout time.Duration
+}
+
+// HTTPOption represents an option which can be used to configure an HTTP
+// server service (see HTTPServer).
+type HTTPOption func(*httpOptions)
+
+// DrainTimeout limits the time the in-flight requests may take when the
+// server is stopped. The remaining connections are closed forcibly
+// afterwards. By default, the requests are drained until the context of
+// the stop function is done.
+func DrainTimeout(d time.Duration) HTTPOption {
+	return func(o *httpOptions) {
+		if d <= 0 {
+			panic("scope options: invalid drain timeout")
+		}
+		o.drainTimeout = d
+	}
+}
+
+// HTTPServer returns a service, which runs the servers created by the
+// given function. Each run of the service, e.g. after a restart (see
+// Scope.RollingRestart), creates a new server, since a server cannot
+// serve again once it was shut down. The start function listens on the
+// server's address and serves the requests. The service is ready as soon
+// as the server listens. If the server has a TLS configuration with
+// certificates, TLS is served.
+//
+// The stop function stops the server in three stages: it disables
+// keep-alives and closes the listener, so no new connections are
+// accepted (StageRefuse), drains the in-flight requests (StageDrain, see
+// http.Server.Shutdown and DrainTimeout), and closes the remaining
+// connections forcibly if the requests were not drained in time
+// (StageClose). In the latter case, the error of the drain is returned.
+// The duration of each stage is part of the shutdown report (see
+// TaskReport). http.ErrServerClosed is not reported as an error.
+//
+// The returned service has no name, which can be set before the service
+// is started:
+//
+//	svc := scope.HTTPServer(func() *http.Server {
+//		return &http.Server{Addr: ":8080", Handler: mux}
+//	})
+//	svc.Name = "api"
+//	s.Start(svc)
+func HTTPServer(newServer func() *http.Server, opts ...HTTPOption) Service {
+	if newServer == nil {
+		panic("scope: nil server function")
+	}
+	var o httpOptions
+	for _, apply := range opts {
+		apply(&o)
+	}
+
+	var (
+		mtx       sync.Mutex
+		srv       *http.Server          // server of the current run
+		listener  net.Listener          // listener of the current run
+		stopping  bool                  // whether the current run is stopped
+		listening = make(chan struct{}) // closed when the current run listens
+	)
+	return Service{
+		Start: func(context.Context) error {
+			mtx.Lock()
+			cur, ready := newServer(), listening
+			srv, listener, stopping = cur, nil, false
+			mtx.Unlock()
+			defer func() {
+				// The next run gets its own channel.
+				mtx.Lock()
+				listening = make(chan struct{})
+				mtx.Unlock()
+			}()
+
+			ln, err := net.Listen("tcp", cmp.Or(cur.Addr, ":http"))
+			if err != nil {
+				return err
+			}
+			mtx.Lock()
+			listener = ln
+			mtx.Unlock()
+			close(ready)
+
+			if tc := cur.TLSConfig; tc != nil && (len(tc.Certificates) > 0 || tc.GetCertificate != nil) {
+				err = cur.ServeTLS(ln, "", "")
+			} else {
+				err = cur.Serve(ln)
+			}
+
+			mtx.Lock()
+			defer mtx.Unlock()
+			// The listener is closed by the stop function
+			// before the server is shut down.
+			if errors.Is(err, http.ErrServerClosed) || (stopping && errors.Is(err, net.ErrClosed)) {
+				return nil
+			}
+			return err
+		},
+		Stop: func(ctx context.Context) error {
+			end := StopStage(ctx, StageRefuse)
+			mtx.Lock()
+			cur := srv
+			stopping = true
+			if listener != nil {
+				listener.Close()
+			}
+			mtx.Unlock()
+			if cur == nil {
+				end()
+				return nil
+			}
+			cur.SetKeepAlivesEnabled(false)
+			end()
+
+			drainCtx := ctx
+			if d := o.drainTimeout; d > 0 {
+				var cancel context.CancelFunc
+				drainCtx, cancel = context.WithTimeout(ctx, d)
+				defer cancel()
+			}
+			end = StopStage(ctx, StageDrain)
+			err := cur.Shutdown(drainCtx)
+			end()
+			if err != nil {
+				end = StopStage(ctx, StageClose)
+				cur.Close()
+				end()
+				return err
+			}
+			return nil
+		},
+		Ready: func(ctx context.Context) error {
+			mtx.Lock()
+			ready := listening
+			mtx.Unlock()
+			select {
+			case <-ready:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
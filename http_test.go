@@ -0,0 +1,174 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHTTPServer(t *testing.T) {
+	t.Run("graceful", func(t *testing.T) {
+		s := newScope(t)
+
+		addr := make(chan string, 1)
+		served := make(chan struct{})
+		srv := &http.Server{
+			Addr: "127.0.0.1:0",
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(served)
+				time.Sleep(10 * time.Millisecond)
+				io.WriteString(w, "ok")
+			}),
+			BaseContext: func(ln net.Listener) context.Context {
+				addr <- ln.Addr().String()
+				return context.Background()
+			},
+		}
+		svc := HTTPServer(func() *http.Server { return srv })
+		svc.Name = "http"
+		s.Start(svc)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := s.WaitFor(ctx, "http"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		body := make(chan string, 1)
+		go func() {
+			resp, err := http.Get("http://" + <-addr)
+			if err != nil {
+				body <- err.Error()
+				return
+			}
+			defer resp.Body.Close()
+			b, _ := io.ReadAll(resp.Body)
+			body <- string(b)
+		}()
+
+		// The in-flight request is finished before
+		// the server is stopped.
+		<-served
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if b := <-body; b != "ok" {
+			t.Fatalf("unexpected response: %s", b)
+		}
+	})
+
+	t.Run("restart", func(t *testing.T) {
+		s := newScope(t)
+
+		addrs := make(chan string, 2)
+		svc := HTTPServer(func() *http.Server {
+			return &http.Server{
+				Addr: "127.0.0.1:0",
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					io.WriteString(w, "ok")
+				}),
+				BaseContext: func(ln net.Listener) context.Context {
+					addrs <- ln.Addr().String()
+					return context.Background()
+				},
+			}
+		})
+		svc.Name = "http"
+		s.Start(svc)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := s.WaitFor(ctx, "http"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		<-addrs
+		if err := s.RollingRestart(ctx, []string{"http"}, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// The restarted service serves with a new server.
+		resp, err := http.Get("http://" + <-addrs)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		b, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(b) != "ok" {
+			t.Fatalf("unexpected response: %s", b)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("listen-error", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer ln.Close()
+
+		var errs []error
+		s := New(WithErrorHandler(CollectErrors(&errs)))
+		task := s.Start(HTTPServer(func() *http.Server {
+			return &http.Server{Addr: ln.Addr().String()}
+		}))
+		<-task.Done()
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(errs) != 1 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+	})
+}
+
+func TestHTTPServerStages(t *testing.T) {
+	s := newScope(t)
+
+	addr := make(chan string, 1)
+	served := make(chan struct{})
+	release := make(chan struct{})
+	srv := &http.Server{
+		Addr: "127.0.0.1:0",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(served)
+			<-release
+		}),
+		BaseContext: func(ln net.Listener) context.Context {
+			addr <- ln.Addr().String()
+			return context.Background()
+		},
+	}
+	defer close(release)
+	svc := HTTPServer(func() *http.Server { return srv }, DrainTimeout(10*time.Millisecond))
+	svc.Name = "http"
+	s.Start(svc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.WaitFor(ctx, "http"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	go http.Get("http://" + <-addr)
+
+	// The hanging request is not drained in time, so the
+	// connection is closed forcibly.
+	<-served
+	if err := closeScope(s); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report, _ := s.ShutdownReport()
+	stages := report.Tasks[0].Stages
+	if len(stages) != 3 || stages[0].Name != StageRefuse || stages[1].Name != StageDrain || stages[2].Name != StageClose {
+		t.Fatalf("unexpected stages: %+v", stages)
+	}
+	if stages[1].Duration < 10*time.Millisecond {
+		t.Fatalf("unexpected drain duration: %v", stages[1].Duration)
+	}
+}
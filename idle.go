@@ -0,0 +1,58 @@
+package scope
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// awaitIdle waits until the task has no item in flight, so its stop
+// function does not cut an item off (see Service.Idle). Tasks, whose
+// start function returned already, have no item in flight. The wait
+// takes at most the task's stop timeout and half of the time left until
+// the deadline of the context, so the stop function is not called with
+// an expired context. If the wait ends before the task is idle, the
+// task is stopped regardless.
+func (s *Scope) awaitIdle(ctx context.Context, t *task) {
+	s.mtx.Lock()
+	done := t.done
+	s.mtx.Unlock()
+	if done == nil || isClosed(done) || t.restarting.Load() {
+		return
+	}
+
+	var idle <-chan struct{}
+	err := protect(ctx, func(context.Context) error {
+		idle = t.svc.Idle()
+		return nil
+	})
+	if err != nil {
+		s.log(slog.LevelError, "idle function failed", "task", t, "caller", (*lazyCaller)(t), "error", err)
+		s.handlePanic(err)
+		return
+	}
+	select {
+	case <-idle:
+		return
+	default:
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Until(deadline)/2)
+		defer cancel()
+	}
+	if d := t.opts.stopTimeout; d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	s.log(slog.LevelDebug, "waiting for idle task", "task", t)
+	select {
+	case <-idle:
+	case <-done:
+	case <-ctx.Done():
+		s.log(slog.LevelWarn, "task not idle before stop", "task", t, "error", context.Cause(ctx))
+	}
+}
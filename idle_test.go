@@ -0,0 +1,154 @@
+package scope
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestServiceIdle(t *testing.T) {
+	t.Run("idle", func(t *testing.T) {
+		var inFlight atomic.Bool
+		inFlight.Store(true)
+		idle := make(chan struct{})
+		s := newScope(t)
+		s.Start(Service{
+			Start: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			Stop: func(context.Context) error {
+				if inFlight.Load() {
+					t.Error("stop called with an item in flight")
+				}
+				return nil
+			},
+			Idle: func() <-chan struct{} { return idle },
+		})
+
+		closed := make(chan error, 1)
+		go func() { closed <- closeScope(s) }()
+		select {
+		case err := <-closed:
+			t.Fatalf("unexpected close: %v", err)
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		inFlight.Store(false)
+		close(idle)
+		if err := <-closed; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		var (
+			warnings []Warning
+			stopErr  error
+		)
+		stopped := newCall(func(ctx context.Context) error {
+			stopErr = ctx.Err()
+			return nil
+		})
+		s := New(
+			WithErrorHandler(func(err error) { t.Fatalf("unexpected error: %v", err) }),
+			WithCancelLatencyThreshold(5*time.Millisecond),
+			WithWarningHandler(func(w Warning) { warnings = append(warnings, w) }),
+		)
+		s.Start(Service{
+			Start: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			Stop: stopped.f,
+			Idle: func() <-chan struct{} { return nil },
+		}, StopTimeout(10*time.Millisecond))
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		switch {
+		case !stopped.called():
+			t.Fatal("stop function not called")
+		case stopErr != nil:
+			t.Fatalf("stop function called with a done context: %v", stopErr)
+		case len(warnings) != 0:
+			t.Fatalf("unexpected warnings: %v", warnings)
+		}
+	})
+
+	t.Run("shutdown-deadline", func(t *testing.T) {
+		var stopErr error
+		stopped := newCall(func(ctx context.Context) error {
+			stopErr = ctx.Err()
+			return nil
+		})
+		s := newScope(t)
+		s.Start(Service{
+			Start: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			Stop: stopped.f,
+			Idle: func() <-chan struct{} { return nil },
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if err := s.CloseContext(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		switch {
+		case !stopped.called():
+			t.Fatal("stop function not called")
+		case stopErr != nil:
+			t.Fatalf("stop function called with a done context: %v", stopErr)
+		}
+	})
+
+	t.Run("returned", func(t *testing.T) {
+		stopped := newCall(nil)
+		var idleCalled atomic.Bool
+		s := newScope(t)
+		task := s.Start(Service{
+			Start: func(context.Context) error { return nil },
+			Stop:  stopped.f,
+			Idle: func() <-chan struct{} {
+				idleCalled.Store(true)
+				return nil
+			},
+		})
+		<-task.Done()
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		switch {
+		case !stopped.called():
+			t.Fatal("stop function not called")
+		case idleCalled.Load():
+			t.Fatal("unexpected idle call")
+		}
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		stopped := newCall(nil)
+		s := newScope(t)
+		s.Start(Service{
+			Start: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			Stop: stopped.f,
+			Idle: func() <-chan struct{} { panic("idle") },
+		})
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !stopped.called() {
+			t.Fatal("stop function not called")
+		}
+	})
+}
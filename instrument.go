@@ -0,0 +1,133 @@
+package scope
+
+import (
+	"context"
+	"strconv"
+)
+
+// TaskInfo describes a task of a scope.
+type TaskInfo struct {
+	Index   int          // position in registration order
+	Scope   string       // ID of the scope, which registered the task, unique within the process
+	Name    string       // name of the service (see Service)
+	Value   any          // value of a cleanup function registered with DeferVal
+	Regions []RegionInfo // regions of the task (see Scope.Region)
+	Caller  string       // file and line, where the task was registered
+	Stack   []string     // stack of the registration (see WithCallerStacks)
+}
+
+// Instrumentor is the interface, which can be implemented to observe the
+// lifecycle of a scope and its tasks (see WithInstrumentor). It allows
+// to integrate logging, tracing, and metrics systems without the scope
+// depending on any of them.
+//
+// TaskStarted and StopStarted may return a derived context, which is
+// passed to the task's start and stop function respectively, as well
+// as to the corresponding TaskEnded and StopEnded calls. All methods
+// must be safe for concurrent use.
+type Instrumentor interface {
+	// TaskStarted is called before the task's start function is called.
+	TaskStarted(ctx context.Context, t TaskInfo) context.Context
+	// TaskEnded is called after the task's start function returned.
+	TaskEnded(ctx context.Context, t TaskInfo, err error)
+	// StopStarted is called before the task's stop function is called.
+	StopStarted(ctx context.Context, t TaskInfo) context.Context
+	// StopEnded is called after the task's stop function returned.
+	StopEnded(ctx context.Context, t TaskInfo, err error)
+	// CloseEnded is called when the scope was closed.
+	CloseEnded(err error)
+}
+
+// String returns the name of the task or its index if it has no name.
+func (t TaskInfo) String() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return "#" + strconv.Itoa(t.Index)
+}
+
+// RegionInstrumentor can be implemented additionally by an Instrumentor
+// to observe the regions of tasks (see Scope.Region).
+type RegionInstrumentor interface {
+	// RegionStarted is called when a region begins.
+	RegionStarted(ctx context.Context, t TaskInfo, name string) context.Context
+	// RegionEnded is called when a region ends.
+	RegionEnded(ctx context.Context, t TaskInfo, r RegionInfo)
+}
+
+// NopInstrumentor is an Instrumentor, which does nothing. It can be
+// embedded to implement only a subset of the methods.
+type NopInstrumentor struct{}
+
+func (NopInstrumentor) TaskStarted(ctx context.Context, _ TaskInfo) context.Context { return ctx }
+func (NopInstrumentor) TaskEnded(context.Context, TaskInfo, error)                  {}
+func (NopInstrumentor) StopStarted(ctx context.Context, _ TaskInfo) context.Context { return ctx }
+func (NopInstrumentor) StopEnded(context.Context, TaskInfo, error)                  {}
+func (NopInstrumentor) CloseEnded(error)                                            {}
+
+type instrumentors []Instrumentor
+
+func (is instrumentors) taskStarted(ctx context.Context, t *task) context.Context {
+	if len(is) == 0 {
+		return ctx
+	}
+	info := t.info()
+	for _, i := range is {
+		ctx = i.TaskStarted(ctx, info)
+	}
+	return ctx
+}
+
+func (is instrumentors) taskEnded(ctx context.Context, t *task, err error) {
+	if len(is) == 0 {
+		return
+	}
+	info := t.info()
+	for _, i := range is {
+		i.TaskEnded(ctx, info, err)
+	}
+}
+
+func (is instrumentors) stopStarted(ctx context.Context, t *task) context.Context {
+	if len(is) == 0 {
+		return ctx
+	}
+	info := t.info()
+	for _, i := range is {
+		ctx = i.StopStarted(ctx, info)
+	}
+	return ctx
+}
+
+func (is instrumentors) stopEnded(ctx context.Context, t *task, err error) {
+	if len(is) == 0 {
+		return
+	}
+	info := t.info()
+	for _, i := range is {
+		i.StopEnded(ctx, info, err)
+	}
+}
+
+func (is instrumentors) closeEnded(err error) {
+	for _, i := range is {
+		i.CloseEnded(err)
+	}
+}
+
+func (is instrumentors) regionStarted(ctx context.Context, t TaskInfo, name string) context.Context {
+	for _, i := range is {
+		if ri, ok := i.(RegionInstrumentor); ok {
+			ctx = ri.RegionStarted(ctx, t, name)
+		}
+	}
+	return ctx
+}
+
+func (is instrumentors) regionEnded(ctx context.Context, t TaskInfo, r RegionInfo) {
+	for _, i := range is {
+		if ri, ok := i.(RegionInstrumentor); ok {
+			ri.RegionEnded(ctx, t, r)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestScopeInstrumentor(t *testing.T) {
+	var rec recordingInstrumentor
+	s := New(
+		WithErrorHandler(func(err error) { t.Fatalf("unexpected error: %v", err) }),
+		WithInstrumentor(&rec),
+	)
+
+	started := make(chan struct{})
+	s.Start(Service{
+		Start: func(context.Context) error {
+			close(started)
+			return nil
+		},
+		Stop: func(context.Context) error { return nil },
+	})
+	<-started
+	waitStats(t, s, Stats{PeakRunning: 1})
+	s.Defer(func(context.Context) error { return nil })
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"task-started 0",
+		"task-ended 0 <nil>",
+		"stop-started 1",
+		"stop-ended 1 <nil>",
+		"stop-started 0",
+		"stop-ended 0 <nil>",
+		"close-ended <nil>",
+	}
+	if !reflect.DeepEqual(rec.events, expected) {
+		t.Fatalf("unexpected events: %q", rec.events)
+	}
+}
+
+type recordingInstrumentor struct {
+	mtx    sync.Mutex
+	events []string
+}
+
+func (r *recordingInstrumentor) record(format string, args ...any) {
+	r.mtx.Lock()
+	r.events = append(r.events, fmt.Sprintf(format, args...))
+	r.mtx.Unlock()
+}
+
+func (r *recordingInstrumentor) TaskStarted(ctx context.Context, t TaskInfo) context.Context {
+	r.record("task-started %d", t.Index)
+	return ctx
+}
+
+func (r *recordingInstrumentor) TaskEnded(_ context.Context, t TaskInfo, err error) {
+	r.record("task-ended %d %v", t.Index, err)
+}
+
+func (r *recordingInstrumentor) StopStarted(ctx context.Context, t TaskInfo) context.Context {
+	r.record("stop-started %d", t.Index)
+	return ctx
+}
+
+func (r *recordingInstrumentor) StopEnded(_ context.Context, t TaskInfo, err error) {
+	r.record("stop-ended %d %v", t.Index, err)
+}
+
+func (r *recordingInstrumentor) CloseEnded(err error) {
+	r.record("close-ended %v", err)
+}
+
+func TestTaskInfoScope(t *testing.T) {
+	var (
+		mtx  sync.Mutex
+		keys = make(map[string]bool)
+	)
+	inst := &scopeInstrumentor{started: func(info TaskInfo) {
+		mtx.Lock()
+		keys[fmt.Sprintf("%s/%d", info.Scope, info.Index)] = true
+		mtx.Unlock()
+	}}
+	s := New(
+		WithErrorHandler(func(err error) { t.Fatalf("unexpected error: %v", err) }),
+		WithInstrumentor(inst),
+	)
+	child := s.Child()
+	for _, s := range []*Scope{s, child} {
+		if err := s.Go(func(context.Context) error { return nil }).Wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("unexpected task keys: %v", keys)
+	}
+}
+
+type scopeInstrumentor struct {
+	NopInstrumentor
+	started func(TaskInfo)
+}
+
+func (i *scopeInstrumentor) TaskStarted(ctx context.Context, t TaskInfo) context.Context {
+	i.started(t)
+	return ctx
+}
@@ -0,0 +1,45 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Interface is the minimal set of methods of a scope. Libraries can
+// accept an Interface instead of a *Scope to be tested with a fake scope,
+// which does not run any Goroutines (see scopetest.Fake). The set of
+// methods is stable, i.e. no methods will be added to Interface.
+type Interface interface {
+	Ctx() context.Context
+	Go(f Func, opts ...StartOption) *Task
+	Defer(f Func)
+	Start(svc Service, opts ...StartOption) *Task
+	Close() error
+}
+
+var _ Interface = (*Scope)(nil)
+
+// NewTask returns a task, which is not bound to a scope, and a function to
+// finish it with the given error. Finishing it with ErrNotStarted marks
+// the task as never started. Subsequent calls of the finish function have
+// no effect. NewTask allows to implement fakes of Interface.
+func NewTask() (*Task, func(error)) {
+	t := &task{}
+	t.setState(pending)
+	var once sync.Once
+	return (*Task)(t), func(err error) {
+		once.Do(func() {
+			switch {
+			case errors.Is(err, ErrNotStarted):
+				t.setState(skipped)
+			case err != nil:
+				t.err = err
+				t.setState(failed)
+			default:
+				t.setState(succeeded)
+			}
+			t.finish()
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package scope
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewTask(t *testing.T) {
+	errTask := errors.New("task failed")
+	tests := map[string]struct {
+		err  error
+		want error
+	}{
+		"succeeded":   {err: nil, want: nil},
+		"failed":      {err: errTask, want: errTask},
+		"not-started": {err: ErrNotStarted, want: ErrNotStarted},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			task, finish := NewTask()
+			select {
+			case <-task.Done():
+				t.Fatal("unexpected finished task")
+			default:
+			}
+
+			finish(test.err)
+			finish(errors.New("ignored"))
+			<-task.Done()
+			if err := task.Err(); err != test.want {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
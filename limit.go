@@ -0,0 +1,350 @@
+package scope
+
+import (
+	"sync"
+	"time"
+)
+
+const autoLimitFactor = 4
+
+// Stats holds statistics of a scope (see Scope.Stats).
+type Stats struct {
+	Limit       int // maximum number of concurrently running start functions (0 if unlimited, see WithAdaptiveLimit)
+	Running     int // number of currently running start functions
+	PeakRunning int // maximum number of concurrently running start functions so far
+	Pending     int // number of start functions waiting for a free slot
+	Leaked      int // number of start functions still running after the scope's context was cancelled
+
+	// WallTime is the total run time of all start functions. It is only
+	// measured with WithTaskAccounting.
+	WallTime time.Duration
+
+	// Groups holds the statistics of the task groups, which share the
+	// concurrency limit (see Group). Groups without active or pending
+	// start functions are omitted. It is nil if the concurrency is
+	// unlimited.
+	Groups map[string]GroupStats
+
+	// Work holds the pending work of the scope's pools and consumers
+	// keyed by their kind (see WorkPool and WorkConsumer). Kinds without
+	// pending work are omitted, i.e. it is nil if the scope is idle.
+	Work map[string]WorkStats
+}
+
+// GroupStats holds statistics of a task group (see Group).
+type GroupStats struct {
+	Weight  int // share of the concurrency limit (see WithGroupWeight)
+	Active  int // number of running start functions of the group
+	Pending int // number of start functions of the group waiting for a free slot
+}
+
+// Stats returns the current statistics of the scope.
+func (s *Scope) Stats() Stats {
+	st := Stats{
+		Running:     int(s.running.Load()),
+		PeakRunning: int(s.peak.Load()),
+		Leaked:      s.leaked(),
+		Work:        s.work(),
+	}
+	if s.limiter != nil {
+		st.Limit, st.Pending, st.Groups = s.limiter.stats()
+	}
+	if s.opts.accounting {
+		st.WallTime = s.wallTime()
+	}
+	return st
+}
+
+// wallTime returns the total wall time of all tasks.
+func (s *Scope) wallTime() time.Duration {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := time.Now()
+	var d time.Duration
+	for _, t := range s.tasks {
+		t.mtx.Lock()
+		d += t.wallTimeLocked(now)
+		t.mtx.Unlock()
+	}
+	return d
+}
+
+func (s *Scope) startRunning() {
+	n := s.running.Add(1)
+	for {
+		peak := s.peak.Load()
+		if n <= peak || s.peak.CompareAndSwap(peak, n) {
+			return
+		}
+	}
+}
+
+func (s *Scope) stopRunning() {
+	s.running.Add(-1)
+}
+
+// leaked returns the number of start functions, which are still running
+// although the scope's context was cancelled.
+func (s *Scope) leaked() int {
+	if s.ctx.Err() == nil {
+		return 0
+	}
+	return int(s.running.Load())
+}
+
+// acquire waits for the task's slot if the concurrency is limited and
+// marks the task as running. It reports false if the scope was closed
+// before a slot was available, in which case the task is skipped.
+func (s *Scope) acquire(t *task, ticket chan struct{}) bool {
+	if s.limiter == nil {
+		return true
+	}
+	if !s.limiter.wait(t.opts.group, ticket, s.closing) {
+		t.setState(skipped)
+		return false
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if isClosed(s.closing) {
+		s.limiter.release(t.opts.group)
+		t.setState(skipped)
+		return false
+	}
+	t.setState(running)
+	return true
+}
+
+func (s *Scope) release(t *task) {
+	if s.limiter != nil {
+		s.limiter.release(t.opts.group)
+	}
+}
+
+// limiter hands out a limited number of slots. The slots are shared
+// fairly between the task groups according to their weights (see Group
+// and WithGroupWeight). Within a group, the slots are handed out in
+// FIFO order.
+type limiter struct {
+	mtx     sync.Mutex
+	limit   int
+	active  int
+	pending int
+	seq     uint64 // sequence number of the last ticket
+	weights map[string]int
+	groups  map[string]*limitGroup
+
+	adaptive *adaptiveLimit // nil if the limit is static
+}
+
+// limitGroup holds the slots and the waiting tickets of a task group.
+type limitGroup struct {
+	weight int
+	active int
+	queue  []waiter
+}
+
+type waiter struct {
+	ticket chan struct{}
+	seq    uint64
+}
+
+// adaptiveLimit holds the configuration and state of an adaptive limit
+// (see WithAdaptiveLimit).
+type adaptiveLimit struct {
+	min       int
+	max       int
+	latency   time.Duration
+	successes int // successful start functions since the last change
+}
+
+func (a *adaptiveLimit) clone() *adaptiveLimit {
+	if a == nil {
+		return nil
+	}
+	c := *a
+	return &c
+}
+
+// newLimiter returns a new limiter for the concurrency limit of the
+// options, or nil if the concurrency is unlimited.
+func (o *options) newLimiter() *limiter {
+	if o.limit <= 0 {
+		return nil
+	}
+	l := newLimiter(o.limit, o.weights)
+	l.adaptive = o.adaptive.clone()
+	return l
+}
+
+func newLimiter(limit int, weights map[string]int) *limiter {
+	return &limiter{
+		limit:   limit,
+		weights: weights,
+		groups:  make(map[string]*limitGroup),
+	}
+}
+
+// group returns the state of the given task group. It must be called
+// with the limiter's mutex held.
+func (l *limiter) group(name string) *limitGroup {
+	g := l.groups[name]
+	if g == nil {
+		g = &limitGroup{weight: 1}
+		if w, ok := l.weights[name]; ok {
+			g.weight = w
+		}
+		l.groups[name] = g
+	}
+	return g
+}
+
+// enqueue requests a slot for a task of the given group. If a slot is
+// available immediately, nil is returned. Otherwise the returned ticket
+// is closed as soon as the slot is handed over.
+func (l *limiter) enqueue(group string) chan struct{} {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	g := l.group(group)
+	if l.active < l.limit && l.pending == 0 {
+		l.active++
+		g.active++
+		return nil
+	}
+	l.seq++
+	ticket := make(chan struct{})
+	g.queue = append(g.queue, waiter{ticket: ticket, seq: l.seq})
+	l.pending++
+	return ticket
+}
+
+// wait waits until the given ticket was handed a slot. It reports
+// false if done is closed before, in which case the ticket is removed
+// from the queue.
+func (l *limiter) wait(group string, ticket chan struct{}, done <-chan struct{}) bool {
+	if ticket == nil {
+		return true
+	}
+
+	select {
+	case <-ticket:
+		return true
+	case <-done:
+	}
+
+	l.mtx.Lock()
+	g := l.group(group)
+	for i, w := range g.queue {
+		if w.ticket == ticket {
+			g.queue = append(g.queue[:i], g.queue[i+1:]...)
+			l.pending--
+			l.pruneLocked(group, g)
+			l.mtx.Unlock()
+			return false
+		}
+	}
+	l.mtx.Unlock()
+
+	// The slot was handed over concurrently.
+	l.release(group)
+	return false
+}
+
+// release frees a slot of the given group, which is handed over to the
+// next waiting ticket (if any).
+func (l *limiter) release(group string) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.active--
+	g := l.group(group)
+	g.active--
+	l.pruneLocked(group, g)
+	l.grantLocked()
+}
+
+// pruneLocked removes the given group if it has neither active tasks nor
+// waiting tickets, so the groups of short-lived tasks do not accumulate.
+// It must be called with the limiter's mutex held.
+func (l *limiter) pruneLocked(name string, g *limitGroup) {
+	if g.active == 0 && len(g.queue) == 0 {
+		delete(l.groups, name)
+	}
+}
+
+// observe adapts the limit to the result of a start function, if the
+// limit is adaptive.
+func (l *limiter) observe(err error, d time.Duration) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	a := l.adaptive
+	if a == nil {
+		return
+	}
+
+	if err != nil || (a.latency > 0 && d > a.latency) {
+		l.limit = max(l.limit/2, a.min)
+		a.successes = 0
+		return
+	}
+	if a.successes++; a.successes >= l.limit {
+		l.limit = min(l.limit+1, a.max)
+		a.successes = 0
+		l.grantLocked()
+	}
+}
+
+// grantLocked hands over free slots to waiting tickets. The next slot
+// goes to the waiting group with the fewest active tasks relative to
+// its weight, and to the oldest ticket on a tie. It must be called with
+// the limiter's mutex held.
+func (l *limiter) grantLocked() {
+	for l.active < l.limit && l.pending > 0 {
+		var next *limitGroup
+		for _, g := range l.groups {
+			if len(g.queue) > 0 && (next == nil || g.before(next)) {
+				next = g
+			}
+		}
+
+		w := next.queue[0]
+		next.queue[0] = waiter{}
+		next.queue = next.queue[1:]
+		l.pending--
+		l.active++
+		next.active++
+		close(w.ticket)
+	}
+}
+
+// before reports whether the group is served before the other group.
+func (g *limitGroup) before(other *limitGroup) bool {
+	share, otherShare := g.active*other.weight, other.active*g.weight
+	if share != otherShare {
+		return share < otherShare
+	}
+	return g.queue[0].seq < other.queue[0].seq
+}
+
+func (l *limiter) stats() (limit, pending int, groups map[string]GroupStats) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	groups = make(map[string]GroupStats, len(l.groups))
+	for name, g := range l.groups {
+		groups[name] = GroupStats{Weight: g.weight, Active: g.active, Pending: len(g.queue)}
+	}
+	return l.limit, l.pending, groups
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,277 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestScopeLimit(t *testing.T) {
+	s := New(
+		WithErrorHandler(func(err error) { t.Fatalf("unexpected error: %v", err) }),
+		WithLimit(1),
+	)
+
+	block := make(chan struct{})
+	first := newCall(func(context.Context) error {
+		<-block
+		return nil
+	})
+	second := newCall(nil)
+	s.Go(first.f)
+	s.Go(second.f)
+
+	waitStats(t, s, Stats{Limit: 1, Running: 1, PeakRunning: 1, Pending: 1})
+	if second.called() {
+		t.Fatal("expected second function not to be called")
+	}
+
+	close(block)
+	if err := second.wait(time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestScopeLimitClose(t *testing.T) {
+	s := New(
+		WithErrorHandler(func(err error) { t.Fatalf("unexpected error: %v", err) }),
+		WithLimit(1),
+	)
+
+	running := newCall(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	queued := newCall(nil)
+	stop := newCall(nil)
+	s.Go(running.f)
+	s.Start(Service{Start: queued.f, Stop: stop.f})
+
+	waitStats(t, s, Stats{Limit: 1, Running: 1, PeakRunning: 1, Pending: 1})
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if queued.called() || stop.called() {
+		t.Fatal("expected queued service not to be called")
+	}
+}
+
+func TestWithAutoLimit(t *testing.T) {
+	s := New(WithAutoLimit())
+	if limit := s.Stats().Limit; limit != autoLimitFactor*runtime.GOMAXPROCS(0) {
+		t.Fatalf("unexpected limit: %d", limit)
+	}
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// waitStats waits for the expected stats, ignoring the group stats.
+func waitStats(t *testing.T, s *Scope, expected Stats) {
+	t.Helper()
+	stats := func() Stats {
+		st := s.Stats()
+		st.Groups = nil
+		return st
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for !reflect.DeepEqual(stats(), expected) {
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected stats: %+v", s.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestScopeStatsPeakAndLeaked(t *testing.T) {
+	s := newScope(t)
+
+	block := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		s.Go(func(context.Context) error {
+			<-block
+			return nil
+		})
+	}
+	waitStats(t, s, Stats{Running: 3, PeakRunning: 3})
+
+	closed := make(chan error, 1)
+	go func() { closed <- closeScope(s) }()
+	waitStats(t, s, Stats{Running: 3, PeakRunning: 3, Leaked: 3})
+	if m := s.Metrics(); m.PeakRunning != 3 || m.Leaked != 3 {
+		t.Fatalf("unexpected metrics: peak=%d leaked=%d", m.PeakRunning, m.Leaked)
+	}
+
+	close(block)
+	if err := <-closed; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st := s.Stats(); st.Leaked != 0 || st.PeakRunning != 3 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+}
+
+func TestAdaptiveLimit(t *testing.T) {
+	l := newLimiter(4, nil)
+	l.adaptive = &adaptiveLimit{min: 1, max: 4, latency: time.Second}
+
+	steps := []struct {
+		err      error
+		duration time.Duration
+		limit    int
+	}{
+		{err: errors.New("failed"), limit: 2},
+		{duration: 2 * time.Second, limit: 1},
+		{err: errors.New("failed"), limit: 1},
+		{limit: 2},
+		{limit: 2},
+		{limit: 3},
+	}
+	for i, step := range steps {
+		l.observe(step.err, step.duration)
+		if limit, _, _ := l.stats(); limit != step.limit {
+			t.Fatalf("unexpected limit after step %d: %d", i, limit)
+		}
+	}
+}
+
+func TestScopeAdaptiveLimit(t *testing.T) {
+	s := New(
+		WithErrorHandler(func(error) {}),
+		WithAdaptiveLimit(1, 8, 0),
+	)
+	for range 3 {
+		failed := newCall(func(context.Context) error { return errors.New("failed") })
+		s.Go(failed.f)
+		if err := failed.wait(time.Second); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st := s.Stats(); st.Limit != 1 {
+		t.Fatalf("unexpected limit: %d", st.Limit)
+	}
+}
+
+func TestLimiterGroups(t *testing.T) {
+	l := newLimiter(3, map[string]int{"batch": 1, "api": 2})
+
+	// A busy batch group occupies all slots and queues more work
+	// before the api group queues its tasks.
+	for range 3 {
+		if l.enqueue("batch") != nil {
+			t.Fatal("expected a free slot")
+		}
+	}
+	var batch, api []chan struct{}
+	for range 4 {
+		batch = append(batch, l.enqueue("batch"))
+	}
+	for range 4 {
+		api = append(api, l.enqueue("api"))
+	}
+
+	// The freed slots go to the api group until it has its share of
+	// two thirds of the slots.
+	granted := func(tickets []chan struct{}) int {
+		n := 0
+		for _, ticket := range tickets {
+			if isClosed(ticket) {
+				n++
+			}
+		}
+		return n
+	}
+	for range 3 {
+		l.release("batch")
+	}
+	if b, a := granted(batch), granted(api); b != 1 || a != 2 {
+		t.Fatalf("unexpected grants: batch=%d api=%d", b, a)
+	}
+
+	_, pending, groups := l.stats()
+	if pending != 5 {
+		t.Fatalf("unexpected number of pending tasks: %d", pending)
+	}
+	if g := groups["api"]; g != (GroupStats{Weight: 2, Active: 2, Pending: 2}) {
+		t.Fatalf("unexpected api stats: %+v", g)
+	}
+	if g := groups["batch"]; g != (GroupStats{Weight: 1, Active: 1, Pending: 3}) {
+		t.Fatalf("unexpected batch stats: %+v", g)
+	}
+}
+
+func TestScopeGroupStats(t *testing.T) {
+	s := New(WithLimit(1), WithGroupWeight("api", 2))
+
+	block := make(chan struct{})
+	f := func(context.Context) error {
+		<-block
+		return nil
+	}
+	s.Go(f, Group("api"))
+	s.Go(f)
+
+	st := s.Stats()
+	if g := st.Groups["api"]; g != (GroupStats{Weight: 2, Active: 1}) {
+		t.Fatalf("unexpected api stats: %+v", g)
+	}
+	if g := st.Groups[""]; g != (GroupStats{Weight: 1, Pending: 1}) {
+		t.Fatalf("unexpected default stats: %+v", g)
+	}
+
+	close(block)
+	if err := s.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st := s.Stats(); len(st.Groups) != 0 {
+		t.Fatalf("unexpected groups: %v", st.Groups)
+	}
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWithTaskAccounting(t *testing.T) {
+	s := New(
+		WithErrorHandler(func(err error) { t.Fatalf("unexpected error: %v", err) }),
+		WithTaskAccounting(),
+	)
+
+	task := s.Go(func(context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+	release := make(chan struct{})
+	s.Go(func(context.Context) error {
+		<-release
+		return nil
+	})
+	<-task.Done()
+
+	snap := s.Snapshot()
+	if d := snap[0].WallTime; d < 10*time.Millisecond {
+		t.Fatalf("unexpected wall time of finished task: %v", d)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if d := s.Snapshot()[1].WallTime; d < 10*time.Millisecond {
+		t.Fatalf("unexpected wall time of running task: %v", d)
+	}
+	if d := s.Stats().WallTime; d < 20*time.Millisecond {
+		t.Fatalf("unexpected total wall time: %v", d)
+	}
+
+	close(release)
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
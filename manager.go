@@ -0,0 +1,143 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Manager owns several named scopes, e.g. one per tenant or module of a
+// process. Scopes can be created and closed individually, and all of
+// them are closed with a combined deadline when the manager is closed.
+type Manager struct {
+	opts []Option
+
+	mtx    sync.Mutex
+	scopes map[string]*Scope
+	closed bool
+}
+
+// NewManager creates a new manager. The given options are applied to
+// all scopes created by the manager.
+func NewManager(o ...Option) *Manager {
+	return &Manager{
+		opts:   o,
+		scopes: make(map[string]*Scope),
+	}
+}
+
+// Create creates a new scope with the given name. The options are
+// applied after the manager's options. An error is returned if a scope
+// with the same name exists or the manager is closed. The scope is
+// removed from the manager when it is closed, even if it is closed
+// directly instead of via CloseScope.
+func (m *Manager) Create(name string, o ...Option) (*Scope, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	switch {
+	case m.closed:
+		return nil, fmt.Errorf("scope: cannot create scope %q of a closed manager", name)
+	case m.scopes[name] != nil:
+		return nil, fmt.Errorf("scope: scope %q already exists", name)
+	}
+
+	s := New(append(slices.Clip(m.opts), o...)...)
+	s.Finally(func(context.Context) error {
+		m.remove(name, s)
+		return nil
+	})
+	m.scopes[name] = s
+	return s, nil
+}
+
+// remove removes the scope with the given name unless the name was
+// reused for another scope in the meantime.
+func (m *Manager) remove(name string, s *Scope) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if m.scopes[name] == s {
+		delete(m.scopes, name)
+	}
+}
+
+// Get returns the scope with the given name.
+func (m *Manager) Get(name string) (*Scope, bool) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	s, ok := m.scopes[name]
+	return s, ok
+}
+
+// Names returns the sorted names of all scopes of the manager.
+func (m *Manager) Names() []string {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	names := make([]string, 0, len(m.scopes))
+	for name := range m.scopes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// CloseScope closes the scope with the given name and removes it from
+// the manager (see Scope.Close). An error is returned if the scope
+// does not exist.
+func (m *Manager) CloseScope(name string) error {
+	m.mtx.Lock()
+	s := m.scopes[name]
+	delete(m.scopes, name)
+	m.mtx.Unlock()
+
+	if s == nil {
+		return fmt.Errorf("scope: unknown scope %q", name)
+	}
+	return s.Close()
+}
+
+// Close closes all scopes of the manager concurrently and waits until
+// they are closed or the context is done. Errors of the scopes are
+// prefixed with the scope's name and returned as Errors. If the context
+// is done before all scopes are closed, the names of the remaining
+// scopes are reported along with the context's error. Afterwards no
+// new scopes can be created.
+func (m *Manager) Close(ctx context.Context) error {
+	m.mtx.Lock()
+	scopes := m.scopes
+	m.scopes = make(map[string]*Scope)
+	m.closed = true
+	m.mtx.Unlock()
+
+	type result struct {
+		name string
+		err  error
+	}
+	results := make(chan result, len(scopes))
+	for name, s := range scopes {
+		go func() { results <- result{name, s.CloseContext(ctx)} }()
+	}
+
+	var errs Errors
+	for n := len(scopes); n > 0; n-- {
+		select {
+		case r := <-results:
+			delete(scopes, r.name)
+			if r.err != nil {
+				errs.append(fmt.Errorf("%s: %w", r.name, r.err))
+			}
+		case <-ctx.Done():
+			names := make([]string, 0, len(scopes))
+			for name := range scopes {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+			errs.append(fmt.Errorf("scope: scopes %v not closed: %w", names, ctx.Err()))
+			return errs.err()
+		}
+	}
+	return errs.err()
+}
@@ -0,0 +1,93 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManager(t *testing.T) {
+	t.Run("create", func(t *testing.T) {
+		m := NewManager()
+		for _, name := range []string{"b", "a"} {
+			if _, err := m.Create(name); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if _, err := m.Create("a"); err == nil {
+			t.Fatal("expected error")
+		}
+		if got := fmt.Sprint(m.Names()); got != "[a b]" {
+			t.Fatalf("unexpected names: %s", got)
+		}
+
+		if err := m.CloseScope("a"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := m.Get("a"); ok {
+			t.Fatal("expected scope to be removed")
+		}
+		if err := m.Close(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := m.Create("c"); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("close-directly", func(t *testing.T) {
+		m := NewManager()
+		s, err := m.Create("a")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := m.Get("a"); ok {
+			t.Fatal("expected scope to be removed")
+		}
+
+		// The name can be reused, and closing the old scope
+		// again does not remove the new one.
+		s2, err := m.Create("a")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got, ok := m.Get("a"); !ok || got != s2 {
+			t.Fatal("expected the new scope")
+		}
+		if err := m.Close(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		m := NewManager()
+		s, err := m.Create("slow")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		s.Defer(func(context.Context) error {
+			<-release
+			return nil
+		})
+		if _, err := m.Create("fast"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := m.Close(ctx); err == nil || !strings.Contains(err.Error(), "slow") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,231 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// The tests in this file verify the happens-before guarantees of the
+// package documentation. They access plain variables without further
+// synchronization, so they are only meaningful with the race detector.
+
+const memoryIterations = 100
+
+func TestMemoryModel(t *testing.T) {
+	t.Run("registration-before-call", func(t *testing.T) {
+		for range memoryIterations {
+			s := New(WithLimit(2))
+			s.Freeze()
+
+			vals := make([]int, 8)
+			results := make([]int, len(vals))
+			for i := range vals {
+				vals[i] = i + 1
+				f := func(context.Context) error {
+					results[i] = vals[i]
+					return nil
+				}
+				switch i % 3 {
+				case 0:
+					s.Go(f)
+				case 1:
+					s.Start(Service{Start: f})
+				default:
+					s.Defer(f)
+				}
+			}
+			s.Unfreeze()
+
+			if err := s.Close(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// Services still waiting for a slot when the
+			// scope is closed are never started.
+			for i, r := range results {
+				if r != i+1 && (r != 0 || i%3 == 2) {
+					t.Fatalf("unexpected result #%d: %d", i, r)
+				}
+			}
+		}
+	})
+
+	t.Run("start-before-restart", func(t *testing.T) {
+		for range memoryIterations {
+			s := New(WithErrorHandler(func(error) {}))
+
+			var runs int
+			s.Start(Service{
+				Name: "svc",
+				Start: func(ctx context.Context) error {
+					runs++
+					if runs < 3 {
+						return errors.New("start error")
+					}
+					<-ctx.Done()
+					return nil
+				},
+				Restart: RestartOnFailure,
+				Backoff: Backoff{Initial: time.Nanosecond},
+			})
+
+			if err := s.Close(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if runs == 0 {
+				t.Fatal("expected start function to be called")
+			}
+		}
+	})
+
+	t.Run("stop-before-next-stop", func(t *testing.T) {
+		for _, parallel := range []bool{false, true} {
+			for range memoryIterations {
+				var opts []Option
+				if parallel {
+					opts = append(opts, WithParallelShutdown(4))
+				}
+				s := New(opts...)
+
+				var phases [3]int
+				for order := range phases {
+					for range 4 {
+						s.Start(Service{
+							Start: func(ctx context.Context) error {
+								<-ctx.Done()
+								return nil
+							},
+							Stop: func(context.Context) error {
+								if order > 0 && phases[order-1] == 0 {
+									t.Error("previous stop order not stopped")
+								}
+								if !parallel {
+									phases[order]++
+								}
+								return nil
+							},
+							StopOrder: order,
+						})
+					}
+					if parallel {
+						// Stop functions of the same order run concurrently,
+						// so only the last one of an order is recorded.
+						s.Start(Service{
+							Start: func(ctx context.Context) error {
+								<-ctx.Done()
+								return nil
+							},
+							Stop: func(context.Context) error {
+								phases[order] = 1
+								return nil
+							},
+							StopOrder: order,
+						})
+					}
+				}
+
+				if err := s.Close(); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+		}
+	})
+
+	t.Run("stop-before-done", func(t *testing.T) {
+		for range memoryIterations {
+			s := New()
+
+			var stopped bool
+			s.Defer(func(context.Context) error {
+				stopped = true
+				return nil
+			})
+
+			observed := make(chan bool)
+			go func() {
+				<-s.Ctx().Done()
+				observed <- stopped
+			}()
+
+			if err := s.Close(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !<-observed {
+				t.Fatal("expected stop function to return before the context is done")
+			}
+		}
+	})
+
+	t.Run("functions-before-close", func(t *testing.T) {
+		for range memoryIterations {
+			var handled []error
+			s := New(WithErrorHandler(func(err error) { handled = append(handled, err) }))
+
+			var started, stopped [4]int
+			for i := range started {
+				s.Start(Service{
+					Start: func(ctx context.Context) error {
+						started[i] = i + 1
+						if i == 0 {
+							return errors.New("start error")
+						}
+						<-ctx.Done()
+						return nil
+					},
+					Stop: func(context.Context) error {
+						stopped[i] = i + 1
+						return nil
+					},
+				})
+			}
+
+			if err := s.Close(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i := range started {
+				if started[i] != i+1 {
+					t.Fatalf("start function #%d did not complete", i)
+				}
+			}
+			// The stop function of the failed service may be skipped.
+			for i := 1; i < len(stopped); i++ {
+				if stopped[i] != i+1 {
+					t.Fatalf("stop function #%d did not complete", i)
+				}
+			}
+			if len(handled) != 1 {
+				t.Fatalf("unexpected errors: %v", handled)
+			}
+		}
+	})
+
+	t.Run("ready-before-wait", func(t *testing.T) {
+		for range memoryIterations {
+			s := New()
+
+			var ready int
+			s.Start(Service{
+				Name: "svc",
+				Start: func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+				Ready: func(context.Context) error {
+					ready = 1
+					return nil
+				},
+			})
+
+			if err := s.WaitFor(context.Background(), "svc"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ready != 1 {
+				t.Fatal("expected ready function to complete")
+			}
+			if err := s.Close(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	})
+}
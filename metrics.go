@@ -0,0 +1,195 @@
+package scope
+
+import (
+	"expvar"
+	"maps"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var defaultLatencyBounds = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+}
+
+// Metrics holds the metrics of a scope (see Scope.Metrics).
+type Metrics struct {
+	// CancelLatency records the time it took the tasks to exit after
+	// the scope's context was cancelled. Tasks which exited before the
+	// cancellation are not recorded.
+	CancelLatency Histogram
+
+	// Started is the number of start function calls, including restarts.
+	Started uint64
+	// Failed is the number of tasks, whose start function failed for
+	// good, i.e. no more restarts were allowed.
+	Failed uint64
+	// PeakRunning is the maximum number of concurrently running start
+	// functions.
+	PeakRunning int
+	// Leaked is the number of start functions, which are still running
+	// although the scope's context was cancelled.
+	Leaked int
+
+	// ShutdownDuration is the time Close took. It is zero as long as
+	// the scope is open.
+	ShutdownDuration time.Duration
+	// StopDurations holds the time the stop functions took, keyed by the
+	// name of the service or the index of the task if it has no name
+	// (e.g. "#3"). Restarted services report their last stop. With a
+	// bounded memory, the longest stop of the tasks exceeding the limit
+	// is reported as "other" (see WithBoundedMemory).
+	StopDurations map[string]time.Duration
+
+	// CloseCause is the label of the reason why the scope was closed
+	// (see CauseLabel). It is empty as long as the scope is open.
+	CloseCause string
+}
+
+// Histogram holds the distribution of observed durations.
+type Histogram struct {
+	// Bounds holds the inclusive upper bounds of the buckets in
+	// ascending order.
+	Bounds []time.Duration
+	// Counts holds the number of observations per bucket. It has one
+	// more element than Bounds, which counts all observations exceeding
+	// the last bound.
+	Counts []uint64
+	// Count is the total number of observations.
+	Count uint64
+	// Sum is the sum of all observed durations.
+	Sum time.Duration
+}
+
+func newHistogram(bounds []time.Duration) Histogram {
+	return Histogram{
+		Bounds: bounds,
+		Counts: make([]uint64, len(bounds)+1),
+	}
+}
+
+func (h *Histogram) observe(d time.Duration) {
+	i := sort.Search(len(h.Bounds), func(i int) bool { return d <= h.Bounds[i] })
+	h.Counts[i]++
+	h.Count++
+	h.Sum += d
+}
+
+func (h Histogram) clone() Histogram {
+	h.Counts = append([]uint64(nil), h.Counts...)
+	return h
+}
+
+type metrics struct {
+	started atomic.Uint64
+	failed  atomic.Uint64
+
+	mtx              sync.Mutex
+	cancelLatency    Histogram
+	shutdownDuration time.Duration
+	stopDurations    map[string]time.Duration
+	closeCause       string
+	maxKeys          int // maximum number of stop durations, zero if unlimited
+}
+
+// otherStops is the key of the stop durations, which exceed the maximum
+// number of keys. It holds the longest of these durations.
+const otherStops = "other"
+
+func newMetrics(maxKeys int) *metrics {
+	return &metrics{
+		cancelLatency: newHistogram(defaultLatencyBounds),
+		maxKeys:       maxKeys,
+	}
+}
+
+func (m *metrics) observeCancelLatency(d time.Duration) {
+	m.mtx.Lock()
+	m.cancelLatency.observe(d)
+	m.mtx.Unlock()
+}
+
+func (m *metrics) observeStop(t *task, d time.Duration) {
+	m.mtx.Lock()
+	if m.stopDurations == nil {
+		m.stopDurations = make(map[string]time.Duration)
+	}
+	key := t.String()
+	if _, ok := m.stopDurations[key]; !ok && m.maxKeys > 0 && len(m.stopDurations) >= m.maxKeys {
+		// The remaining tasks share a single key to bound
+		// the cardinality (see WithBoundedMemory).
+		key, d = otherStops, max(d, m.stopDurations[otherStops])
+	}
+	m.stopDurations[key] = d
+	m.mtx.Unlock()
+}
+
+func (m *metrics) setShutdownDuration(d time.Duration) {
+	m.mtx.Lock()
+	m.shutdownDuration = d
+	m.mtx.Unlock()
+}
+
+func (m *metrics) setCloseCause(c CloseCause) {
+	m.mtx.Lock()
+	m.closeCause = CauseLabel(c)
+	m.mtx.Unlock()
+}
+
+func (m *metrics) snapshot() Metrics {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return Metrics{
+		CancelLatency:    m.cancelLatency.clone(),
+		Started:          m.started.Load(),
+		Failed:           m.failed.Load(),
+		ShutdownDuration: m.shutdownDuration,
+		StopDurations:    maps.Clone(m.stopDurations),
+		CloseCause:       m.closeCause,
+	}
+}
+
+// Expvar returns a variable, which publishes the current state of the
+// scope as JSON, e.g. via expvar.Publish. It combines the statistics and
+// the metrics of the scope (see Scope.Stats and Scope.Metrics), so
+// operators can alert on failed or stuck services:
+//
+//	expvar.Publish("scope", s.Expvar())
+//
+// Durations are published in seconds.
+func (s *Scope) Expvar() expvar.Var {
+	return expvar.Func(func() any {
+		st, m := s.Stats(), s.Metrics()
+		stops := make(map[string]float64, len(m.StopDurations))
+		for name, d := range m.StopDurations {
+			stops[name] = d.Seconds()
+		}
+		var work WorkStats
+		for _, w := range st.Work {
+			work = work.add(w)
+		}
+		return map[string]any{
+			"running":                   st.Running,
+			"pending":                   st.Pending,
+			"peak_running":              st.PeakRunning,
+			"leaked":                    st.Leaked,
+			"queued":                    work.Queued,
+			"in_flight":                 work.InFlight,
+			"started":                   m.Started,
+			"failed":                    m.Failed,
+			"shutdown_duration_seconds": m.ShutdownDuration.Seconds(),
+			"stop_duration_seconds":     stops,
+			"close_cause":               m.CloseCause,
+		}
+	})
+}
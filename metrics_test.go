@@ -0,0 +1,81 @@
+package scope
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHistogramObserve(t *testing.T) {
+	h := newHistogram([]time.Duration{time.Millisecond, time.Second})
+	h.observe(time.Millisecond)
+	h.observe(time.Second)
+	h.observe(time.Minute)
+
+	if h.Count != 3 {
+		t.Fatalf("unexpected count: %d", h.Count)
+	}
+	if h.Sum != time.Millisecond+time.Second+time.Minute {
+		t.Fatalf("unexpected sum: %v", h.Sum)
+	}
+	for i, n := range []uint64{1, 1, 1} {
+		if h.Counts[i] != n {
+			t.Fatalf("unexpected count in bucket %d: %d", i, h.Counts[i])
+		}
+	}
+}
+
+func TestScopeMetrics(t *testing.T) {
+	s := newScope(t)
+	s.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	m := s.Metrics()
+	if m.CancelLatency.Count != 1 {
+		t.Fatalf("unexpected number of cancel latencies: %d", m.CancelLatency.Count)
+	}
+}
+
+func TestScopeExpvar(t *testing.T) {
+	var errs []error
+	s := New(WithErrorHandler(CollectErrors(&errs)))
+	s.Start(Service{
+		Name: "db",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Stop: func(context.Context) error { return nil },
+	})
+	failed := s.Go(func(context.Context) error { return errors.New("failure") })
+	<-failed.Done()
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var vars struct {
+		Started  uint64             `json:"started"`
+		Failed   uint64             `json:"failed"`
+		Shutdown float64            `json:"shutdown_duration_seconds"`
+		Stops    map[string]float64 `json:"stop_duration_seconds"`
+		Cause    string             `json:"close_cause"`
+	}
+	if err := json.Unmarshal([]byte(s.Expvar().String()), &vars); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if vars.Started != 2 || vars.Failed != 1 || vars.Shutdown <= 0 || vars.Cause != "manual" {
+		t.Fatalf("unexpected vars: %+v", vars)
+	}
+	if _, ok := vars.Stops["db"]; !ok || len(vars.Stops) != 1 {
+		t.Fatalf("unexpected stop durations: %v", vars.Stops)
+	}
+}
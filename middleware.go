@@ -0,0 +1,42 @@
+package scope
+
+// CallKind describes which function of a task is called.
+type CallKind int
+
+// The kinds of calls a middleware wraps.
+const (
+	StartCall CallKind = iota // the start function of a task
+	StopCall                  // the stop function of a task
+)
+
+func (k CallKind) String() string {
+	switch k {
+	case StartCall:
+		return "start"
+	case StopCall:
+		return "stop"
+	default:
+		return "unknown"
+	}
+}
+
+// Middleware wraps the start or stop function f of the given task and
+// returns the function, which is called instead (see WithMiddleware).
+// It allows to emit logs, metrics, or traces around each call without
+// wrapping every function by hand. Panics of f pass through the
+// middleware and are recovered by the scope afterwards.
+type Middleware func(kind CallKind, t TaskInfo, f Func) Func
+
+// wrap applies the middleware of the scope to the given function of the
+// task. The first middleware is the outermost one.
+func (s *Scope) wrap(kind CallKind, t *task, f Func) Func {
+	mw := s.opts.middleware
+	if len(mw) == 0 {
+		return f
+	}
+	info := t.info()
+	for i := len(mw) - 1; i >= 0; i-- {
+		f = mw[i](kind, info, f)
+	}
+	return f
+}
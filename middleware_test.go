@@ -0,0 +1,71 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	var (
+		mtx    sync.Mutex
+		events []string
+	)
+	record := func(name string) Middleware {
+		return func(kind CallKind, t TaskInfo, f Func) Func {
+			return func(ctx context.Context) error {
+				err := f(ctx)
+				mtx.Lock()
+				events = append(events, fmt.Sprintf("%s %s %s %v", name, kind, t, err))
+				mtx.Unlock()
+				return err
+			}
+		}
+	}
+
+	var errs []error
+	s := New(
+		WithErrorHandler(CollectErrors(&errs)),
+		WithMiddleware(record("outer")),
+		WithMiddleware(record("inner")),
+	)
+
+	errStart := errors.New("start error")
+	task := s.Start(Service{
+		Name:  "svc",
+		Start: func(context.Context) error { return errStart },
+	})
+	<-task.Done()
+	s.Start(Service{
+		Name: "worker",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Stop: func(context.Context) error { return nil },
+	})
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(errs) != 1 || errs[0] != errStart {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	expected := []string{
+		"inner start svc start error",
+		"outer start svc start error",
+		"inner stop worker <nil>",
+		"outer stop worker <nil>",
+		"inner start worker <nil>",
+		"outer start worker <nil>",
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("unexpected events: %q", events)
+	}
+}
@@ -0,0 +1,108 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+)
+
+// Module is a named bundle of services, deferred functions, and options,
+// which allows to share the bootstrapping of infrastructure like
+// databases, caches, or telemetry between applications instead of
+// copying the wiring (see Scope.Compose).
+type Module struct {
+	Name     string
+	Options  []Option  // options of the module's scope (see Scope.Child)
+	Services []Service // services, which are started in order
+	Defers   []Func    // functions, which are deferred in order (see Scope.Defer)
+}
+
+// ModuleError attributes an error to the module, which caused it (see
+// Scope.Compose).
+type ModuleError struct {
+	Module string
+	Err    error
+}
+
+func (e *ModuleError) Error() string {
+	return fmt.Sprintf("module %s: %v", e.Module, e.Err)
+}
+
+func (e *ModuleError) Unwrap() error {
+	return e.Err
+}
+
+// Compose starts the given modules in order. Each module runs in its
+// own child scope (see Child), which is named after the module. The
+// services of a module are started after all services of the previous
+// modules are ready, so the start order is deterministic. The errors of
+// a module, which are reported by the error handler or returned when
+// the module is closed, are of type *ModuleError.
+//
+// When the scope is closed, the modules are closed in reverse order. A
+// module can be closed individually via its scope (see Scope.Module).
+// If a module does not become ready, e.g. because one of its services
+// failed, the modules started by Compose are closed again and the error
+// is returned. An error is returned as well if the names of the modules
+// are empty or not unique.
+func (s *Scope) Compose(ctx context.Context, modules ...Module) error {
+	names := make(map[string]bool, len(modules))
+	for _, m := range modules {
+		switch {
+		case m.Name == "":
+			return fmt.Errorf("scope: module without name")
+		case names[m.Name] || s.Module(m.Name) != nil:
+			return fmt.Errorf("scope: duplicate module %q", m.Name)
+		}
+		names[m.Name] = true
+	}
+
+	started := make([]*Scope, 0, len(modules))
+	for _, m := range modules {
+		child := s.startModule(m)
+		started = append(started, child)
+		if err := child.WaitReady(ctx); err != nil {
+			err = &ModuleError{Module: m.Name, Err: err}
+			var errs Errors
+			errs.append(err)
+			for i := len(started) - 1; i >= 0; i-- {
+				errs.append(started[i].CloseContext(context.WithoutCancel(ctx)))
+			}
+			return errs.err()
+		}
+	}
+	return nil
+}
+
+// Module returns the scope of the open module with the given name, or nil
+// if there is none (see Compose).
+func (s *Scope) Module(name string) *Scope {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, child := range s.children {
+		if child.module == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// startModule starts the module in a new child scope.
+func (s *Scope) startModule(m Module) *Scope {
+	opts := append(m.Options[:len(m.Options):len(m.Options)], WithName(m.Name))
+	child := s.Child(opts...)
+	s.mtx.Lock()
+	child.module = m.Name
+	s.mtx.Unlock()
+	onError := child.onError
+	child.onError = func(err error) {
+		onError(&ModuleError{Module: m.Name, Err: err})
+	}
+
+	for _, f := range m.Defers {
+		child.Defer(f)
+	}
+	for _, svc := range m.Services {
+		child.Start(svc)
+	}
+	return child
+}
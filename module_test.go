@@ -0,0 +1,130 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestScopeCompose(t *testing.T) {
+	var (
+		mtx    sync.Mutex
+		events []string
+	)
+	event := func(e string) {
+		mtx.Lock()
+		events = append(events, e)
+		mtx.Unlock()
+	}
+	service := func(name string) Service {
+		return Service{
+			Name: name,
+			Start: func(ctx context.Context) error {
+				event("start " + name)
+				<-ctx.Done()
+				return nil
+			},
+			Stop: func(context.Context) error {
+				event("stop " + name)
+				return nil
+			},
+		}
+	}
+	module := func(name string, services ...string) Module {
+		m := Module{
+			Name: name,
+			Defers: []Func{func(context.Context) error {
+				event("close " + name)
+				return nil
+			}},
+		}
+		for _, svc := range services {
+			m.Services = append(m.Services, service(svc))
+		}
+		return m
+	}
+
+	t.Run("order", func(t *testing.T) {
+		s := newScope(t)
+		err := s.Compose(context.Background(),
+			module("db", "postgres"),
+			module("api", "http", "grpc"),
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if s.Module("db") == nil || s.Module("api") == nil || s.Module("cache") != nil {
+			t.Fatal("unexpected modules")
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		mtx.Lock()
+		defer mtx.Unlock()
+		// The services of a module may start in any order,
+		// but after the ones of the previous modules.
+		slices.Sort(events[1:3])
+		expected := []string{
+			"start postgres", "start grpc", "start http",
+			"stop grpc", "stop http", "close api",
+			"stop postgres", "close db",
+		}
+		if !slices.Equal(events, expected) {
+			t.Fatalf("unexpected events: %v", events)
+		}
+		events = nil
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		var errs []error
+		s := New(WithErrorHandler(CollectErrors(&errs)))
+		errBroken := errors.New("broken")
+		broken := Module{
+			Name: "broken",
+			Services: []Service{{
+				Name:  "broken",
+				Start: func(context.Context) error { return errBroken },
+				Ready: func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+			}},
+		}
+
+		err := s.Compose(context.Background(), module("db", "postgres"), broken)
+		var modErr *ModuleError
+		if !errors.As(err, &modErr) || modErr.Module != "broken" || !errors.Is(err, errBroken) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if s.Module("db") != nil {
+			t.Fatal("module was not closed")
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(errs) != 1 || !errors.As(errs[0], &modErr) || modErr.Module != "broken" {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+		mtx.Lock()
+		defer mtx.Unlock()
+		if !slices.Contains(events, "close db") {
+			t.Fatalf("unexpected events: %v", events)
+		}
+		events = nil
+	})
+
+	t.Run("names", func(t *testing.T) {
+		s := newScope(t)
+		defer closeScope(s)
+		if err := s.Compose(context.Background(), Module{}); err == nil {
+			t.Fatal("error expected")
+		}
+		if err := s.Compose(context.Background(), Module{Name: "a"}, Module{Name: "a"}); err == nil {
+			t.Fatal("error expected")
+		}
+	})
+}
@@ -2,18 +2,77 @@ package scope
 
 import (
 	"context"
+	"io"
 	"log"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"os"
+	"runtime"
+	"time"
 )
 
 type options struct {
-	ctx          context.Context
-	errorHandler func(error)
+	ctx             context.Context
+	errorHandler    func(error)
+	logger          *slog.Logger
+	slowCancel      time.Duration
+	maxLifetime     time.Duration
+	limit           int
+	adaptive        *adaptiveLimit
+	instruments     instrumentors
+	middleware      []Middleware
+	executor        Executor
+	rand            rand.Source
+	onReady         func()
+	onUnready       func()
+	grace           time.Duration
+	warnMargin      time.Duration
+	warn            func([]TaskInfo)
+	strict          bool
+	defaults        []StartOption
+	callerDepth     int
+	panicHandler    func(any)
+	failFast        bool
+	shutdownTimeout time.Duration
+	shutdownBudget  *ShutdownBudget
+	softCancel      time.Duration
+	phaseHook       func(PhaseReport)
+	parallelStops   int
+	backoffStore    BackoffStore
+	weights         map[string]int
+	accounting      bool
+	name            string
+	doneCtx         DoneContextPolicy
+	recorder        io.Writer
+	closeOnExit     bool
+	retain          int
+
+	stallThreshold    time.Duration
+	stallHandler      func(StallInfo)
+	blockingThreshold time.Duration
+	blockingReport    func(BlockingStop)
+
+	expected      []error
+	contextCheck  bool
+	contextReport func(TaskInfo)
+
+	warningHandler func(Warning)
+	stopPolicy     StopPolicy
+	signals        SignalPolicy
+	probeTimeout   time.Duration
+	finalTimeout   time.Duration
+	summary        bool
+	onSummary      func(Summary)
 }
 
 func defaultOptions() options {
 	return options{
 		ctx:          context.Background(),
 		errorHandler: func(err error) { log.Fatal(err) },
+		stopPolicy:   SkipStopOnFailure,
+		probeTimeout: defaultProbeTimeout,
+		slowCancel:   time.Second,
 	}
 }
 
@@ -22,7 +81,8 @@ func defaultOptions() options {
 type Option func(*options)
 
 // WithContext defines the base context, which will be used by the
-// scope to derive its context.
+// scope to derive its context. Further values can be attached to the
+// contexts of the scope's functions later (see Scope.SetValue).
 func WithContext(ctx context.Context) Option {
 	return func(o *options) {
 		if ctx == nil {
@@ -32,9 +92,39 @@ func WithContext(ctx context.Context) Option {
 	}
 }
 
+// DoneContextPolicy defines how a scope treats a base context, which is
+// already done when the scope is created (see WithDoneContextPolicy).
+type DoneContextPolicy int
+
+const (
+	// RunWithDoneContext creates the scope as usual. Its functions are
+	// run with a context, which is already done.
+	RunWithDoneContext DoneContextPolicy = iota
+	// RejectDoneContext fails the creation of the scope: NewE returns
+	// an error wrapping ErrContextDone and New panics.
+	RejectDoneContext
+	// CloseOnDoneContext creates a scope, which is closing right away.
+	// Its functions are never started and ErrClosed is reported for
+	// them (see Scope.IsClosed). The scope still needs to be closed.
+	CloseOnDoneContext
+)
+
+// WithDoneContextPolicy defines how the scope treats a base context (see
+// WithContext), which is already done when the scope is created. By
+// default, the scope runs its functions with the done context.
+func WithDoneContextPolicy(p DoneContextPolicy) Option {
+	return func(o *options) {
+		if p < RunWithDoneContext || p > CloseOnDoneContext {
+			panic("scope options: invalid done context policy")
+		}
+		o.doneCtx = p
+	}
+}
+
 // WithErrorHandler defines an error handler, which will be called
 // in case of an error while running functions. The default behaviour
-// calls log.Fatal.
+// calls log.Fatal. The handler is called synchronously by the failed
+// task and all calls complete before Close returns (see Scope.Close).
 func WithErrorHandler(f func(error)) Option {
 	return func(o *options) {
 		if f == nil {
@@ -43,3 +133,616 @@ func WithErrorHandler(f func(error)) Option {
 		o.errorHandler = f
 	}
 }
+
+// WithExpectedErrors defines errors, which are expected when a start
+// function returns, e.g. context.Canceled or http.ErrServerClosed. A
+// start function returning an error, which matches one of them (see
+// errors.Is), is treated as succeeded: the error is not reported, the
+// service is not restarted on failure, and its stop function is called
+// when the scope is closed.
+func WithExpectedErrors(errs ...error) Option {
+	return func(o *options) {
+		o.expected = append(o.expected, errs...)
+	}
+}
+
+// WithFailFast cancels the scope's context with the cause FatalError as
+// soon as the first start function fails, like an errgroup does. This
+// initiates the shutdown of all tasks observing the scope's context,
+// while the error is still reported by the error handler, e.g. to
+// collect the errors:
+//
+//	s := scope.New(scope.WithFailFast(), scope.WithErrorHandler(scope.CollectErrors(&errs)))
+//
+// Use Cause to retrieve the first error. The context is also cancelled
+// for errors suppressed by sampling (see ReportEvery).
+func WithFailFast() Option {
+	return func(o *options) {
+		o.failFast = true
+	}
+}
+
+// WithWarningHandler defines a function, which is called for non-fatal
+// conditions the scope detects, e.g. slow stop functions, ignored
+// cancellations, suppressed errors, or quarantined services (see
+// Warning). This keeps them apart from the errors of failed tasks, e.g.
+// in alerting. The warnings are logged regardless. The function is
+// called synchronously and should return quickly.
+func WithWarningHandler(f func(Warning)) Option {
+	return func(o *options) {
+		if f == nil {
+			panic("scope options: no warning handler specified")
+		}
+		o.warningHandler = f
+	}
+}
+
+// WithPanicHandler defines the function, which is called with the value
+// of a panic recovered from a task's start or stop function. Panics are
+// always recovered and the task is marked as failed. Without a panic
+// handler, the panic is reported as *PanicError by the error handler
+// (see WithErrorHandler). With a panic handler, panics of start
+// functions are no longer reported by the error handler. Panics of stop
+// functions are still returned by Close.
+func WithPanicHandler(h func(any)) Option {
+	return func(o *options) {
+		if h == nil {
+			panic("scope options: no panic handler specified")
+		}
+		o.panicHandler = h
+	}
+}
+
+// WithLogger defines a logger, which will be used to log the lifecycle
+// of the scope and its functions (start, completion, failure, and
+// shutdown). Slow stop functions are logged as well (see
+// WithCancelLatencyThreshold). The records carry the task, the duration,
+// and the error as attributes. By default nothing is logged.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *options) {
+		if l == nil {
+			panic("scope options: no logger specified")
+		}
+		o.logger = l
+	}
+}
+
+// WithCancelLatencyThreshold defines the maximum time a task may take to
+// exit after the scope's context was cancelled. Tasks exceeding this
+// threshold are flagged in the shutdown report (see Scope.ShutdownReport)
+// and logged. Stop functions taking longer than the threshold are logged
+// as slow as well (see WithLogger). The default threshold is one second.
+func WithCancelLatencyThreshold(d time.Duration) Option {
+	return func(o *options) {
+		if d <= 0 {
+			panic("scope options: invalid cancel latency threshold")
+		}
+		o.slowCancel = d
+	}
+}
+
+// WithMaxLifetime defines the maximum lifetime of a scope. When the
+// lifetime is reached, the scope's context will be cancelled with the
+// cause MaxLifetime. The scope still needs to be closed.
+func WithMaxLifetime(d time.Duration) Option {
+	return func(o *options) {
+		if d <= 0 {
+			panic("scope options: invalid max lifetime")
+		}
+		o.maxLifetime = d
+	}
+}
+
+// WithLimit limits the number of concurrently running start functions.
+// Additional services wait until a running start function returns. By
+// default the concurrency is unlimited.
+func WithLimit(n int) Option {
+	return func(o *options) {
+		if n <= 0 {
+			panic("scope options: invalid limit")
+		}
+		o.limit = n
+		o.adaptive = nil
+	}
+}
+
+// WithAdaptiveLimit limits the number of concurrently running start
+// functions like WithLimit, but adapts the limit to the observed load
+// (AIMD): the limit starts at max and is halved, but not below min,
+// whenever a start function fails or runs longer than the given latency.
+// After as many successful start functions as the current limit, the
+// limit is increased by one up to max. A latency of zero only considers
+// errors. The current limit is available via Scope.Stats.
+func WithAdaptiveLimit(min, max int, latency time.Duration) Option {
+	return func(o *options) {
+		if min <= 0 || max < min || latency < 0 {
+			panic("scope options: invalid adaptive limit")
+		}
+		o.limit = max
+		o.adaptive = &adaptiveLimit{min: min, max: max, latency: latency}
+	}
+}
+
+// WithAutoLimit limits the number of concurrently running start functions
+// to a multiple of the available CPUs (see WithLimit). The number of CPUs
+// is determined by runtime.GOMAXPROCS, which respects the CPU quota of the
+// process' cgroup. The chosen limit is available via Scope.Stats.
+func WithAutoLimit() Option {
+	return func(o *options) {
+		o.limit = autoLimitFactor * runtime.GOMAXPROCS(0)
+		o.adaptive = nil
+	}
+}
+
+// WithInstrumentor adds an instrumentor, which observes the lifecycle
+// of the scope and its tasks. The option can be used multiple times;
+// the instrumentors are called in the given order.
+func WithInstrumentor(i Instrumentor) Option {
+	return func(o *options) {
+		if i == nil {
+			panic("scope options: no instrumentor specified")
+		}
+		o.instruments = append(o.instruments, i)
+	}
+}
+
+// WithMiddleware adds a middleware, which wraps the start and stop
+// functions of all tasks of the scope. The option can be used multiple
+// times; the first middleware is the outermost one.
+func WithMiddleware(m Middleware) Option {
+	return func(o *options) {
+		if m == nil {
+			panic("scope options: no middleware specified")
+		}
+		o.middleware = append(o.middleware, m)
+	}
+}
+
+// WithExecutor defines the executor, which runs the goroutines of the
+// scope's tasks (see Executor). Child scopes inherit the executor. By
+// default, each goroutine is started with the go statement.
+func WithExecutor(e Executor) Option {
+	return func(o *options) {
+		if e == nil {
+			panic("scope options: no executor specified")
+		}
+		o.executor = e
+	}
+}
+
+// WithRand defines the source of randomness, which is used by the scope
+// for jitter (e.g. in backoffs and schedules). Providing a seeded source
+// makes timing-sensitive tests and simulations reproducible. The source
+// does not need to be safe for concurrent use. By default the global
+// source of math/rand/v2 is used.
+func WithRand(src rand.Source) Option {
+	return func(o *options) {
+		if src == nil {
+			panic("scope options: no random source specified")
+		}
+		o.rand = src
+	}
+}
+
+// WithName defines the name of the scope, which identifies a child scope
+// in the readiness of its parent (see Scope.Ready).
+func WithName(name string) Option {
+	return func(o *options) {
+		if name == "" {
+			panic("scope options: no name specified")
+		}
+		o.name = name
+	}
+}
+
+// WithReadinessHooks defines functions, which are called when the
+// aggregated readiness of the scope changes. The scope becomes ready
+// when all its services and children are ready (see Scope.Ready) and
+// unready when a service is registered, fails, or the scope is closed.
+// This allows to register and deregister the process at a service
+// registry. One of the functions may be nil.
+func WithReadinessHooks(onReady, onUnready func()) Option {
+	return func(o *options) {
+		if onReady == nil && onUnready == nil {
+			panic("scope options: no readiness hooks specified")
+		}
+		o.onReady = onReady
+		o.onUnready = onUnready
+	}
+}
+
+// WithShutdownTimeout bounds the total time Close may take (see
+// Scope.CloseContext).
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(o *options) {
+		if d <= 0 {
+			panic("scope options: invalid shutdown timeout")
+		}
+		o.shutdownTimeout = d
+	}
+}
+
+// WithCancelOnSignal ends the scope's context with the cause
+// SignalReceived when one of the given signals is received before the
+// scope is closed. It only cancels the context and does not close the
+// scope: like with other causes (see WithMaxLifetime), the owner of the
+// scope still needs to call Close once the context is done, which
+// RunUntilSignal does. If no signals are provided, the default
+// termination signals are handled (see SignalChan). It is a shorthand for
+// a signal policy, which maps all signals to Graceful (see
+// WithSignalPolicy).
+func WithCancelOnSignal(sigs ...os.Signal) Option {
+	if len(sigs) == 0 {
+		sigs = defaultSignals
+	}
+	p := make(SignalPolicy, len(sigs))
+	for _, sig := range sigs {
+		p[sig] = Graceful
+	}
+	return WithSignalPolicy(p)
+}
+
+// WithProbeTimeout bounds the time a single health check may take (see
+// Scope.Health). The default timeout is five seconds.
+func WithProbeTimeout(d time.Duration) Option {
+	return func(o *options) {
+		if d <= 0 {
+			panic("scope options: invalid probe timeout")
+		}
+		o.probeTimeout = d
+	}
+}
+
+// WithFinalTimeout bounds the time each final function may take (see
+// Scope.Finally). If the shutdown is bounded (see CloseContext and
+// WithShutdownTimeout), the last d of it are reserved for the final
+// functions: the stop functions get a deadline, which is d earlier than
+// the one of the shutdown. The final functions never exceed the
+// deadline of the shutdown.
+func WithFinalTimeout(d time.Duration) Option {
+	return func(o *options) {
+		if d <= 0 {
+			panic("scope options: invalid final timeout")
+		}
+		o.finalTimeout = d
+	}
+}
+
+// WithStartupSummary emits a summary of the scope's services when the
+// scope becomes ready for the first time (see Scope.Ready and
+// Scope.Summary). The summary is logged as a single record (see
+// WithLogger) and passed to f, which may be nil.
+func WithStartupSummary(f func(Summary)) Option {
+	return func(o *options) {
+		o.summary = true
+		o.onSummary = f
+	}
+}
+
+// WithSignalPolicy lets the scope handle the signals of the given policy
+// until it is closed, e.g. to reload on SIGHUP and to stop gracefully on
+// SIGTERM (see SignalPolicy). Signals, which are received after the
+// scope's context is done, are ignored by the scope, so its owner can
+// force the shutdown (see RunUntilSignal).
+func WithSignalPolicy(p SignalPolicy) Option {
+	p = maps.Clone(p)
+	return func(o *options) {
+		for _, a := range p {
+			if a.kind == 0 {
+				panic("scope options: invalid signal action")
+			}
+		}
+		o.signals = p
+	}
+}
+
+// WithStopPolicy defines the default stop policy of the scope's
+// services, which do not define one (see Service). The default policy is
+// SkipStopOnFailure.
+func WithStopPolicy(p StopPolicy) Option {
+	return func(o *options) {
+		if p < SkipStopOnFailure || p > StopOnlyOnSuccess {
+			panic("scope options: invalid stop policy")
+		}
+		o.stopPolicy = p
+	}
+}
+
+// WithShutdownBudget bounds the time Close may take by the deadline of
+// the given budget, which is shared with other scopes (see
+// ShutdownBudget). The budget starts when the first of the scopes is
+// closed. Child scopes share the budget of their parent. If a shutdown
+// timeout is configured as well, the earlier deadline applies (see
+// WithShutdownTimeout).
+func WithShutdownBudget(b *ShutdownBudget) Option {
+	return func(o *options) {
+		if b == nil {
+			panic("scope options: no shutdown budget specified")
+		}
+		o.shutdownBudget = b
+	}
+}
+
+// WithSoftCancel enables a two-stage cancellation on Close: first the
+// tasks are asked to finish their current work (see Stopping), then Close
+// waits up to the given duration for the start functions to return,
+// before the stop functions are called and the scope's context is
+// cancelled.
+func WithSoftCancel(d time.Duration) Option {
+	return func(o *options) {
+		if d <= 0 {
+			panic("scope options: invalid soft cancel period")
+		}
+		o.softCancel = d
+	}
+}
+
+// WithParallelShutdown calls up to n stop functions concurrently when
+// the scope is closed. Only stop functions of the same stop order run
+// concurrently (see Service), so the stop order still defines phases,
+// which are stopped one after another. The errors of the stop functions
+// are returned in stop order. By default the stop functions are called
+// sequentially.
+func WithParallelShutdown(n int) Option {
+	return func(o *options) {
+		if n <= 0 {
+			panic("scope options: invalid shutdown concurrency")
+		}
+		o.parallelStops = n
+	}
+}
+
+// WithBackoffStore persists the consecutive failures of named services
+// in the given store. When a service is started and the store reports
+// previous failures, e.g. because the process crashed and was restarted
+// by its orchestrator, the start is delayed according to the service's
+// backoff (see Service). The failures are reset when the start function
+// returns nil or the service is stopped. Errors of the store are logged
+// and otherwise ignored.
+func WithBackoffStore(store BackoffStore) Option {
+	return func(o *options) {
+		if store == nil {
+			panic("scope options: no backoff store specified")
+		}
+		o.backoffStore = store
+	}
+}
+
+// WithGroupWeight defines the share of the concurrency limit of a task
+// group (see Group). When a slot becomes free, it is handed to the group
+// with the fewest running tasks relative to its weight, so a busy group
+// cannot starve the waiting tasks of other groups. The default weight of
+// a group is 1. The option has no effect if the concurrency is unlimited
+// (see WithLimit).
+func WithGroupWeight(group string, weight int) Option {
+	return func(o *options) {
+		if weight <= 0 {
+			panic("scope options: invalid group weight")
+		}
+		o.weights = maps.Clone(o.weights)
+		if o.weights == nil {
+			o.weights = make(map[string]int)
+		}
+		o.weights[group] = weight
+	}
+}
+
+// WithPhaseHook defines a function, which is called at the end of each
+// phase of Close with the phase's name and duration (see PhaseReport).
+// This allows to alert on slow phases individually.
+func WithPhaseHook(f func(PhaseReport)) Option {
+	return func(o *options) {
+		if f == nil {
+			panic("scope options: no phase hook specified")
+		}
+		o.phaseHook = f
+	}
+}
+
+// WithGracePeriod defines the time the process has to shut down after
+// the scope was closed, before it will be killed (e.g. the termination
+// grace period of a Kubernetes pod). It is used to warn about a doomed
+// shutdown (see WithDeadlineWarning).
+func WithGracePeriod(d time.Duration) Option {
+	return func(o *options) {
+		if d <= 0 {
+			panic("scope options: invalid grace period")
+		}
+		o.grace = d
+	}
+}
+
+// WithStallHandler defines a function, which is called once if Close
+// takes longer than the given threshold. It is called with the tasks,
+// which keep the shutdown from finishing, and the stacks of the scope's
+// goroutines, so a service, which won't exit, can be found without
+// sending SIGQUIT to the process. The tasks are logged as well. Close
+// does not return before the function returned. The function may be nil,
+// in which case only the log is written.
+func WithStallHandler(threshold time.Duration, f func(StallInfo)) Option {
+	return func(o *options) {
+		if threshold <= 0 {
+			panic("scope options: invalid stall threshold")
+		}
+		o.stallThreshold = threshold
+		o.stallHandler = f
+	}
+}
+
+// WithDeadlineWarning defines a function, which is called with the tasks
+// still running when the given margin before the end of the grace period
+// (see WithGracePeriod) is reached during Close. The tasks are logged as
+// well. The function may be nil, in which case only the log is written.
+func WithDeadlineWarning(margin time.Duration, f func(running []TaskInfo)) Option {
+	return func(o *options) {
+		if margin <= 0 {
+			panic("scope options: invalid deadline warning margin")
+		}
+		o.warnMargin = margin
+		o.warn = f
+	}
+}
+
+// WithStrictClose makes the scope panic with a diagnostic message if any
+// task is still running at the end of the grace period (see WithGracePeriod)
+// during Close. The message lists the running tasks and their goroutines.
+// This option is intended for development and tests and requires a grace
+// period.
+func WithStrictClose() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithBlockingStopDetection samples the stop functions while the scope is
+// closing and reports stop functions, which run longer than the given
+// threshold and are blocked when sampled, e.g. waiting for a channel, a
+// lock, or a system call. Such stop functions are the most common cause
+// of hanging shutdowns, especially if their context has no deadline
+// (see StopTimeout and WithShutdownTimeout). Each stop function is
+// reported at most once with the stack of its goroutine. The report is
+// logged as well. The function may be nil, in which case only the log is
+// written. The detection inspects the stacks of all goroutines and is
+// intended for development and tests.
+func WithBlockingStopDetection(threshold time.Duration, report func(BlockingStop)) Option {
+	return func(o *options) {
+		if threshold <= 0 {
+			panic("scope options: invalid blocking threshold")
+		}
+		o.blockingThreshold = threshold
+		o.blockingReport = report
+	}
+}
+
+// WithContextCheck detects start functions, which never observe their
+// context, i.e. which neither wait for its Done channel nor check its
+// error, although the context was cancelled while they were running.
+// Such tasks cannot be cancelled and delay the shutdown. Each detected
+// start function is reported when it returned. The report is logged as
+// well. The function may be nil, in which case only the log is written.
+// Deriving a cancellable context (e.g. with context.WithCancel) counts
+// as observing the context. The check is intended for development and
+// tests, e.g. to audit a large code base for tasks, which cannot be
+// cancelled.
+func WithContextCheck(report func(TaskInfo)) Option {
+	return func(o *options) {
+		o.contextCheck = true
+		o.contextReport = report
+	}
+}
+
+// WithTaskAccounting measures the wall time of the start functions of
+// each task, which is the total time the start functions ran across all
+// restarts. The wall times are available in the task snapshots and their
+// total in the statistics (see Scope.Snapshot and Scope.Stats).
+//
+// Go does not expose the CPU time of single Goroutines. To find the tasks
+// consuming the CPU, a CPU profile can be recorded; its samples carry the
+// pprof labels of the tasks (see Scope.DumpGoroutines).
+func WithTaskAccounting() Option {
+	return func(o *options) {
+		o.accounting = true
+	}
+}
+
+// WithRecorder records every lifecycle decision of the scope, e.g. when a
+// task is scheduled, a restart is decided, a shutdown phase is entered,
+// or a timeout fires, to w. The records carry monotonic timestamps and
+// are written in a compact, line-oriented format, which can be read with
+// ReadRecords for post-incident analysis. The records are written
+// synchronously, so w should be fast, e.g. a buffered file.
+func WithRecorder(w io.Writer) Option {
+	return func(o *options) {
+		if w == nil {
+			panic("scope options: no recorder specified")
+		}
+		o.recorder = w
+	}
+}
+
+// WithBoundedMemory caps the history, which the scope retains, so its
+// memory stays bounded when it runs for months with a steady churn of
+// tasks, e.g. on an embedded device. With a bounded memory, the scope
+// keeps at most n finished tasks without a stop function, the n latest
+// regions of each task, and the stop durations of n tasks in its metrics
+// (see Metrics). Older finished tasks are dropped from snapshots, reports,
+// and Wait, so their errors are only reported to the error handler.
+// Deferred functions and services with a stop function are retained
+// until the scope is closed, since they are needed for the shutdown.
+// Hence the retained history is proportional to n and to the number of
+// registered stop functions, but not to the number of tasks run so far.
+// Child scopes are released when they are closed.
+func WithBoundedMemory(n int) Option {
+	return func(o *options) {
+		if n <= 0 {
+			panic("scope options: invalid retention")
+		}
+		o.retain = n
+	}
+}
+
+// WithCallerStacks records the stack of the call site, which registered
+// a task, up to the given number of frames (see TaskInfo). By default,
+// only the file and line of the call site are recorded.
+func WithCallerStacks(depth int) Option {
+	return func(o *options) {
+		if depth <= 0 {
+			panic("scope options: invalid caller stack depth")
+		}
+		o.callerDepth = depth
+	}
+}
+
+// WithTaskDefaults defines start options, which are applied to every task
+// of the scope (see Scope.Go and Scope.Start). Options passed on start
+// are applied afterwards and thus override the defaults.
+func WithTaskDefaults(opts ...StartOption) Option {
+	return func(o *options) {
+		for _, opt := range opts {
+			if opt == nil {
+				panic("scope options: no start option specified")
+			}
+		}
+		o.defaults = append(o.defaults, opts...)
+	}
+}
+
+type taskOptions struct {
+	sampler     *sampler
+	stopTimeout time.Duration
+	group       string
+	stopOnError bool
+	overlap     OverlapPolicy
+	location    *time.Location
+
+	scheduleStore ScheduleStore
+	scheduleName  string
+	catchUp       bool
+}
+
+// StartOption represents an option which can be used to configure a
+// single task (see Scope.Go and Scope.Start).
+type StartOption func(*taskOptions)
+
+// Group assigns the task to the given group, which shares the
+// concurrency limit of the scope with other groups according to its
+// weight (see WithGroupWeight). Tasks without a group belong to the
+// group "".
+func Group(name string) StartOption {
+	return func(o *taskOptions) {
+		o.group = name
+	}
+}
+
+// StopTimeout limits the time the task's stop function may take. The
+// context passed to the stop function is cancelled after the given
+// duration. The wait for an idle service is limited separately by the
+// same duration (see Service). A duration of zero removes the limit.
+func StopTimeout(d time.Duration) StartOption {
+	return func(o *taskOptions) {
+		if d < 0 {
+			panic("scope options: invalid stop timeout")
+		}
+		o.stopTimeout = d
+	}
+}
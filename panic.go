@@ -0,0 +1,46 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is the error of a task, whose start or stop function
+// panicked.
+type PanicError struct {
+	Value any    // the value passed to panic
+	Stack []byte // stack of the panicking goroutine
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("scope: panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// protect calls f and converts a panic into an error of type *PanicError.
+func protect(ctx context.Context, f Func) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return f(ctx)
+}
+
+// handlePanic passes the value of a recovered panic to the panic handler
+// (see WithPanicHandler). It reports whether the handler was called.
+func (s *Scope) handlePanic(err error) bool {
+	var pe *PanicError
+	if s.opts.panicHandler == nil || !errors.As(err, &pe) {
+		return false
+	}
+	s.opts.panicHandler(pe.Value)
+	return true
+}
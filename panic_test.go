@@ -0,0 +1,57 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestScopePanic(t *testing.T) {
+	t.Run("error-handler", func(t *testing.T) {
+		reported := make(chan error, 1)
+		s := New(WithErrorHandler(func(err error) { reported <- err }))
+		s.Go(func(context.Context) error { panic("boom") })
+
+		var pe *PanicError
+		if err := <-reported; !errors.As(err, &pe) || pe.Value != "boom" || len(pe.Stack) == 0 {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("panic-handler", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		recovered := make(chan any, 1)
+		s := New(
+			WithErrorHandler(func(err error) { t.Fatalf("unexpected error: %v", err) }),
+			WithPanicHandler(func(v any) { recovered <- v }),
+		)
+		s.Start(Service{
+			Name:  "svc",
+			Start: func(context.Context) error { panic(errBoom) },
+		})
+
+		if v := <-recovered; v != errBoom {
+			t.Fatalf("unexpected panic value: %v", v)
+		}
+		if err := s.WaitFor(context.Background(), "svc"); !errors.Is(err, errBoom) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		s := newScope(t)
+		s.Defer(func(context.Context) error { panic("boom") })
+
+		var pe *PanicError
+		err := closeScope(s)
+		if errs, ok := err.(Errors); !ok || errs.Len() != 1 || !errors.As(errs.All()[0], &pe) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package scope
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ScheduleStore persists the time of the last run of periodic tasks by
+// their names. This allows a restarted process to detect the runs, which
+// were due while it was down (see Persist). The implementation must be
+// safe for concurrent use.
+type ScheduleStore interface {
+	// Load returns the time of the last run of the periodic task with
+	// the given name, or the zero time if nothing was stored.
+	Load(name string) (time.Time, error)
+	// Save stores the time of the last run of the periodic task with
+	// the given name.
+	Save(name string, last time.Time) error
+}
+
+// Persist records the start of each run of a periodic task (see
+// Scope.Every and Scope.Schedule) under the given name in the store. When
+// the task is started, e.g. after the process was restarted, a run, which
+// was due since the last recorded run, is reported as missed (see
+// WarnMissedRun). Missed runs are skipped, unless the task was started
+// with CatchUp. Errors of the store are logged and otherwise ignored.
+func Persist(store ScheduleStore, name string) StartOption {
+	return func(o *taskOptions) {
+		if store == nil {
+			panic("scope options: no schedule store specified")
+		}
+		if name == "" {
+			panic("scope options: no schedule name specified")
+		}
+		o.scheduleStore = store
+		o.scheduleName = name
+	}
+}
+
+// CatchUp makes up the missed runs of a persisted task right away, when
+// the task is started (see Persist). Several missed runs are made up by a
+// single run.
+func CatchUp() StartOption {
+	return func(o *taskOptions) {
+		o.catchUp = true
+	}
+}
+
+// missedRun loads the last run of a persisted task (see Persist) and
+// reports a run, which was due according to next, but was missed. It
+// returns true if the missed run should be made up.
+func (s *Scope) missedRun(ctx context.Context, next func(time.Time) time.Time) bool {
+	t := taskFromContext(ctx)
+	store := t.opts.scheduleStore
+	if store == nil {
+		return false
+	}
+
+	last, err := store.Load(t.opts.scheduleName)
+	if err != nil {
+		s.log(slog.LevelWarn, "loading last run failed", "task", t, "schedule", t.opts.scheduleName, "error", err)
+		return false
+	}
+	if last.IsZero() {
+		return false
+	}
+	due := next(last)
+	if due.IsZero() || !due.Before(time.Now()) {
+		return false
+	}
+
+	overdue := time.Since(due)
+	s.log(slog.LevelWarn, "scheduled run missed", "task", t, "schedule", t.opts.scheduleName, "due", due, "catch_up", t.opts.catchUp)
+	s.record("missed", t, "schedule", t.opts.scheduleName, "overdue", overdue)
+	s.warn(Warning{Kind: WarnMissedRun, Task: t.info(), Duration: overdue})
+	return t.opts.catchUp
+}
+
+// saveRun records the start of a run of a persisted task (see Persist).
+func (s *Scope) saveRun(t *task, started time.Time) {
+	store := t.opts.scheduleStore
+	if store == nil {
+		return
+	}
+	if err := store.Save(t.opts.scheduleName, started); err != nil {
+		s.log(slog.LevelWarn, "saving last run failed", "task", t, "schedule", t.opts.scheduleName, "error", err)
+	}
+}
@@ -0,0 +1,163 @@
+package scope
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPersist(t *testing.T) {
+	hourly := func(last time.Time) time.Time { return last.Add(time.Hour) }
+
+	missed := func(t *testing.T, store ScheduleStore, opts ...StartOption) (bool, string) {
+		var (
+			buf      bytes.Buffer
+			warnings []Warning
+		)
+		s := New(
+			WithLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+			WithWarningHandler(func(w Warning) { warnings = append(warnings, w) }),
+		)
+
+		var catchUp bool
+		opts = append(opts, Persist(store, "job"))
+		task := s.Go(func(ctx context.Context) error {
+			catchUp = s.missedRun(ctx, hourly)
+			return nil
+		}, opts...)
+		<-task.Done()
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		logged := strings.Contains(buf.String(), "scheduled run missed")
+		switch {
+		case !logged && len(warnings) != 0:
+			t.Fatalf("unexpected warnings: %v", warnings)
+		case logged && (len(warnings) != 1 || warnings[0].Kind != WarnMissedRun || warnings[0].Duration <= 0):
+			t.Fatalf("unexpected warnings: %v", warnings)
+		}
+		return catchUp, buf.String()
+	}
+
+	t.Run("save", func(t *testing.T) {
+		store := &memScheduleStore{}
+		s := newScope(t)
+
+		started := time.Now()
+		task := s.Go(func(ctx context.Context) error {
+			s.saveRun(taskFromContext(ctx), started)
+			return nil
+		}, Persist(store, "job"))
+		<-task.Done()
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if last, _ := store.Load("job"); !last.Equal(started) {
+			t.Fatalf("unexpected last run: %v", last)
+		}
+	})
+
+	t.Run("never-run", func(t *testing.T) {
+		catchUp, log := missed(t, &memScheduleStore{}, CatchUp())
+		if catchUp {
+			t.Fatal("expected no catch-up")
+		}
+		if strings.Contains(log, "scheduled run missed") {
+			t.Fatalf("unexpected log:\n%s", log)
+		}
+	})
+
+	t.Run("not-missed", func(t *testing.T) {
+		store := &memScheduleStore{}
+		store.Save("job", time.Now())
+
+		catchUp, log := missed(t, store, CatchUp())
+		if catchUp {
+			t.Fatal("expected no catch-up")
+		}
+		if strings.Contains(log, "scheduled run missed") {
+			t.Fatalf("unexpected log:\n%s", log)
+		}
+	})
+
+	t.Run("missed", func(t *testing.T) {
+		store := &memScheduleStore{}
+		store.Save("job", time.Now().Add(-2*time.Hour))
+
+		catchUp, log := missed(t, store)
+		if catchUp {
+			t.Fatal("expected no catch-up")
+		}
+		if !strings.Contains(log, "scheduled run missed") {
+			t.Fatalf("expected missed run to be logged, got:\n%s", log)
+		}
+	})
+
+	t.Run("catch-up", func(t *testing.T) {
+		store := &memScheduleStore{}
+		store.Save("job", time.Now().Add(-2*time.Hour))
+
+		catchUp, _ := missed(t, store, CatchUp())
+		if !catchUp {
+			t.Fatal("expected catch-up")
+		}
+	})
+
+	t.Run("load-error", func(t *testing.T) {
+		store := &memScheduleStore{err: errors.New("unavailable")}
+
+		catchUp, log := missed(t, store, CatchUp())
+		if catchUp {
+			t.Fatal("expected no catch-up")
+		}
+		if !strings.Contains(log, "loading last run failed") {
+			t.Fatalf("expected load error to be logged, got:\n%s", log)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for name, f := range map[string]func(){
+			"nil-store":  func() { Persist(nil, "job")(&taskOptions{}) },
+			"empty-name": func() { Persist(&memScheduleStore{}, "")(&taskOptions{}) },
+		} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Fatalf("%s: expected panic", name)
+					}
+				}()
+				f()
+			}()
+		}
+	})
+}
+
+type memScheduleStore struct {
+	mtx  sync.Mutex
+	last map[string]time.Time
+	err  error
+}
+
+func (s *memScheduleStore) Load(name string) (time.Time, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.last[name], s.err
+}
+
+func (s *memScheduleStore) Save(name string, last time.Time) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.last == nil {
+		s.last = make(map[string]time.Time)
+	}
+	s.last[name] = last
+	return nil
+}
@@ -0,0 +1,126 @@
+package scope
+
+import "sync"
+
+// Pool runs functions of a scope with a bounded number of Goroutines.
+// Functions exceeding the pool's size are queued without a Goroutine
+// until a worker is free. This allows to fan out over many items
+// without spawning a Goroutine per item (see Scope.Pool).
+type Pool struct {
+	s    *Scope
+	size int
+
+	mtx     sync.Mutex
+	queue   []*task
+	workers int
+	active  int // number of running functions
+}
+
+// Pool creates a pool, which runs at most n functions of the scope
+// concurrently. The concurrency limit of the scope (see WithLimit)
+// applies in addition. When the scope is closed, the queued functions
+// are never started and their handles report ErrNotStarted (see Task).
+func (s *Scope) Pool(n int) *Pool {
+	if n <= 0 {
+		panic("scope: invalid pool size")
+	}
+	return &Pool{s: s, size: n}
+}
+
+// Go queues the given function, which is run by the next free worker of
+// the pool. If the function returns an error, it will be reported by the
+// registered error handler (see WithErrorHandler).
+func (p *Pool) Go(f Func, opts ...StartOption) *Task {
+	s := p.s
+	t := &task{svc: Service{Start: f}}
+	s.capture(t)
+	for _, apply := range s.opts.defaults {
+		apply(&t.opts)
+	}
+	for _, apply := range opts {
+		apply(&t.opts)
+	}
+	// See Scope.start for why the task is rejected, registered,
+	// and added to the wait group while holding the mutex.
+	s.mtx.Lock()
+	if err := s.rejectionLocked(); err != nil {
+		s.mtx.Unlock()
+		s.reject(t, err)
+		return (*Task)(t)
+	}
+	t.setState(pending)
+	s.registerLocked(t)
+
+	p.mtx.Lock()
+	p.queue = append(p.queue, t)
+	s.pools.queued.Add(1)
+	spawn := p.workers < p.size
+	if spawn {
+		p.workers++
+	}
+	p.mtx.Unlock()
+	if spawn {
+		s.wg.Add(1)
+	}
+	s.mtx.Unlock()
+	s.updateReadiness()
+
+	if spawn {
+		s.spawn(p.work)
+	}
+	return (*Task)(t)
+}
+
+// Len returns the number of queued functions, which wait for a worker.
+func (p *Pool) Len() int {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return len(p.queue)
+}
+
+// Stats returns the pending work of the pool. The queued functions wait
+// for a worker, the functions in flight are running.
+func (p *Pool) Stats() WorkStats {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return WorkStats{Queued: len(p.queue), InFlight: p.active}
+}
+
+// work runs the queued functions until the queue is empty. Functions,
+// which are dequeued after the scope was closed, are skipped.
+func (p *Pool) work() {
+	s := p.s
+	defer s.wg.Done()
+
+	for {
+		p.mtx.Lock()
+		if len(p.queue) == 0 {
+			p.workers--
+			p.mtx.Unlock()
+			return
+		}
+		t := p.queue[0]
+		p.queue[0] = nil
+		p.queue = p.queue[1:]
+		skip := isClosed(s.closing)
+		if !skip {
+			p.active++
+			s.pools.inFlight.Add(1)
+		}
+		s.pools.queued.Add(-1)
+		p.mtx.Unlock()
+
+		if skip {
+			t.setState(skipped)
+			s.notify()
+			t.finish()
+			continue
+		}
+		s.run(s.ctx, t, s.enqueue(t))
+
+		p.mtx.Lock()
+		p.active--
+		s.pools.inFlight.Add(-1)
+		p.mtx.Unlock()
+	}
+}
@@ -0,0 +1,76 @@
+package scope
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool(t *testing.T) {
+	t.Run("bounded", func(t *testing.T) {
+		s := newScope(t)
+		pool := s.Pool(3)
+
+		var running, peak, calls atomic.Int32
+		tasks := make([]*Task, 100)
+		for i := range tasks {
+			tasks[i] = pool.Go(func(context.Context) error {
+				n := running.Add(1)
+				defer running.Add(-1)
+				for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+				}
+				calls.Add(1)
+				time.Sleep(100 * time.Microsecond)
+				return nil
+			})
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		for _, task := range tasks {
+			if err := task.Wait(ctx); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := calls.Load(); n != 100 {
+			t.Fatalf("unexpected number of calls: %d", n)
+		}
+		if n := peak.Load(); n > 3 {
+			t.Fatalf("unexpected concurrency: %d", n)
+		}
+	})
+
+	t.Run("close", func(t *testing.T) {
+		s := newScope(t)
+		pool := s.Pool(1)
+
+		started := make(chan struct{})
+		first := pool.Go(func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return nil
+		})
+		queued := pool.Go(func(context.Context) error {
+			t.Error("unexpected call of a queued function")
+			return nil
+		})
+		<-started
+		if n := pool.Len(); n != 1 {
+			t.Fatalf("unexpected queue length: %d", n)
+		}
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := first.Wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := queued.Wait(context.Background()); err != ErrNotStarted {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
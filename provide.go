@@ -0,0 +1,139 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+type scopeKey struct{}
+
+// provided is a value of the scope's registry (see Provide).
+type provided struct {
+	val any
+	t   *task // task, which provided the value
+}
+
+// Provide makes the given value available to the functions of the scope,
+// which runs the calling function, by its type (see Get). It must be
+// called from a start, stop, or ready function of a task. A value
+// provided for the same type replaces the previous one.
+func Provide[T any](ctx context.Context, v T) {
+	s, t := scopeFromContext(ctx), taskFromContext(ctx)
+	if s == nil || t == nil {
+		panic("scope: Provide called outside of a task")
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.provided == nil {
+		s.provided = make(map[reflect.Type]provided)
+	}
+	s.provided[reflect.TypeFor[T]()] = provided{val: v, t: t}
+	s.notifyLocked()
+}
+
+// Get returns the value of type T, which was provided to the scope of
+// the calling function (see Provide). It waits until a value is provided
+// or the context is done, in which case the context's error is returned.
+// It must be called from a start, stop, or ready function of a task.
+//
+// The calling task is recorded as a consumer of the providing task. When
+// the scope is closed, consumers are stopped before their providers
+// within the same stop order, like services, which depend on others
+// explicitly (see Service.DependsOn). If the providing task depends on
+// the calling task already, the lookup is rejected with an error, which
+// wraps ErrDependencyCycle and describes the cycle.
+func Get[T any](ctx context.Context) (T, error) {
+	s, t := scopeFromContext(ctx), taskFromContext(ctx)
+	if s == nil || t == nil {
+		panic("scope: Get called outside of a task")
+	}
+
+	typ := reflect.TypeFor[T]()
+	var p provided
+	err := s.await(ctx, func() (bool, error) {
+		var ok bool
+		if p, ok = s.provided[typ]; !ok {
+			return false, nil
+		}
+		return true, s.addProviderLocked(t, p.t)
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return p.val.(T), nil
+}
+
+func scopeFromContext(ctx context.Context) *Scope {
+	s, _ := ctx.Value(scopeKey{}).(*Scope)
+	return s
+}
+
+// addProviderLocked records the task as a consumer of the provider. If
+// the provider depends on the task, an error is returned, which
+// describes the cycle. It must be called with the scope's mutex held.
+func (s *Scope) addProviderLocked(t, provider *task) error {
+	if t == provider {
+		return nil
+	}
+	if path := s.taskPathLocked(provider, t, nil); path != nil {
+		names := []string{t.String()}
+		for _, p := range path {
+			names = append(names, p.String())
+		}
+		return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(names, " -> "))
+	}
+
+	t.mtx.Lock()
+	if !slices.Contains(t.providers, provider) {
+		t.providers = append(t.providers, provider)
+	}
+	t.mtx.Unlock()
+	return nil
+}
+
+// taskPathLocked returns the path of dependencies from one task to
+// another, or nil if there is none. Both the inferred providers and the
+// explicit dependencies (see Service.DependsOn) are considered. It must
+// be called with the scope's mutex held.
+func (s *Scope) taskPathLocked(from, to *task, visited map[*task]bool) []*task {
+	if from == to {
+		return []*task{to}
+	}
+	if visited[from] {
+		return nil
+	}
+	if visited == nil {
+		visited = make(map[*task]bool)
+	}
+	visited[from] = true
+
+	for _, dep := range from.dependencies(s.tasks) {
+		if path := s.taskPathLocked(dep, to, visited); path != nil {
+			return append([]*task{from}, path...)
+		}
+	}
+	return nil
+}
+
+// dependencies returns the tasks of the given ones, which the task
+// depends on, either explicitly (see Service.DependsOn) or inferred by
+// its lookups (see Get).
+func (t *task) dependencies(tasks []*task) []*task {
+	t.mtx.Lock()
+	deps := slices.Clone(t.providers)
+	t.mtx.Unlock()
+	if len(t.svc.DependsOn) == 0 {
+		return deps
+	}
+	for _, d := range tasks {
+		if d.name != "" && slices.Contains(t.svc.DependsOn, d.name) {
+			deps = append(deps, d)
+		}
+	}
+	return deps
+}
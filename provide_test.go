@@ -0,0 +1,148 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProvideGet(t *testing.T) {
+	type db struct{ name string }
+	type cache struct{}
+
+	t.Run("order", func(t *testing.T) {
+		s := newScope(t)
+
+		var (
+			mtx     sync.Mutex
+			stopped []string
+		)
+		stop := func(name string) Func {
+			return func(context.Context) error {
+				mtx.Lock()
+				stopped = append(stopped, name)
+				mtx.Unlock()
+				return nil
+			}
+		}
+
+		got := make(chan *db, 1)
+		s.Start(Service{
+			Name: "api",
+			Start: func(ctx context.Context) error {
+				v, err := Get[*db](ctx)
+				if err != nil {
+					return err
+				}
+				got <- v
+				<-ctx.Done()
+				return nil
+			},
+			Stop: stop("api"),
+		})
+		s.Start(Service{
+			Name: "db",
+			Start: func(ctx context.Context) error {
+				Provide(ctx, &db{name: "primary"})
+				<-ctx.Done()
+				return nil
+			},
+			Stop: stop("db"),
+		})
+
+		select {
+		case v := <-got:
+			if v.name != "primary" {
+				t.Fatalf("unexpected value: %+v", v)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("value not provided")
+		}
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// Without the lookup, the database would be
+		// stopped first, since it was started last.
+		if !slices.Equal(stopped, []string{"api", "db"}) {
+			t.Fatalf("unexpected stop order: %v", stopped)
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		var errs []error
+		s := New(WithErrorHandler(CollectErrors(&errs)))
+
+		s.Start(Service{
+			Name: "a",
+			Start: func(ctx context.Context) error {
+				Provide(ctx, &db{})
+				_, err := Get[cache](ctx)
+				return err
+			},
+		})
+		s.Start(Service{
+			Name: "b",
+			Start: func(ctx context.Context) error {
+				Provide(ctx, cache{})
+				_, err := Get[*db](ctx)
+				return err
+			},
+		})
+
+		if err := s.Wait(context.Background()); err == nil {
+			t.Fatal("error expected")
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(errs) != 1 || !errors.Is(errs[0], ErrDependencyCycle) {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+	})
+
+	t.Run("done", func(t *testing.T) {
+		s := newScope(t)
+		defer closeScope(s)
+
+		res := make(chan error, 1)
+		s.Go(func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+			defer cancel()
+			_, err := Get[cache](ctx)
+			res <- err
+			return nil
+		})
+		if err := <-res; err != context.DeadlineExceeded {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		s := newScope(t)
+		s.Go(func(ctx context.Context) error {
+			Provide(ctx, cache{})
+			return nil
+		})
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		s.Reset()
+		defer closeScope(s)
+
+		res := make(chan error, 1)
+		s.Go(func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+			defer cancel()
+			_, err := Get[cache](ctx)
+			res <- err
+			return nil
+		})
+		if err := <-res; err != context.DeadlineExceeded {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package scope
+
+// raceEnabled reports whether the race detector is enabled, which adds
+// allocations to the instrumented code.
+const raceEnabled = true
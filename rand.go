@@ -0,0 +1,42 @@
+package scope
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// random provides the randomness for jitter. It uses the global source
+// unless a source was configured (see WithRand).
+type random struct {
+	mtx sync.Mutex
+	rng *rand.Rand
+}
+
+func newRandom(src rand.Source) *random {
+	r := &random{}
+	if src != nil {
+		r.rng = rand.New(src)
+	}
+	return r
+}
+
+// float64 returns a pseudo-random number in [0.0,1.0).
+func (r *random) float64() float64 {
+	if r.rng == nil {
+		return rand.Float64()
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.rng.Float64()
+}
+
+// jitter randomizes the given duration by up to ±factor*d.
+func (r *random) jitter(d time.Duration, factor float64) time.Duration {
+	if factor <= 0 {
+		return d
+	}
+	delta := factor * float64(d)
+	return d + time.Duration(delta*(2*r.float64()-1))
+}
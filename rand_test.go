@@ -0,0 +1,23 @@
+package scope
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func TestWithRand(t *testing.T) {
+	a := New(WithRand(rand.NewPCG(1, 2)))
+	b := New(WithRand(rand.NewPCG(1, 2)))
+
+	for i := 0; i < 10; i++ {
+		ja := a.rand.jitter(time.Second, 0.5)
+		jb := b.rand.jitter(time.Second, 0.5)
+		if ja != jb {
+			t.Fatalf("expected reproducible jitter, got %v and %v", ja, jb)
+		}
+		if ja < 500*time.Millisecond || ja > 1500*time.Millisecond {
+			t.Fatalf("jitter out of range: %v", ja)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package scope
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+// WaitFor blocks until all services with the given names have reported
+// ready (see Service). Services, which are not registered yet, are
+// awaited as well. If one of the services fails before it is ready,
+// an error is returned. If the context is done before, the context's
+// error is returned.
+func (s *Scope) WaitFor(ctx context.Context, names ...string) error {
+	return s.await(ctx, func() (bool, error) {
+		return s.readyLocked(names)
+	})
+}
+
+// WaitReady blocks until all services, which are registered when WaitReady
+// is called, have reported ready (see Service). Deferred functions are
+// not considered. If one of the services fails before it is ready, an
+// error is returned. If the context is done before, the context's error
+// is returned.
+func (s *Scope) WaitReady(ctx context.Context) error {
+	s.mtx.Lock()
+	tasks := s.tasks
+	s.mtx.Unlock()
+
+	return s.await(ctx, func() (bool, error) {
+		for _, t := range tasks {
+			if t.deferred() {
+				continue
+			}
+			if ok, err := t.readyLocked(); err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+}
+
+// await blocks until cond reports true or an error. The condition is
+// evaluated with the scope's mutex held whenever a task changed.
+func (s *Scope) await(ctx context.Context, cond func() (bool, error)) error {
+	for {
+		s.mtx.Lock()
+		ok, err := cond()
+		if s.changed == nil {
+			s.changed = make(chan struct{})
+		}
+		changed := s.changed
+		s.mtx.Unlock()
+
+		switch {
+		case err != nil:
+			return err
+		case ok:
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Scope) readyLocked(names []string) (bool, error) {
+	ready := true
+	for _, name := range names {
+		found := false
+		for _, t := range s.tasks {
+			if t.name != name {
+				continue
+			}
+
+			found = true
+			ok, err := t.readyLocked()
+			if err != nil {
+				return false, err
+			}
+			ready = ready && ok
+		}
+		ready = ready && found
+	}
+	return ready, nil
+}
+
+// readyLocked reports whether the task is ready. If the task failed
+// before it was ready, an error is returned. It must be called with
+// the scope's mutex held.
+func (t *task) readyLocked() (bool, error) {
+	switch {
+	case t.readyErr != nil:
+		return false, fmt.Errorf("%w: service %q is not ready: %w", ErrStartupFailed, t, t.readyErr)
+	case t.state.is(failed):
+		return false, fmt.Errorf("%w: service %q failed: %w", ErrStartupFailed, t, t.err)
+	case t.state.is(skipped):
+		return false, fmt.Errorf("%w: service %q was not started", ErrStartupFailed, t)
+	default:
+		return t.ready, nil
+	}
+}
+
+// awaitReady calls the task's ready function concurrently and marks the
+// task ready as soon as it returns. The returned function aborts the
+// ready function and must be called when the task's start function
+// returned.
+func (s *Scope) awaitReady(ctx context.Context, t *task, ready Func) func() {
+	if ready == nil {
+		s.setReady(t, nil)
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.spawn(func() {
+		if err := protect(ctx, ready); ctx.Err() == nil {
+			s.setReady(t, err)
+		}
+	})
+	return cancel
+}
+
+// setReady marks the task as ready or records the error of its ready
+// function.
+func (s *Scope) setReady(t *task, err error) {
+	s.mtx.Lock()
+	if t.ready || t.readyErr != nil {
+		s.mtx.Unlock()
+		return
+	}
+	if err != nil {
+		t.readyErr = err
+	} else {
+		t.ready = true
+	}
+	s.notifyLocked()
+	s.mtx.Unlock()
+	s.updateReadiness()
+
+	if err != nil {
+		s.log(slog.LevelError, "task not ready", "task", t, "error", err)
+	} else {
+		s.log(slog.LevelDebug, "task ready", "task", t)
+	}
+}
+
+// notify wakes up all waiters for task changes and updates the
+// aggregated readiness.
+func (s *Scope) notify() {
+	s.mtx.Lock()
+	s.notifyLocked()
+	s.mtx.Unlock()
+	s.updateReadiness()
+}
+
+// notifyLocked wakes up all waiters (see await). The channel is created
+// on demand, so tasks can change without allocations if nobody waits.
+func (s *Scope) notifyLocked() {
+	if s.changed != nil {
+		close(s.changed)
+		s.changed = nil
+	}
+}
+
+// updateReadiness calls the readiness hooks (see WithReadinessHooks) if
+// the aggregated readiness changed, and propagates the change to the
+// parent scope. The scope is ready if it is not closing, all its services
+// are ready, and all its open children are ready.
+func (s *Scope) updateReadiness() {
+	if s.parent != nil {
+		defer s.parent.updateReadiness()
+	}
+	if s.opts.onReady == nil && s.opts.onUnready == nil && !s.opts.summary {
+		return
+	}
+
+	s.readyMtx.Lock()
+	defer s.readyMtx.Unlock()
+
+	_, notReady := s.notReady()
+	ready := !notReady
+	if ready == s.ready {
+		return
+	}
+	s.ready = ready
+
+	if ready {
+		s.summarizeLocked()
+	}
+	switch {
+	case ready && s.opts.onReady != nil:
+		s.opts.onReady()
+	case !ready && s.opts.onUnready != nil:
+		s.opts.onUnready()
+	}
+}
+
+// Ready reports whether the scope is ready, i.e. it is not closing, all
+// its services are ready (see Service), and all its open children are
+// ready (see Child). If the scope is not ready, the path of the first
+// component, which is not ready, is returned as well. The path consists
+// of the names of the child scopes (see WithName) and the name of the
+// service, e.g. "api/db". Unnamed children and services are identified
+// by their index, e.g. "#0". The path of a closing scope is the path of
+// the scope itself, which is empty for the scope Ready is called on.
+func (s *Scope) Ready() (bool, string) {
+	path, notReady := s.notReady()
+	return !notReady, path
+}
+
+// notReady returns the path of the first component, which is not ready.
+func (s *Scope) notReady() (string, bool) {
+	s.mtx.Lock()
+	if isClosed(s.closing) {
+		s.mtx.Unlock()
+		return "", true
+	}
+	for _, t := range s.tasks {
+		if t.deferred() {
+			continue
+		}
+		if !t.ready || t.readyErr != nil || t.state.is(failed) || t.state.is(skipped) {
+			s.mtx.Unlock()
+			return t.String(), true
+		}
+	}
+	children := s.children
+	s.mtx.Unlock()
+
+	for i, child := range children {
+		if path, ok := child.notReady(); ok {
+			name := cmp.Or(child.opts.name, "#"+strconv.Itoa(i))
+			if path != "" {
+				name += "/" + path
+			}
+			return name, true
+		}
+	}
+	return "", false
+}
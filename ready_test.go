@@ -0,0 +1,234 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScopeWaitFor(t *testing.T) {
+	t.Run("ready", func(t *testing.T) {
+		s := newScope(t)
+		ready := make(chan struct{})
+		waited := make(chan error, 1)
+		go func() { waited <- s.WaitFor(context.Background(), "db", "cache") }()
+
+		s.Start(Service{
+			Name: "db",
+			Start: func(ctx context.Context) error {
+				close(ready)
+				<-ctx.Done()
+				return nil
+			},
+			Ready: func(ctx context.Context) error {
+				<-ready
+				return nil
+			},
+		})
+		s.Go(func(context.Context) error { return nil })
+		s.Start(Service{
+			Name: "cache",
+			Start: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+		})
+
+		select {
+		case err := <-waited:
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for services")
+		}
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		s := newScope(t)
+		s.onError = func(error) {}
+
+		errStart := errors.New("start error")
+		s.Start(Service{
+			Name:  "db",
+			Start: func(context.Context) error { return errStart },
+			Ready: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		})
+
+		if err := s.WaitFor(context.Background(), "db"); !errors.Is(err, errStart) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		s := newScope(t)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		if err := s.WaitFor(ctx, "unknown"); err != context.DeadlineExceeded {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestScopeReadinessHooks(t *testing.T) {
+	events := make(chan string, 4)
+	s := New(
+		WithErrorHandler(func(err error) { t.Fatalf("unexpected error: %v", err) }),
+		WithReadinessHooks(
+			func() { events <- "ready" },
+			func() { events <- "unready" },
+		),
+	)
+
+	ready := make(chan struct{})
+	s.Start(Service{
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Ready: func(context.Context) error {
+			<-ready
+			return nil
+		},
+	})
+	s.Defer(func(context.Context) error { return nil })
+
+	close(ready)
+	if ev := <-events; ev != "ready" {
+		t.Fatalf("unexpected event: %s", ev)
+	}
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ev := <-events; ev != "unready" {
+		t.Fatalf("unexpected event: %s", ev)
+	}
+	if len(events) != 0 {
+		t.Fatalf("unexpected events: %d", len(events))
+	}
+}
+
+func TestScopeWaitReady(t *testing.T) {
+	t.Run("ready", func(t *testing.T) {
+		ready := make(chan struct{})
+		s := newScope(t)
+		s.Defer(func(context.Context) error { return nil })
+		s.Start(Service{
+			Start: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			Ready: func(ctx context.Context) error {
+				<-ready
+				return nil
+			},
+		})
+
+		waited := make(chan error, 1)
+		go func() { waited <- s.WaitReady(context.Background()) }()
+		select {
+		case err := <-waited:
+			t.Fatalf("unexpected result before ready: %v", err)
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		close(ready)
+		if err := <-waited; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		errStart := errors.New("start error")
+		s := New(WithErrorHandler(func(error) {}))
+		s.Go(func(context.Context) error { return errStart })
+
+		if err := s.WaitReady(context.Background()); !errors.Is(err, errStart) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestScopeReady(t *testing.T) {
+	events := make(chan string, 4)
+	s := New(
+		WithErrorHandler(func(err error) { t.Fatalf("unexpected error: %v", err) }),
+		WithReadinessHooks(
+			func() { events <- "ready" },
+			func() { events <- "unready" },
+		),
+	)
+	child := s.Child(WithName("api"))
+
+	ready := make(chan struct{})
+	child.Start(Service{
+		Name: "db",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Ready: func(context.Context) error {
+			<-ready
+			return nil
+		},
+	})
+	child.Defer(func(context.Context) error { return nil })
+
+	if ok, path := s.Ready(); ok || path != "api/db" {
+		t.Fatalf("unexpected readiness: %v %q", ok, path)
+	}
+	if ok, path := child.Ready(); ok || path != "db" {
+		t.Fatalf("unexpected readiness: %v %q", ok, path)
+	}
+
+	close(ready)
+	if ev := <-events; ev != "ready" {
+		t.Fatalf("unexpected event: %s", ev)
+	}
+	if ok, path := s.Ready(); !ok || path != "" {
+		t.Fatalf("unexpected readiness: %v %q", ok, path)
+	}
+
+	// A closed child is detached and does not affect the parent.
+	if err := closeScope(child); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ok, _ := s.Ready(); !ok {
+		t.Fatal("expected scope to be ready")
+	}
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ev := <-events; ev != "unready" {
+		t.Fatalf("unexpected event: %s", ev)
+	}
+	if ok, path := s.Ready(); ok || path != "" {
+		t.Fatalf("unexpected readiness: %v %q", ok, path)
+	}
+	if len(events) != 0 {
+		t.Fatalf("unexpected events: %d", len(events))
+	}
+}
@@ -0,0 +1,129 @@
+package scope
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is a lifecycle decision of a scope, which was recorded by a
+// recorder (see WithRecorder).
+type Record struct {
+	// Offset is the time since the scope was created. It is measured
+	// with the monotonic clock, so it is not affected by changes of the
+	// wall clock.
+	Offset time.Duration
+	// Kind describes the decision: "scheduled", "started", "ended",
+	// "restart", "delayed", "missed", "halted", "draining", "signal",
+	// "closing", "phase", "phase-done", "stopping", "stopped", "blocked",
+	// "timeout", or "closed".
+	Kind string
+	// Task is the task the decision was made for. It is empty for
+	// decisions of the scope itself.
+	Task string
+	// Detail holds additional attributes as space-separated key=value
+	// pairs, e.g. the delay of a restart.
+	Detail string
+}
+
+// String returns a human-readable representation of the record.
+func (r Record) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%12s %-10s", "+"+r.Offset.String(), r.Kind)
+	if r.Task != "" {
+		fmt.Fprintf(&b, " %s", r.Task)
+	}
+	if r.Detail != "" {
+		fmt.Fprintf(&b, " %s", r.Detail)
+	}
+	return b.String()
+}
+
+// ReadRecords reads the records, which were written by a recorder (see
+// WithRecorder), e.g. to pretty-print the timeline of a shutdown offline.
+func ReadRecords(r io.Reader) ([]Record, error) {
+	var records []Record
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		rec, err := parseRecord(sc.Text())
+		if err != nil {
+			return records, fmt.Errorf("scope: invalid record in line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records, sc.Err()
+}
+
+// parseRecord parses a record of the form: offset kind "task" "detail".
+func parseRecord(s string) (Record, error) {
+	offset, s, _ := strings.Cut(s, " ")
+	kind, s, _ := strings.Cut(s, " ")
+	ns, err := strconv.ParseInt(offset, 10, 64)
+	if err != nil {
+		return Record{}, err
+	}
+	if kind == "" {
+		return Record{}, errors.New("missing kind")
+	}
+
+	var fields [2]string
+	for i := range fields {
+		q, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return Record{}, err
+		}
+		fields[i], _ = strconv.Unquote(q)
+		s = strings.TrimPrefix(s[len(q):], " ")
+	}
+	return Record{Offset: time.Duration(ns), Kind: kind, Task: fields[0], Detail: fields[1]}, nil
+}
+
+// recordLog writes the lifecycle decisions of a scope in a compact,
+// line-oriented format (see ReadRecords).
+type recordLog struct {
+	created time.Time
+
+	mtx sync.Mutex
+	w   io.Writer
+	buf []byte
+}
+
+// record writes a record of the given kind. The task may be nil and the
+// attributes are key-value pairs. Write errors are ignored, since the
+// recording must not affect the lifecycle.
+func (s *Scope) record(kind string, t *task, attrs ...any) {
+	r := s.recorder
+	if r == nil {
+		return
+	}
+	offset := time.Since(r.created)
+
+	var detail []byte
+	for i := 0; i+1 < len(attrs); i += 2 {
+		if i > 0 {
+			detail = append(detail, ' ')
+		}
+		detail = fmt.Appendf(detail, "%v=%v", attrs[i], attrs[i+1])
+	}
+	var name string
+	if t != nil {
+		name = t.String()
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.buf = strconv.AppendInt(r.buf[:0], int64(offset), 10)
+	r.buf = append(r.buf, ' ')
+	r.buf = append(r.buf, kind...)
+	r.buf = append(r.buf, ' ')
+	r.buf = strconv.AppendQuote(r.buf, name)
+	r.buf = append(r.buf, ' ')
+	r.buf = strconv.AppendQuote(r.buf, string(detail))
+	r.buf = append(r.buf, '\n')
+	r.w.Write(r.buf)
+}
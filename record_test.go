@@ -0,0 +1,93 @@
+package scope
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	s := New(
+		WithErrorHandler(func(err error) { t.Fatalf("unexpected error: %v", err) }),
+		WithRecorder(&buf),
+	)
+
+	errStop := errors.New("stop error")
+	s.Start(Service{
+		Name: "db server",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Stop: func(context.Context) error { return errStop },
+	})
+	if err := s.WaitFor(context.Background(), "db server"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := closeScope(s); !errors.Is(err, errStop) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	records, err := ReadRecords(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var kinds []string
+	for i, r := range records {
+		kinds = append(kinds, r.Kind)
+		if i > 0 && r.Offset < records[i-1].Offset {
+			t.Fatalf("unexpected offsets: %v", records)
+		}
+	}
+	expected := []string{
+		"scheduled", "started",
+		"closing",
+		"phase", "stopping", "stopped", "phase-done",
+		"phase", "ended", "phase-done",
+		"closed",
+	}
+	if !slices.Equal(kinds, expected) {
+		t.Fatalf("unexpected records:\n%s", formatRecords(records))
+	}
+
+	stopped := records[5]
+	if stopped.Task != "db server" || !strings.Contains(stopped.Detail, "error=stop error") {
+		t.Fatalf("unexpected record: %v", stopped)
+	}
+	if s := stopped.String(); !strings.Contains(s, "stopped    db server duration=") {
+		t.Fatalf("unexpected string: %s", s)
+	}
+}
+
+func TestReadRecords(t *testing.T) {
+	records, err := ReadRecords(strings.NewReader("1500 restart \"db\" \"attempt=1 delay=1s\"\n2000000 closed \"\" \"\"\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Record{
+		{Offset: 1500, Kind: "restart", Task: "db", Detail: "attempt=1 delay=1s"},
+		{Offset: 2 * time.Millisecond, Kind: "closed"},
+	}
+	if !slices.Equal(records, expected) {
+		t.Fatalf("unexpected records: %v", records)
+	}
+
+	if _, err := ReadRecords(strings.NewReader("1500 restart db\n")); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func formatRecords(records []Record) string {
+	var b strings.Builder
+	for _, r := range records {
+		b.WriteString(r.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package scope
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// RegionInfo describes a region of a task (see Scope.Region).
+type RegionInfo struct {
+	Name    string    // name of the region, nested regions are separated by '/'
+	Started time.Time // time when the region began
+	Ended   time.Time // time when the region ended, zero if still active
+}
+
+// Duration returns the duration of the region. For active regions the
+// time elapsed so far is returned.
+func (r RegionInfo) Duration() time.Duration {
+	if r.Ended.IsZero() {
+		return time.Since(r.Started)
+	}
+	return r.Ended.Sub(r.Started)
+}
+
+type regionKey struct{}
+
+// Region marks the beginning of a named sub-section of the task, which
+// owns the given context. The returned function marks its end and must
+// be called exactly once. Regions are timed, logged, passed to the
+// instrumentors (see RegionInstrumentor), and are part of the task's
+// info. Regions can be nested by passing the returned context to Region.
+//
+//	ctx, end := s.Region(ctx, "load-index")
+//	defer end()
+func (s *Scope) Region(ctx context.Context, name string) (context.Context, func()) {
+	if parent, ok := ctx.Value(regionKey{}).(string); ok {
+		name = parent + "/" + name
+	}
+
+	t := taskFromContext(ctx)
+	r := RegionInfo{Name: name, Started: time.Now()}
+	info := TaskInfo{Index: -1}
+	var (
+		idx  int
+		attr = slog.Any("task", t)
+	)
+	if t != nil {
+		t.mtx.Lock()
+		if n := s.opts.retain; n > 0 && len(t.regions) >= n {
+			drop := len(t.regions) - n + 1
+			t.regions = slices.Delete(t.regions, 0, drop)
+			t.regionsOff += drop
+		}
+		idx = t.regionsOff + len(t.regions)
+		t.regions = append(t.regions, r)
+		t.mtx.Unlock()
+		info = t.info()
+	}
+
+	s.log(slog.LevelDebug, "region started", attr, "region", name)
+	ctx = s.opts.instruments.regionStarted(context.WithValue(ctx, regionKey{}, name), info, name)
+	return ctx, func() {
+		r.Ended = time.Now()
+		if t != nil {
+			t.mtx.Lock()
+			if i := idx - t.regionsOff; i >= 0 {
+				t.regions[i] = r
+			}
+			t.mtx.Unlock()
+			info = t.info()
+		}
+
+		s.log(slog.LevelDebug, "region ended", attr, "region", name, "duration", r.Duration())
+		s.opts.instruments.regionEnded(ctx, info, r)
+	}
+}
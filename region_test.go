@@ -0,0 +1,61 @@
+package scope
+
+import (
+	"context"
+	"testing"
+)
+
+func TestScopeRegion(t *testing.T) {
+	var rec regionRecorder
+	s := New(
+		WithErrorHandler(func(err error) { t.Fatalf("unexpected error: %v", err) }),
+		WithInstrumentor(&rec),
+	)
+
+	done := make(chan struct{})
+	s.Go(func(ctx context.Context) error {
+		defer close(done)
+
+		ctx, end := s.Region(ctx, "load")
+		_, endParse := s.Region(ctx, "parse")
+		endParse()
+		end()
+		return nil
+	})
+	<-done
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	regions := rec.info.Regions
+	switch {
+	case len(regions) != 2:
+		t.Fatalf("unexpected number of regions: %d", len(regions))
+	case regions[0].Name != "load" || regions[1].Name != "load/parse":
+		t.Fatalf("unexpected regions: %+v", regions)
+	case regions[0].Ended.IsZero() || regions[1].Ended.IsZero():
+		t.Fatalf("expected regions to be ended: %+v", regions)
+	}
+	if rec.ended != 2 {
+		t.Fatalf("unexpected number of ended regions: %d", rec.ended)
+	}
+}
+
+type regionRecorder struct {
+	NopInstrumentor
+	info  TaskInfo
+	ended int
+}
+
+func (r *regionRecorder) TaskEnded(_ context.Context, t TaskInfo, _ error) {
+	r.info = t
+}
+
+func (r *regionRecorder) RegionStarted(ctx context.Context, _ TaskInfo, _ string) context.Context {
+	return ctx
+}
+
+func (r *regionRecorder) RegionEnded(context.Context, TaskInfo, RegionInfo) {
+	r.ended++
+}
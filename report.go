@@ -0,0 +1,104 @@
+package scope
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ShutdownReport describes the shutdown of a scope. It is available
+// after the scope was closed (see Scope.ShutdownReport).
+type ShutdownReport struct {
+	Started   time.Time  // time when Close was called
+	Cancelled time.Time  // time when the scope's context was cancelled
+	Finished  time.Time  // time when all functions have completed
+	Cause     CloseCause // reason why the scope was closed (see Cause)
+	Phases    []PhaseReport
+	Tasks     []TaskReport
+}
+
+// Slow returns the reports of all tasks, which exceeded the configured
+// cancellation latency threshold (see WithCancelLatencyThreshold).
+func (r ShutdownReport) Slow() []TaskReport {
+	var slow []TaskReport
+	for _, t := range r.Tasks {
+		if t.Slow {
+			slow = append(slow, t)
+		}
+	}
+	return slow
+}
+
+// TaskReport describes how a single task behaved during the shutdown
+// of its scope.
+type TaskReport struct {
+	// Index is the position of the task in registration order.
+	Index int
+	// Name is the name of the task's service (if any).
+	Name string
+	// Exited is the time when the task's start function returned.
+	Exited time.Time
+	// CancelLatency is the time it took the task to exit after the
+	// scope's context was cancelled. It is zero if the task exited
+	// before the cancellation.
+	CancelLatency time.Duration
+	// Slow reports whether the cancellation latency exceeded the
+	// configured threshold.
+	Slow bool
+	// Stages holds the stages of the task's last stop function in
+	// order of execution (see StopStage).
+	Stages []PhaseReport
+}
+
+// The phases of a shutdown in order of execution (see PhaseReport).
+const (
+	PhaseSoftCancel = "soft-cancel" // waiting for tasks to finish (see WithSoftCancel)
+	PhaseChildren   = "children"    // closing the child scopes (see Scope.Child)
+	PhaseStop       = "stop"        // calling the stop functions
+	PhaseCancel     = "cancel"      // waiting for the start functions after the cancellation
+	PhaseFinally    = "finally"     // calling the final functions (see Scope.Finally)
+)
+
+// PhaseReport describes a completed phase of a shutdown. Phases, which
+// have nothing to do, are skipped.
+type PhaseReport struct {
+	Name     string
+	Started  time.Time
+	Duration time.Duration
+}
+
+// StopStage starts a stage of the stop function, which was called with
+// the given context, e.g. draining the in-flight requests of a server.
+// The returned function ends the stage and adds it to the task's report
+// of the shutdown (see TaskReport). Outside of a stop function, the
+// stage is not recorded.
+func StopStage(ctx context.Context, name string) func() {
+	t := taskFromContext(ctx)
+	if t == nil {
+		return func() {}
+	}
+
+	started := time.Now()
+	return func() {
+		p := PhaseReport{Name: name, Started: started, Duration: time.Since(started)}
+		t.mtx.Lock()
+		t.stages = append(t.stages, p)
+		t.mtx.Unlock()
+	}
+}
+
+// phase starts a phase of the shutdown. The returned function ends the
+// phase, adds it to the report, and calls the phase hook.
+func (s *Scope) phase(r *ShutdownReport, name string) func() {
+	started := time.Now()
+	s.record("phase", nil, "name", name)
+	return func() {
+		p := PhaseReport{Name: name, Started: started, Duration: time.Since(started)}
+		r.Phases = append(r.Phases, p)
+		s.record("phase-done", nil, "name", name, "duration", p.Duration)
+		s.log(slog.LevelInfo, "phase completed", "phase", name, "duration", p.Duration)
+		if s.opts.phaseHook != nil {
+			s.opts.phaseHook(p)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// RollingRestart restarts the services with the given names, at most
+// maxUnavailable services at a time. A service is restarted by calling
+// its stop function, cancelling its context with the cause Restarted,
+// and starting it again once its start function returned. The next
+// services are restarted when the restarted ones are ready (see Service).
+// If a service fails to restart, RollingRestart stops and returns the
+// error. A maxUnavailable of zero or less restarts one service at a time.
+func (s *Scope) RollingRestart(ctx context.Context, names []string, maxUnavailable int) error {
+	tasks, err := s.lookup(names)
+	if err != nil {
+		return err
+	}
+	maxUnavailable = max(maxUnavailable, 1)
+
+	for len(tasks) > 0 {
+		batch := tasks[:min(maxUnavailable, len(tasks))]
+		tasks = tasks[len(batch):]
+
+		errs := make(chan error, len(batch))
+		for _, t := range batch {
+			s.spawn(func() { errs <- s.restart(ctx, t) })
+		}
+
+		var batchErrs Errors
+		for range batch {
+			batchErrs.append(<-errs)
+		}
+		if err := batchErrs.err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookup returns the started services with the given names.
+func (s *Scope) lookup(names []string) ([]*task, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var tasks []*task
+	for _, name := range names {
+		found := false
+		for _, t := range s.tasks {
+			if t.name == name && t.done != nil {
+				tasks = append(tasks, t)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("scope: unknown service %q", name)
+		}
+	}
+	return tasks, nil
+}
+
+func (s *Scope) restart(ctx context.Context, t *task) error {
+	if t.state.is(paused) {
+		return fmt.Errorf("scope: cannot restart paused service %q", t)
+	}
+
+	s.log(slog.LevelInfo, "restarting task", "task", t)
+	if err := s.halt(ctx, t, "restart", Restarted{}); err != nil {
+		return err
+	}
+	return s.relaunch(ctx, t, "restart")
+}
+
+// halt ends the current run of the task: its stop function is called, its
+// context is cancelled with the given cause, and halt waits until its
+// start function returned. The task is not ready afterwards.
+func (s *Scope) halt(ctx context.Context, t *task, op string, cause CloseCause) error {
+	s.mtx.Lock()
+	if isClosed(s.closing) {
+		s.mtx.Unlock()
+		return fmt.Errorf("scope: cannot %s service %q of a closing scope", op, t)
+	}
+	done, cancel := t.done, t.cancel
+	s.record("halted", t, "op", op, "cause", CauseLabel(cause))
+	t.ready, t.readyErr = false, nil
+	s.notifyLocked()
+	s.mtx.Unlock()
+	s.updateReadiness()
+
+	if t.stop != nil && t.state.is(running) {
+		if err := s.stopTask(ctx, t); err != nil {
+			return &TaskError{Task: t.info(), Err: err}
+		}
+	}
+	cancel(cause)
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		if cause == (Restarted{}) {
+			// The task is not restarted anymore.
+			go func() {
+				<-done
+				t.finish()
+			}()
+		}
+		return ctx.Err()
+	}
+}
+
+// relaunch starts the halted task again and waits until it is ready.
+func (s *Scope) relaunch(ctx context.Context, t *task, op string) error {
+	// The previous run may have marked the task ready
+	// when it returned.
+	s.mtx.Lock()
+	closing := isClosed(s.closing)
+	t.ready, t.readyErr = false, nil
+	s.mtx.Unlock()
+	if closing {
+		t.finish()
+		return fmt.Errorf("scope: cannot %s service %q of a closing scope", op, t)
+	}
+
+	if !s.launch(t) {
+		return fmt.Errorf("scope: cannot %s service %q of a closing scope", op, t)
+	}
+	return s.await(ctx, t.readyLocked)
+}
@@ -0,0 +1,80 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestScopeRollingRestart(t *testing.T) {
+	var (
+		mtx    sync.Mutex
+		events []string
+		starts = map[string]int{}
+	)
+	record := func(ev string) {
+		mtx.Lock()
+		events = append(events, ev)
+		mtx.Unlock()
+	}
+
+	s := newScope(t)
+	for _, name := range []string{"a", "b", "c"} {
+		s.Start(Service{
+			Name: name,
+			Start: func(ctx context.Context) error {
+				mtx.Lock()
+				starts[name]++
+				mtx.Unlock()
+
+				<-ctx.Done()
+				return nil
+			},
+			Stop: func(context.Context) error {
+				record("stop " + name)
+				return nil
+			},
+			Ready: func(context.Context) error {
+				record("ready " + name)
+				return nil
+			},
+		})
+	}
+	if err := s.WaitFor(context.Background(), "a", "b", "c"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mtx.Lock()
+	events = nil
+	mtx.Unlock()
+	if err := s.RollingRestart(context.Background(), []string{"a", "b", "c"}, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mtx.Lock()
+	expected := "[stop a ready a stop b ready b stop c ready c]"
+	if got := fmt.Sprint(events); got != expected {
+		t.Fatalf("unexpected events: %s", got)
+	}
+	for name, n := range starts {
+		if n != 2 {
+			t.Fatalf("unexpected number of starts for %s: %d", name, n)
+		}
+	}
+	mtx.Unlock()
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestScopeRollingRestartUnknown(t *testing.T) {
+	s := newScope(t)
+	if err := s.RollingRestart(context.Background(), []string{"unknown"}, 1); err == nil {
+		t.Fatal("expected error")
+	}
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
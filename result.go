@@ -0,0 +1,44 @@
+package scope
+
+import "context"
+
+// Result is a handle of a function started by GoResult, which allows to
+// await the function's value.
+type Result[T any] struct {
+	task *Task
+	val  T // written by the task's goroutine before it is done
+}
+
+// GoResult runs the given function in a new Goroutine like Scope.Go and
+// returns a handle to await its value. This allows to use the scope for
+// fan-out computations. Errors of the function are still reported by the
+// error handler of the scope (see WithErrorHandler).
+func GoResult[T any](s *Scope, f func(context.Context) (T, error), opts ...StartOption) *Result[T] {
+	r := &Result[T]{}
+	r.task = s.Go(func(ctx context.Context) error {
+		v, err := f(ctx)
+		r.val = v
+		return err
+	}, opts...)
+	return r
+}
+
+// Done returns a channel, which is closed when the function returned or
+// will never be called (see Task.Done).
+func (r *Result[T]) Done() <-chan struct{} {
+	return r.task.Done()
+}
+
+// Get blocks until the function returned and returns its value and
+// error. If the function was never called, the zero value and
+// ErrNotStarted are returned. If the context is done before, the zero
+// value and the context's error are returned.
+func (r *Result[T]) Get(ctx context.Context) (T, error) {
+	select {
+	case <-r.Done():
+		return r.val, r.task.Err()
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
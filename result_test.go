@@ -0,0 +1,75 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGoResult(t *testing.T) {
+	t.Run("fan-out", func(t *testing.T) {
+		s := newScope(t)
+		defer closeScope(s)
+
+		results := make([]*Result[int], 10)
+		for i := range results {
+			results[i] = GoResult(s, func(context.Context) (int, error) {
+				return i * i, nil
+			})
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		sum := 0
+		for _, r := range results {
+			v, err := r.Get(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			sum += v
+		}
+		if sum != 285 {
+			t.Fatalf("unexpected sum: %d", sum)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var errs []error
+		s := New(WithErrorHandler(CollectErrors(&errs)))
+
+		errCompute := errors.New("compute error")
+		r := GoResult(s, func(context.Context) (string, error) {
+			return "partial", errCompute
+		})
+
+		v, err := r.Get(context.Background())
+		if err != errCompute || v != "partial" {
+			t.Fatalf("unexpected result: %q, %v", v, err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(errs) != 1 || errs[0] != errCompute {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+	})
+
+	t.Run("context-done", func(t *testing.T) {
+		s := newScope(t)
+		r := GoResult(s, func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+			return 1, nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if v, err := r.Get(ctx); err != context.Canceled || v != 0 {
+			t.Fatalf("unexpected result: %d, %v", v, err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
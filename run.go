@@ -0,0 +1,69 @@
+package scope
+
+import (
+	"context"
+	"log/slog"
+	"os"
+)
+
+// RunUntilSignal runs the typical lifecycle of a worker binary: it creates
+// a new scope with the given options, calls setup to register the scope's
+// functions, and waits until SIGINT or SIGTERM is received or the scope's
+// context is done (e.g. see WithMaxLifetime and Scope.CloseOn). Afterwards
+// the scope is closed. The returned error combines the errors of setup
+// and Close.
+//
+// If another signal is received while the scope is closing, the shutdown
+// is forced: all contexts are cancelled and RunUntilSignal returns
+// immediately with a *ShutdownTimeoutError, which lists the tasks that
+// did not finish and holds the second signal as SignalReceived. A
+// shutdown timeout (see WithShutdownTimeout) forces the shutdown as well.
+//
+// As long as the scope's context is not done, the signals of the scope's
+// signal policy are handled by the scope instead (see WithSignalPolicy).
+func RunUntilSignal(setup func(*Scope) error, opts ...Option) error {
+	sigs, stop := SignalChan()
+	defer stop()
+	return RunUntil(sigs, setup, opts...)
+}
+
+// RunUntil is like RunUntilSignal, but receives the signals from the given
+// channel instead of the operating system. This allows to shut down on
+// custom signals (see SignalChan) or to inject signals in tests.
+func RunUntil(sigs <-chan os.Signal, setup func(*Scope) error, opts ...Option) error {
+	s := New(opts...)
+	var errs Errors
+	if err := setup(s); err != nil {
+		errs.append(err)
+		errs.append(s.Close())
+		return errs.err()
+	}
+
+wait:
+	for {
+		select {
+		case sig := <-sigs:
+			if _, ok := s.opts.signals[sig]; ok {
+				// The signal is handled by the scope's
+				// signal policy (see WithSignalPolicy).
+				continue
+			}
+			s.setCloseCause(SignalReceived{Signal: sig})
+			break wait
+		case <-s.Ctx().Done():
+			break wait
+		}
+	}
+
+	ctx, force := context.WithCancelCause(context.Background())
+	defer force(nil)
+	go func() {
+		select {
+		case sig := <-sigs:
+			s.log(slog.LevelWarn, "forcing shutdown", "signal", sig)
+			force(SignalReceived{Signal: sig})
+		case <-ctx.Done():
+		}
+	}()
+	return s.CloseContext(ctx)
+}
@@ -0,0 +1,98 @@
+//go:build unix
+
+package scope
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRunUntilSignal(t *testing.T) {
+	t.Run("signal", func(t *testing.T) {
+		stopped := newCall(nil)
+		err := RunUntilSignal(func(s *Scope) error {
+			s.Defer(stopped.f)
+			return syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !stopped.called() {
+			t.Fatal("expected deferred function to be called")
+		}
+	})
+
+	t.Run("setup-error", func(t *testing.T) {
+		errSetup := errors.New("setup error")
+		stopped := newCall(nil)
+		err := RunUntilSignal(func(s *Scope) error {
+			s.Defer(stopped.f)
+			return errSetup
+		})
+		if err == nil || err.Error() != errSetup.Error() {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !stopped.called() {
+			t.Fatal("expected deferred function to be called")
+		}
+	})
+
+	t.Run("context-done", func(t *testing.T) {
+		err := RunUntilSignal(func(s *Scope) error {
+			s.Go(func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			})
+			return nil
+		}, WithMaxLifetime(time.Millisecond))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("second-signal", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		stopping := make(chan struct{})
+		done := make(chan error, 1)
+		go func() {
+			done <- RunUntilSignal(func(s *Scope) error {
+				s.Start(Service{
+					Name: "stuck",
+					Start: func(ctx context.Context) error {
+						<-ctx.Done()
+						return nil
+					},
+					Stop: func(context.Context) error {
+						close(stopping)
+						<-release
+						return nil
+					},
+				})
+				return syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+			})
+		}()
+
+		<-stopping
+		if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var te *ShutdownTimeoutError
+		err := <-done
+		if !errors.As(err, &te) || len(te.Tasks) != 1 || te.Tasks[0].Name != "stuck" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c, ok := te.Err.(SignalReceived); !ok || c.Signal != syscall.SIGTERM {
+			t.Fatalf("unexpected cause: %v", te.Err)
+		}
+		var se SignalError
+		if !errors.Is(err, ErrShutdownTimeout) || !errors.As(err, &se) || se.Signal != syscall.SIGTERM {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
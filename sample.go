@@ -0,0 +1,87 @@
+package scope
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ReportEvery samples the errors of the tasks started with the returned
+// option: only every n-th error is reported by the error handler. The
+// reported error is of type *SampledError and holds the number of
+// suppressed errors. The counter is shared by all tasks started with the
+// same option value, so it can be reused for recurring tasks like
+// best-effort cache refreshes.
+func ReportEvery(n int) StartOption {
+	smp := &sampler{every: n}
+	return func(o *taskOptions) {
+		if n <= 0 {
+			panic("scope options: invalid report interval")
+		}
+		o.sampler = smp
+	}
+}
+
+// ReportSampled samples the errors of the tasks started with the returned
+// option: each error is reported with the given probability using the
+// scope's source of randomness (see WithRand). Like ReportEvery, the
+// reported errors are of type *SampledError and the option value can be
+// shared by multiple tasks.
+func ReportSampled(p float64) StartOption {
+	smp := &sampler{prob: p}
+	return func(o *taskOptions) {
+		if p <= 0 || p > 1 {
+			panic("scope options: invalid report probability")
+		}
+		o.sampler = smp
+	}
+}
+
+// SampledError is the error, which is reported for tasks with sampled
+// errors (see ReportEvery and ReportSampled).
+type SampledError struct {
+	Err        error // the reported error
+	Suppressed int   // number of errors suppressed since the last report
+}
+
+func (e *SampledError) Error() string {
+	if e.Suppressed == 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%v (%d similar errors suppressed)", e.Err, e.Suppressed)
+}
+
+// Unwrap returns the reported error.
+func (e *SampledError) Unwrap() error {
+	return e.Err
+}
+
+type sampler struct {
+	every int
+	prob  float64
+
+	mtx        sync.Mutex
+	count      int
+	suppressed int
+}
+
+// sample returns the error to report or nil if the error is suppressed.
+func (smp *sampler) sample(err error, rand *random) error {
+	smp.mtx.Lock()
+	defer smp.mtx.Unlock()
+
+	smp.count++
+	var report bool
+	if smp.every > 0 {
+		report = smp.count%smp.every == 0
+	} else {
+		report = rand.float64() < smp.prob
+	}
+	if !report {
+		smp.suppressed++
+		return nil
+	}
+
+	err = &SampledError{Err: err, Suppressed: smp.suppressed}
+	smp.suppressed = 0
+	return err
+}
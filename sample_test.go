@@ -0,0 +1,57 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestReportEvery(t *testing.T) {
+	var errs []error
+	s := New(WithErrorHandler(CollectErrors(&errs)))
+
+	errRefresh := errors.New("refresh error")
+	sampled := ReportEvery(3)
+	for i := 0; i < 7; i++ {
+		done := make(chan struct{})
+		s.Go(func(context.Context) error {
+			defer close(done)
+			return errRefresh
+		}, sampled)
+		<-done
+	}
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("unexpected number of errors: %d", len(errs))
+	}
+	for _, err := range errs {
+		var se *SampledError
+		if !errors.As(err, &se) || se.Suppressed != 2 || !errors.Is(err, errRefresh) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestReportSampled(t *testing.T) {
+	var smp sampler
+	smp.prob = 0.5
+	r := newRandom(rand.NewPCG(1, 2))
+
+	var reported, suppressed int
+	for i := 0; i < 1000; i++ {
+		if err := smp.sample(errors.New("error"), r); err != nil {
+			reported++
+			suppressed += err.(*SampledError).Suppressed
+		}
+	}
+	if reported < 400 || reported > 600 {
+		t.Fatalf("unexpected number of reported errors: %d", reported)
+	}
+	if reported+suppressed+smp.suppressed != 1000 {
+		t.Fatalf("unexpected number of suppressed errors: %d", suppressed)
+	}
+}
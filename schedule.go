@@ -0,0 +1,188 @@
+package scope
+
+import (
+	"cmp"
+	"context"
+	"time"
+)
+
+// Every runs f periodically in a new Goroutine, first after the given
+// interval. The ticking stops when the scope is closed. Errors of f are
+// reported by the error handler and the ticking continues, unless the
+// task was started with StopOnError, in which case the task fails with
+// the first error. Ticks are dropped while f is still running, so slow
+// functions do not pile up. The returned handle reports when the ticking
+// stopped (see Task). With Persist, the start of each run is stored, so
+// a run missed while the process was down is detected on the next start.
+func (s *Scope) Every(interval time.Duration, f Func, opts ...StartOption) *Task {
+	if interval <= 0 {
+		panic("scope: invalid interval")
+	}
+	return s.Go(func(ctx context.Context) error {
+		if s.missedRun(ctx, func(t time.Time) time.Time { return t.Add(interval) }) {
+			if err := s.tick(ctx, f); err != nil {
+				return err
+			}
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+			if err := s.tick(ctx, f); err != nil {
+				return err
+			}
+		}
+	}, opts...)
+}
+
+// After runs f in a new Goroutine once the given duration elapsed. If
+// the scope is closed before, f is never called. Errors of f are reported
+// by the error handler.
+func (s *Scope) After(d time.Duration, f Func, opts ...StartOption) *Task {
+	return s.Go(func(ctx context.Context) error {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+			return f(ctx)
+		}
+	}, opts...)
+}
+
+// Schedule runs f in a new Goroutine according to the given cron
+// expression, e.g. "0 3 * * *" for 03:00 every day. The expression has
+// the five fields minute, hour, day of month, month, and day of week,
+// which may hold values, ranges ("1-5"), lists ("1,15"), steps ("*/10"),
+// and names ("jan", "mon"). The descriptors "@yearly", "@monthly",
+// "@weekly", "@daily", and "@hourly" are supported as well.
+//
+// The schedule is evaluated in the local time zone, unless another one is
+// defined with In. Times, which do not exist in the time zone because of
+// a daylight saving time change, are skipped, and times, which occur
+// twice, match both times. If f is still running when the next run is
+// due, the run is skipped by default (see Overlap). Errors are handled
+// like those of Every. The scheduling stops when the scope is closed. An
+// error is returned if the expression is invalid.
+func (s *Scope) Schedule(spec string, f Func, opts ...StartOption) (*Task, error) {
+	var o taskOptions
+	for _, apply := range s.opts.defaults {
+		apply(&o)
+	}
+	for _, apply := range opts {
+		apply(&o)
+	}
+	sched, err := parseCron(spec, cmp.Or(o.location, time.Local))
+	if err != nil {
+		return nil, err
+	}
+	return s.Go(func(ctx context.Context) error {
+		return s.runSchedule(ctx, sched, f)
+	}, opts...), nil
+}
+
+// runSchedule calls f at the times of the given schedule until the
+// context is done.
+func (s *Scope) runSchedule(ctx context.Context, sched schedule, f Func) error {
+	queue := taskFromContext(ctx).opts.overlap == QueueOverlap
+	next := sched.next(time.Now())
+	if s.missedRun(ctx, sched.next) {
+		next = time.Now()
+	}
+	for !next.IsZero() {
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		if err := s.tick(ctx, f); err != nil {
+			return err
+		}
+
+		if queue {
+			// Runs, which were due while f was running,
+			// follow immediately.
+			next = sched.next(next)
+		} else {
+			next = sched.next(time.Now())
+		}
+	}
+	return nil
+}
+
+// schedule is a sequence of times (see Scope.Schedule).
+type schedule interface {
+	// next returns the first time after t, or the zero time if
+	// there is none.
+	next(t time.Time) time.Time
+}
+
+// OverlapPolicy defines what happens if a scheduled function is still
+// running when its next run is due (see Scope.Schedule).
+type OverlapPolicy int
+
+const (
+	// SkipOverlap skips the runs, which were due while the function was
+	// running.
+	SkipOverlap OverlapPolicy = iota
+	// QueueOverlap starts the runs, which were due while the function
+	// was running, one after another as soon as it returned.
+	QueueOverlap
+)
+
+// Overlap defines the overlap policy of a scheduled task (see
+// Scope.Schedule). By default, overlapping runs are skipped.
+func Overlap(p OverlapPolicy) StartOption {
+	return func(o *taskOptions) {
+		if p != SkipOverlap && p != QueueOverlap {
+			panic("scope options: invalid overlap policy")
+		}
+		o.overlap = p
+	}
+}
+
+// In defines the time zone, in which the schedule of a scheduled task is
+// evaluated (see Scope.Schedule).
+func In(loc *time.Location) StartOption {
+	return func(o *taskOptions) {
+		if loc == nil {
+			panic("scope options: no location specified")
+		}
+		o.location = loc
+	}
+}
+
+// StopOnError stops a periodic task after the first error of its function
+// (see Scope.Every). By default, the error is reported and the task keeps
+// running.
+func StopOnError() StartOption {
+	return func(o *taskOptions) {
+		o.stopOnError = true
+	}
+}
+
+// tick calls the function of a periodic task once. The error is returned
+// if the task stops on errors, otherwise it is reported right away.
+func (s *Scope) tick(ctx context.Context, f Func) error {
+	t := taskFromContext(ctx)
+	started := time.Now()
+	s.saveRun(t, started)
+	err := protect(ctx, f)
+	switch {
+	case err == nil:
+		return nil
+	case t.opts.stopOnError:
+		return err
+	default:
+		s.reportError(t, err, time.Since(started))
+		return nil
+	}
+}
@@ -0,0 +1,244 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScopeEvery(t *testing.T) {
+	t.Run("keep-ticking", func(t *testing.T) {
+		var errs []error
+		s := New(WithErrorHandler(CollectErrors(&errs)))
+
+		var ticks atomic.Int32
+		done := make(chan struct{})
+		errTick := errors.New("tick error")
+		s.Every(time.Millisecond, func(context.Context) error {
+			switch ticks.Add(1) {
+			case 1:
+				return errTick
+			case 3:
+				close(done)
+			}
+			return nil
+		})
+
+		<-done
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(errs) != 1 || errs[0] != errTick {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+	})
+
+	t.Run("stop-on-error", func(t *testing.T) {
+		var errs []error
+		s := New(WithErrorHandler(CollectErrors(&errs)))
+
+		var ticks atomic.Int32
+		errTick := errors.New("tick error")
+		task := s.Every(time.Millisecond, func(context.Context) error {
+			ticks.Add(1)
+			return errTick
+		}, StopOnError())
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := task.Wait(ctx); err != errTick {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := ticks.Load(); n != 1 {
+			t.Fatalf("unexpected number of ticks: %d", n)
+		}
+	})
+
+	t.Run("persist", func(t *testing.T) {
+		s := newScope(t)
+		store := &memScheduleStore{}
+		ran := make(chan struct{}, 1)
+		s.Every(time.Millisecond, func(context.Context) error {
+			select {
+			case ran <- struct{}{}:
+			default:
+			}
+			return nil
+		}, Persist(store, "job"))
+
+		<-ran
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if last, _ := store.Load("job"); last.IsZero() {
+			t.Fatal("expected the last run to be saved")
+		}
+	})
+
+	t.Run("catch-up", func(t *testing.T) {
+		s := newScope(t)
+		store := &memScheduleStore{}
+		store.Save("job", time.Now().Add(-2*time.Hour))
+
+		called := newCall(nil)
+		s.Every(time.Hour, called.f, Persist(store, "job"), CatchUp())
+		if err := called.wait(time.Second); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestScopeAfter(t *testing.T) {
+	t.Run("elapsed", func(t *testing.T) {
+		s := newScope(t)
+		called := newCall(nil)
+		s.After(time.Millisecond, called.f)
+		if err := called.wait(time.Second); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		s := newScope(t)
+		called := newCall(nil)
+		s.After(time.Hour, called.f)
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if called.called() {
+			t.Fatal("expected function not to be called")
+		}
+	})
+}
+
+func TestScopeSchedule(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		s := newScope(t)
+		defer closeScope(s)
+		if _, err := s.Schedule("* * *", func(context.Context) error { return nil }); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		s := newScope(t)
+		called := newCall(nil)
+		task, err := s.Schedule("@yearly", called.f, In(time.UTC))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		<-task.Done()
+		if called.called() {
+			t.Fatal("expected function not to be called")
+		}
+	})
+
+	for _, test := range []struct {
+		name   string
+		policy OverlapPolicy
+		runs   int32
+	}{
+		{"skip-overlap", SkipOverlap, 2},
+		{"queue-overlap", QueueOverlap, 4},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			s := newScope(t)
+
+			// The schedule is due every millisecond for 4 milliseconds,
+			// while the first run takes 3 milliseconds. The schedule
+			// starts when it is first queried, since the task may not
+			// run immediately.
+			var start time.Time
+			sched := scheduleFunc(func(t time.Time) time.Time {
+				if start.IsZero() {
+					start = t
+				}
+				next := t.Truncate(time.Millisecond).Add(time.Millisecond)
+				if next.Sub(start) > 4*time.Millisecond {
+					return time.Time{}
+				}
+				return next
+			})
+
+			var runs atomic.Int32
+			task := s.Go(func(ctx context.Context) error {
+				return s.runSchedule(ctx, sched, func(context.Context) error {
+					if runs.Add(1) == 1 {
+						time.Sleep(3 * time.Millisecond)
+					}
+					return nil
+				})
+			}, Overlap(test.policy))
+
+			<-task.Done()
+			if n := runs.Load(); test.policy == QueueOverlap && n != test.runs || n > test.runs {
+				t.Fatalf("unexpected number of runs: %d", n)
+			}
+			if err := closeScope(s); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	for _, test := range []struct {
+		name    string
+		last    time.Duration
+		catchUp bool
+	}{
+		{"not-missed", 0, true},
+		{"missed", -2 * time.Hour, false},
+		{"catch-up", -2 * time.Hour, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			s := newScope(t)
+			store := &memScheduleStore{}
+			store.Save("job", time.Now().Add(test.last))
+
+			opts := []StartOption{Persist(store, "job"), In(time.UTC)}
+			if test.catchUp {
+				opts = append(opts, CatchUp())
+			}
+			called := make(chan struct{})
+			_, err := s.Schedule("@hourly", func(context.Context) error {
+				close(called)
+				return nil
+			}, opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			madeUp := test.catchUp && test.last < 0
+			select {
+			case <-called:
+				if !madeUp {
+					t.Fatal("unexpected run")
+				}
+			case <-time.After(20 * time.Millisecond):
+				if madeUp {
+					t.Fatal("missed run not made up")
+				}
+			}
+			if err := closeScope(s); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+type scheduleFunc func(time.Time) time.Time
+
+func (f scheduleFunc) next(t time.Time) time.Time { return f(t) }
@@ -1,118 +1,1135 @@
 package scope
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime/pprof"
+	"slices"
+	"strconv"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
+// ErrClosed is reported when a function is registered after the scope
+// was closed (see Scope.IsClosed).
+var ErrClosed = errors.New("scope: scope is closed")
+
+// ErrContextDone is returned when a scope is created with a base context,
+// which is already done (see RejectDoneContext).
+var ErrContextDone = errors.New("scope: base context is done")
+
+// ErrNoStartFunc is reported when a service without a Start function
+// is started (see Scope.Start).
+var ErrNoStartFunc = errors.New("scope: service has no start function")
+
 // Func represents the function type the scope is able to call.
 type Func func(context.Context) error
 
 // Service holds the start and stop function of a specific service or
 // server. Normally start is a blocking function which returns when
 // Stop will be called.
+//
+// The optional Name identifies the service, e.g. in logs and when
+// waiting for it (see WaitFor). The optional Ready function blocks
+// until the service is ready to serve and is called concurrently to
+// Start. If Ready is nil, the service is ready as soon as it was started.
+//
+// Stop and deferred functions are called with a shutdown context. It
+// holds the values of the scope's context, but is not cancelled with it,
+// so cleanup work like flushing buffers is possible even when the scope
+// ended because of e.g. a fatal error. The shutdown context carries the
+// deadline of the shutdown (see CloseContext and WithShutdownTimeout).
+//
+// The optional Health function checks whether the running service is
+// healthy, e.g. whether its connections are alive. It is called for each
+// health check (see Scope.Health and Scope.HealthHandler) and should
+// return quickly.
+//
+// The optional OnError function is called with the errors of the
+// service instead of the scope's error handler (see WithErrorHandler).
+//
+// The optional Suspend and Unsuspend functions make the running service
+// stop and continue pulling work without stopping it (see Scope.Suspend
+// and Scope.Unsuspend). They are called with the context of the caller.
+//
+// The optional Idle function makes worker services finish their items in
+// flight before they are stopped. It returns a channel, which is closed
+// or receives a value as soon as the service has no item in flight. When
+// the scope is closed, Stop is called once the service is idle or Start
+// returned. The wait takes at most the stop timeout (see StopTimeout)
+// and half of the time left until the shutdown deadline, so Stop keeps
+// the rest.
+//
+// The Restart policy defines whether Start is called again when it
+// returned before the scope was closed. The delay between the restarts
+// is defined by Backoff. The error handler is only called when no more
+// restarts are allowed.
+//
+// DependsOn lists the names of the services, which have to be ready
+// before Start is called (see WaitFor). If one of them fails before it
+// is ready, the service fails as well. Services depending on each other
+// are rejected with ErrDependencyCycle. When the scope is closed, the
+// stop functions of dependent services are called before the ones of
+// their dependencies within the same stop order.
+//
+// The StopPolicy defines whether Stop is called depending on the outcome
+// of Start. By default, the policy of the scope applies (see
+// WithStopPolicy).
+//
+// StopOrder groups the stop functions into ordered phases, e.g. to stop
+// accepting traffic before draining workers and closing connections.
+// When the scope is closed, the phases are stopped in ascending order
+// and the stop functions of a phase in reverse registration order.
+// Deferred functions (see Defer) belong to phase 0.
 type Service struct {
-	Start Func
-	Stop  Func
+	Name       string
+	Start      Func
+	Stop       Func
+	Ready      Func
+	Health     Func
+	OnError    func(error)
+	Suspend    Func
+	Unsuspend  Func
+	Idle       func() <-chan struct{}
+	Restart    RestartPolicy
+	Backoff    Backoff
+	DependsOn  []string
+	StopPolicy StopPolicy
+	StopOrder  int
 }
 
 // Scope provides a way to run several functions concurrently and register
 // clean-up functions which are run when the scope is closed.
 type Scope struct {
 	ctx     context.Context
-	cancel  context.CancelFunc
+	cancel  context.CancelCauseFunc
+	timer   *time.Timer
 	wg      sync.WaitGroup
 	onError func(error)
+	logger  *slog.Logger
+	slow    time.Duration
 	mtx     sync.Mutex
 	tasks   []*task
+	nextIdx int                 // index of the next registered task, guarded by mtx
+	deps    map[string][]string // dependencies of the services by name, guarded by mtx (see Service.DependsOn)
+	pruneAt int                 // number of tasks, which triggers pruning, guarded by mtx (see WithBoundedMemory)
+	report  *ShutdownReport
+	metrics *metrics
+	opts    options
+	id      string
+	closing chan struct{}
+	closed  chan struct{} // closed when Close completed (see Done)
+	refuse  chan struct{} // closed when Close or Drain began (see Rejecting)
+	changed chan struct{} // closed when a task changes, created on demand (see await)
+	limiter *limiter      // nil if the concurrency is unlimited
+	rand    *random
+	running atomic.Int64
+	peak    atomic.Int64
+	cause   CloseCause // cause used by Close, guarded by mtx (see setCloseCause)
+
+	draining atomic.Bool                 // see Drain
+	values   atomic.Pointer[map[any]any] // values of the task contexts, written with mtx held (see SetValue)
+	provided map[reflect.Type]provided   // registry of the scope, guarded by mtx (see Provide)
+
+	pools     workCounts             // pending work of all pools (see Pool)
+	consumers map[*Consumer]struct{} // running consumers, guarded by mtx (see Consume)
+
+	frozen bool    // guarded by mtx (see Freeze)
+	held   []*task // tasks started while frozen, guarded by mtx
+
+	parent   *Scope   // nil if the scope is no child (see Child)
+	module   string   // name of the module, empty if the scope is no module (see Compose)
+	children []*Scope // open child scopes, guarded by mtx
+
+	finally   []*task // final functions, guarded by mtx (see Finally)
+	finalized bool    // whether the final functions were called, guarded by mtx
+
+	closeCalled bool  // whether Close was called, guarded by mtx
+	closeErr    error // result of the shutdown, guarded by mtx (see Close)
+
+	readyMtx   sync.Mutex
+	ready      bool // aggregated readiness, guarded by readyMtx
+	summarized bool // whether the startup summary was emitted, guarded by readyMtx
+
+	recorder *recordLog // nil if nothing is recorded (see WithRecorder)
 }
 
-// New creates a new scope with the given options.
+// New creates a new scope with the given options. It panics if the base
+// context is already done and rejected (see WithDoneContextPolicy).
 func New(o ...Option) *Scope {
+	s, err := NewE(o...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// NewE is like New, but returns an error instead of panicking if the base
+// context is already done and rejected (see RejectDoneContext).
+func NewE(o ...Option) (*Scope, error) {
 	opts := defaultOptions()
 	for _, apply := range o {
 		apply(&opts)
 	}
+	if opts.doneCtx == RejectDoneContext && opts.ctx.Err() != nil {
+		return nil, fmt.Errorf("%w: %w", ErrContextDone, context.Cause(opts.ctx))
+	}
+	return build(opts), nil
+}
 
-	ctx, cancel := context.WithCancel(opts.ctx)
-	return &Scope{
-		ctx:     ctx,
-		cancel:  cancel,
+func build(opts options) *Scope {
+	if opts.strict && opts.grace <= 0 {
+		panic("scope options: strict close requires a grace period")
+	}
+
+	s := &Scope{
 		onError: opts.errorHandler,
+		logger:  opts.logger,
+		slow:    opts.slowCancel,
+		metrics: newMetrics(opts.retain),
+		opts:    opts,
+		id:      strconv.FormatUint(lastScopeID.Add(1), 10),
+		rand:    newRandom(opts.rand),
+	}
+	if opts.recorder != nil {
+		s.recorder = &recordLog{created: time.Now(), w: opts.recorder}
+	}
+	s.limiter = opts.newLimiter()
+	s.init()
+	return s
+}
+
+func (s *Scope) init() {
+	s.closing = make(chan struct{})
+	s.closed = make(chan struct{})
+	s.refuse = make(chan struct{})
+	if s.opts.doneCtx == CloseOnDoneContext && s.opts.ctx.Err() != nil {
+		close(s.closing)
+		close(s.refuse)
+	}
+	if s.opts.closeOnExit {
+		registerExit(s)
+	}
+	ctx, cancel := context.WithCancelCause(context.WithValue(s.opts.ctx, closingKey{}, s.closing))
+	s.ctx = ctx
+	s.cancel = cancel
+	s.timer = nil
+	if d := s.opts.maxLifetime; d > 0 {
+		s.timer = time.AfterFunc(d, func() { cancel(MaxLifetime{Lifetime: d}) })
+	}
+	s.watchSignals()
+}
+
+// Reset returns a closed scope to a fresh state, so it can be reused
+// (e.g. via sync.Pool). The scope gets a new context derived from the
+// base context, and all registered functions, values, consumers, the
+// concurrency limit, the shutdown report, and the metrics are cleared.
+// A child scope is derived from its parent again and closed with it
+// (see Child). The options remain the same. Reset panics if the scope
+// is not closed. It must not be called concurrently with any other
+// method of the scope.
+func (s *Scope) Reset() {
+	if s.report == nil {
+		panic("scope: reset of an open scope")
+	}
+
+	clear(s.tasks)
+	s.tasks = s.tasks[:0]
+	s.nextIdx, s.pruneAt = 0, 0
+	s.deps = nil
+	s.provided = nil
+	s.values.Store(nil)
+	s.consumers = nil
+	s.limiter = s.opts.newLimiter()
+	s.report = nil
+	s.closeCalled, s.closeErr = false, nil
+	s.metrics = newMetrics(s.opts.retain)
+	s.peak.Store(0)
+	s.ready = false
+	s.summarized = false
+	s.frozen = false
+	s.held = nil
+	s.cause = nil
+	s.children = nil
+	s.finally = nil
+	s.finalized = false
+	s.draining.Store(false)
+	if s.parent != nil {
+		// The parent may have been reset as well.
+		s.opts.ctx = s.parent.ctx
+	}
+	s.init()
+	if s.parent != nil {
+		s.parent.addChild(s)
 	}
 }
 
 // Ctx returns the scope's context. The context is derived from the
 // configured base context (see WithContext) and is cancelled when
-// the scope is closed.
+// the scope is closed. Use Cause to determine why the context ended.
 func (s *Scope) Ctx() context.Context {
 	return s.ctx
 }
 
 // Go runs the given function in a new Goroutine. If the function
 // returns an error, it will be reported by the registered error
-// handler (see WithErrorHandler).
-func (s *Scope) Go(f Func) {
-	s.Start(Service{Start: f})
+// handler (see WithErrorHandler). The returned handle allows to wait
+// for the function.
+func (s *Scope) Go(f Func, opts ...StartOption) *Task {
+	return s.Start(Service{Start: f}, opts...)
 }
 
 // Defer registers a function which will be called when the scope
 // is closed. All deferred functions are called in reverse order
-// of registration to mimic the `defer` behaviour.
+// of registration to mimic the `defer` behaviour, after the stop
+// functions of services with a negative stop order (see Service).
+// Functions deferred after the scope was closed are never called;
+// ErrClosed is reported by the error handler instead.
 func (s *Scope) Defer(f Func) {
-	s.Start(Service{
-		Start: func(context.Context) error { return nil },
-		Stop:  f,
-	})
+	t := &task{
+		stop:   f,
+		state:  succeeded,
+		exited: time.Now(),
+	}
+	s.capture(t)
+	s.registerDeferred(t)
+}
+
+// DeferVal registers a cleanup function for the given value, which will
+// be called when the scope is closed (see Defer). The value is captured
+// explicitly, which avoids capturing loop variables by accident, and is
+// available in the task's info (see TaskInfo).
+func DeferVal[T any](s *Scope, v T, f func(context.Context, T) error) {
+	t := &task{
+		stop:   func(ctx context.Context) error { return f(ctx, v) },
+		val:    v,
+		state:  succeeded,
+		exited: time.Now(),
+	}
+	s.capture(t)
+	s.registerDeferred(t)
 }
 
 // Start tries to run the given service. The service's Start function will
 // be called in a new Goroutine. The optional Stop function is called
 // when the scope will be closed. However, if the Start function returns
 // an error before the scope is closed, the error handler will be called
-// and the Stop function will not be invoked.
-func (s *Scope) Start(svc Service) {
-	t := &task{stop: svc.Stop}
+// and the Stop function will not be invoked by default (see StopPolicy).
+// If the concurrency of the scope is limited (see WithLimit), the Start
+// function waits for a free slot. Services, which are still waiting when
+// the scope is closed, are never started. A service without a Start
+// function is reported as failed with ErrNoStartFunc when it is
+// registered, and its Stop function is never called. Services started
+// after the scope was closed are never started and ErrClosed is reported
+// (see TryStart). Likewise, ErrDraining is reported while the scope is
+// draining (see Drain). The returned handle allows to wait for the
+// service.
+func (s *Scope) Start(svc Service, opts ...StartOption) *Task {
+	t := &task{name: svc.Name, svc: svc, stop: svc.Stop}
+	s.capture(t)
+	s.start(t, opts)
+	return (*Task)(t)
+}
+
+// TryStart starts the given service like Start, but returns ErrClosed
+// or ErrDraining without reporting it to the error handler if the scope
+// was closed or is draining.
+func (s *Scope) TryStart(svc Service, opts ...StartOption) (*Task, error) {
+	t := &task{name: svc.Name, svc: svc, stop: svc.Stop, try: true}
+	s.capture(t)
+	// The rejection is decided by start, so it cannot
+	// race with a concurrent Close or Drain.
+	if !s.start(t, opts) && t.state.is(failed) && (t.err == ErrClosed || t.err == ErrDraining) {
+		return nil, t.err
+	}
+	return (*Task)(t), nil
+}
+
+// IsClosed reports whether Close was called.
+func (s *Scope) IsClosed() bool {
+	return isClosed(s.closing)
+}
 
+// Closing returns a channel, which is closed as soon as the scope begins
+// to close (see IsClosed). This allows components outside of the scope,
+// e.g. readiness probes, to react to the shutdown without sharing the
+// scope's context.
+func (s *Scope) Closing() <-chan struct{} {
 	s.mtx.Lock()
-	s.tasks = append(s.tasks, t)
+	defer s.mtx.Unlock()
+	return s.closing
+}
+
+// Done returns a channel, which is closed when Close completed, i.e.
+// when all stop functions were called and all start functions returned.
+// If Close returns early because its context is done, the channel is
+// closed when the shutdown completed in the background nevertheless.
+func (s *Scope) Done() <-chan struct{} {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.closed
+}
+
+// rejectClosed reports ErrClosed for a task, which is registered after
+// the scope was closed. It reports whether the task was rejected.
+func (s *Scope) rejectClosed(t *task) bool {
+	if !s.IsClosed() {
+		return false
+	}
+	s.reject(t, ErrClosed)
+	return true
+}
+
+// registerDeferred registers a task without a start function unless
+// the scope was closed, in which case ErrClosed is reported. The check
+// and the registration are done under the same lock, so Close either
+// calls the task's stop function or the task is rejected.
+func (s *Scope) registerDeferred(t *task) {
+	s.mtx.Lock()
+	if isClosed(s.closing) {
+		s.mtx.Unlock()
+		s.reject(t, ErrClosed)
+		return
+	}
+	s.registerLocked(t)
+	s.mtx.Unlock()
+	s.updateReadiness()
+}
+
+// rejectStart is like rejectClosed, but rejects tasks with a start
+// function while the scope is draining as well (see Drain).
+func (s *Scope) rejectStart(t *task) bool {
+	s.mtx.Lock()
+	err := s.rejectionLocked()
 	s.mtx.Unlock()
+	if err == nil {
+		return false
+	}
+	s.reject(t, err)
+	return true
+}
+
+// rejectionLocked returns the error, a task with a start function is
+// rejected with, or nil if the scope accepts new tasks. It must be
+// called with the scope's mutex held.
+func (s *Scope) rejectionLocked() error {
+	switch {
+	case isClosed(s.closing):
+		return ErrClosed
+	case isClosed(s.refuse):
+		return ErrDraining
+	}
+	return nil
+}
 
+func (s *Scope) reject(t *task, err error) {
+	t.err = err
+	t.setState(failed)
+	if !t.try {
+		s.log(slog.LevelWarn, "task rejected", "task", t, "caller", (*lazyCaller)(t), "error", err)
+		s.onError(&TaskError{Task: t.info(), Err: err})
+	}
+	t.finish()
+}
+
+// start registers the task and runs it unless the scope is frozen or
+// the task has no start function. It reports whether the task was
+// launched.
+func (s *Scope) start(t *task, opts []StartOption) bool {
+	svc := t.svc
+	for _, apply := range s.opts.defaults {
+		apply(&t.opts)
+	}
+	for _, apply := range opts {
+		apply(&t.opts)
+	}
+
+	if s.rejectStart(t) {
+		return false
+	}
+	var err error
+	switch {
+	case svc.Start == nil:
+		err = ErrNoStartFunc
+	case len(svc.DependsOn) > 0:
+		err = s.addDependencies(t)
+	}
+	if err != nil {
+		t.err = err
+		t.setState(failed)
+		s.register(t)
+		if s.opts.failFast {
+			s.cancel(FatalError{Err: t.err})
+		}
+		s.reportError(t, &TaskError{Task: t.info(), Err: t.err}, 0)
+		t.finish()
+		return false
+	}
+
+	// The task is rejected, registered, and added to the wait group
+	// while holding the mutex, so Close, which closes the scope under
+	// the same mutex, either stops the task or it is never launched.
+	s.mtx.Lock()
+	if err := s.rejectionLocked(); err != nil {
+		s.mtx.Unlock()
+		s.reject(t, err)
+		return false
+	}
+	var run func()
+	switch {
+	case s.frozen:
+		t.setState(pending)
+		s.held = append(s.held, t)
+	case len(svc.DependsOn) > 0:
+		t.setState(pending)
+		run = s.awaitDependenciesLocked(t)
+	default:
+		run = s.prepareLocked(t)
+	}
+	s.registerLocked(t)
+	s.mtx.Unlock()
+	s.updateReadiness()
+
+	if run == nil {
+		return false
+	}
+	s.spawn(run)
+	return true
+}
+
+// enqueue prepares the task for a new run. If the concurrency is limited,
+// a slot is requested and the returned ticket has to be passed to run.
+func (s *Scope) enqueue(t *task) chan struct{} {
+	if s.limiter == nil {
+		t.setState(running)
+		return nil
+	}
+	t.setState(pending)
+	return s.limiter.enqueue(t.opts.group)
+}
+
+// launch runs the registered task's start function in a new Goroutine.
+// The task is skipped if the scope was closed before. It reports whether
+// the task was launched.
+func (s *Scope) launch(t *task) bool {
+	s.mtx.Lock()
+	if isClosed(s.closing) {
+		s.mtx.Unlock()
+		t.setState(skipped)
+		s.notify()
+		t.finish()
+		return false
+	}
+	run := s.prepareLocked(t)
+	s.mtx.Unlock()
+	s.spawn(run)
+	return true
+}
+
+// prepareLocked enqueues the task for a new run and returns the function,
+// which runs it. Named tasks and isolated tasks get their own context,
+// which allows to stop them individually (see Scope.RollingRestart,
+// Scope.StartCanary, and Scope.StartGroup). It must be called with the
+// scope's mutex held.
+func (s *Scope) prepareLocked(t *task) func() {
+	ticket := s.enqueue(t)
+	ctx := s.ctx
+	done := make(chan struct{})
+	if t.name != "" || t.isolated {
+		ctx, t.cancel = context.WithCancelCause(ctx)
+	}
+	t.done = done
+	if s.recorder != nil {
+		// The attributes would allocate even without a recorder.
+		s.record("scheduled", t, "state", t.state.String())
+	}
+	// The task is added to the wait group while holding the mutex, so
+	// Close, which closes the scope under the same mutex, either waits
+	// for the task or the task is never launched. This ensures that all
+	// errors of tasks started before Close have been reported when Close
+	// returns.
 	s.wg.Add(1)
-	go func() {
+
+	return func() {
 		defer s.wg.Done()
+		defer close(done)
+		s.run(ctx, t, ticket)
+	}
+}
+
+func (s *Scope) run(ctx context.Context, t *task, ticket chan struct{}) {
+	if !s.acquire(t, ticket) {
+		s.notify()
+		t.finish()
+		return
+	}
+	defer s.release(t)
+
+	s.startRunning()
+	defer s.stopRunning()
+
+	s.awaitFailures(ctx, t)
+
+	start := time.Now()
+	err := s.call(ctx, t)
+	s.recordFailure(ctx, t, err)
+	restarts := 0
+	for ; s.shouldRestart(ctx, t, err, restarts); restarts++ {
+		err = s.call(ctx, t)
+		s.recordFailure(ctx, t, err)
+	}
+	if n := t.svc.Backoff.MaxAttempts; err != nil && n > 0 && restarts >= n && ctx.Err() == nil {
+		err = fmt.Errorf("%w after %d restarts: %w", ErrRestartBudgetExceeded, restarts, err)
+	}
+
+	cause := context.Cause(ctx)
+	if err != nil {
+		switch cause.(type) {
+		case Restarted, RolledBack, Stopped, Paused:
+			// The task was stopped on purpose, so we
+			// don't treat the error as a failure.
+			err = nil
+		}
+	}
+	switch cause.(type) {
+	case Paused:
+		// The task is started again when it is
+		// resumed (see Admin.Resume).
+		t.setState(paused)
+		s.notify()
+		return
+	case Restarted:
+	default:
+		defer t.finish()
+	}
+
+	if err == nil {
+		t.setState(succeeded)
+		s.log(slog.LevelDebug, "task completed", "task", t, "duration", time.Since(start))
+		s.setReady(t, nil)
+	} else {
+		s.fail(t, err, time.Since(start))
+	}
+}
+
+// fail marks the task as failed and reports the error.
+func (s *Scope) fail(t *task, err error, duration time.Duration) {
+	t.err = err
+	t.setState(failed)
+	s.metrics.failed.Add(1)
+	s.notify()
+	if s.opts.failFast {
+		s.cancel(FatalError{Err: err})
+	}
+	s.reportError(t, err, duration)
+}
+
+// call calls the task's start function once.
+func (s *Scope) call(ctx context.Context, t *task) error {
+	s.log(slog.LevelDebug, "task started", "task", t)
+	s.metrics.started.Add(1)
+	s.record("started", t)
+	t.mtx.Lock()
+	t.startTime = time.Now()
+	if s.opts.accounting {
+		t.runSince = t.startTime
+	}
+	t.mtx.Unlock()
+
+	var err error
+	pprof.Do(s.withValues(t.context(ctx)), s.labels(t.idx), func(ctx context.Context) {
+		ctx = s.opts.instruments.taskStarted(ctx, t)
+		stopReady := s.awaitReady(ctx, t, t.svc.Ready)
+		var check *checkedContext
+		if s.opts.contextCheck {
+			check = &checkedContext{Context: ctx}
+			ctx = check
+		}
+		started := time.Now()
+		err = protect(ctx, s.wrap(StartCall, t, t.svc.Start))
+		t.exited = time.Now()
+		if err != nil && s.expected(err) {
+			s.log(slog.LevelDebug, "task returned expected error", "task", t, "error", err)
+			err = nil
+		}
+		if check != nil {
+			s.checkContext(t, check)
+		}
+		if s.recorder != nil {
+			s.record("ended", t, "error", err)
+		}
+		if s.opts.accounting {
+			t.mtx.Lock()
+			t.wallTime += t.exited.Sub(t.runSince)
+			t.runSince = time.Time{}
+			t.mtx.Unlock()
+		}
+		if s.limiter != nil {
+			s.limiter.observe(err, t.exited.Sub(started))
+		}
+		stopReady()
+		s.opts.instruments.taskEnded(ctx, t, err)
+	})
+	return err
+}
+
+// stopTask calls the task's stop function.
+func (s *Scope) stopTask(ctx context.Context, t *task) error {
+	t.stopping.Store(true)
+	defer t.stopping.Store(false)
+	t.mtx.Lock()
+	t.stages = nil
+	t.mtx.Unlock()
+
+	s.record("stopping", t)
+	if t.svc.Idle != nil {
+		s.awaitIdle(ctx, t)
+	}
+	if d := t.opts.stopTimeout; d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	var err error
+	start := time.Now()
+	pprof.Do(s.withValues(t.context(ctx)), s.labels(t.idx), func(ctx context.Context) {
+		ctx = s.opts.instruments.stopStarted(ctx, t)
+		t.stopped.Store(true)
+		unwatch := s.watchStop(ctx, t)
+		err = protect(ctx, s.wrap(StopCall, t, t.stop))
+		unwatch()
+		s.opts.instruments.stopEnded(ctx, t, err)
+	})
+	duration := time.Since(start)
+	s.metrics.observeStop(t, duration)
+	s.record("stopped", t, "duration", duration, "error", err)
+	switch {
+	case err != nil:
+		s.log(slog.LevelError, "stop function failed", "task", t, "caller", (*lazyCaller)(t), "duration", duration, "error", err)
+		s.handlePanic(err)
+	case duration > s.slow:
+		s.log(slog.LevelWarn, "stop function slow", "task", t, "caller", (*lazyCaller)(t), "duration", duration)
+		s.warn(Warning{Kind: WarnSlowStop, Task: t.info(), Duration: duration})
+	default:
+		s.log(slog.LevelDebug, "task stopped", "task", t, "duration", duration)
+	}
+	return err
+}
 
-		if err := svc.Start(s.ctx); err == nil {
-			t.state.set(succeeded)
-		} else {
-			t.state.set(failed)
-			s.onError(err)
+// reportError reports the error of a failed task to the error handler.
+func (s *Scope) reportError(t *task, err error, duration time.Duration) {
+	if s.handlePanic(err) {
+		s.log(slog.LevelError, "task panicked", "task", t, "caller", (*lazyCaller)(t), "duration", duration, "error", err)
+		return
+	}
+	if smp := t.opts.sampler; smp != nil {
+		sampled := smp.sample(err, s.rand)
+		if sampled == nil {
+			s.log(slog.LevelDebug, "task failed", "task", t, "duration", duration, "error", err, "suppressed", true)
+			s.warn(Warning{Kind: WarnSuppressedError, Task: t.info(), Duration: duration, Err: err})
+			return
 		}
-	}()
+		err = sampled
+	}
+
+	s.log(slog.LevelError, "task failed", "task", t, "caller", (*lazyCaller)(t), "duration", duration, "error", err)
+	if t.svc.OnError != nil {
+		t.svc.OnError(err)
+	} else {
+		s.onError(err)
+	}
 }
 
-// Close closes the scope and runs all deferred functions. It waits
-// until all functions have completed.
+// expected reports whether the error of a start function is expected
+// (see WithExpectedErrors).
+func (s *Scope) expected(err error) bool {
+	for _, target := range s.opts.expected {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scope) register(t *task) {
+	s.mtx.Lock()
+	s.registerLocked(t)
+	s.mtx.Unlock()
+	s.updateReadiness()
+}
+
+func (s *Scope) registerLocked(t *task) {
+	if s.opts.retain > 0 && len(s.tasks) >= s.pruneAt {
+		s.pruneLocked()
+		// Pruning again after the number of tasks doubled
+		// keeps the registration amortized constant.
+		s.pruneAt = max(2*len(s.tasks), 2*s.opts.retain)
+	}
+	t.idx, t.scope = s.nextIdx, s.id
+	s.nextIdx++
+	s.tasks = append(s.tasks, t)
+	s.notifyLocked()
+}
+
+// pruneLocked drops the oldest finished tasks without a stop function,
+// so at most the configured number of them is retained (see
+// WithBoundedMemory). The tasks are copied into a new slice, since the
+// old one may still be iterated without holding the mutex.
+func (s *Scope) pruneLocked() {
+	var finished int
+	for _, t := range s.tasks {
+		if t.prunable() {
+			finished++
+		}
+	}
+	excess := finished - s.opts.retain
+	if excess <= 0 {
+		return
+	}
+
+	tasks := make([]*task, 0, len(s.tasks)-excess)
+	for _, t := range s.tasks {
+		if excess > 0 && t.prunable() {
+			excess--
+			continue
+		}
+		tasks = append(tasks, t)
+	}
+	s.tasks = tasks
+}
+
+// Close closes the scope and runs all deferred functions. Afterwards
+// the scope's context is cancelled and Close waits until all functions
+// have completed. Finally, the final functions are called (see
+// Finally). If stop or final functions failed, the returned error is of
+// type Errors. All invocations of the error handler for tasks started
+// before Close have completed when Close returns, so final errors are
+// never lost when the process exits right afterwards. If a shutdown
+// timeout or budget is configured (see WithShutdownTimeout and
+// WithShutdownBudget), Close is bounded like CloseContext. Subsequent
+// calls do not run the shutdown again, but wait until the first one
+// completed and return its result.
 func (s *Scope) Close() error {
+	return s.CloseContext(context.Background())
+}
+
+// CloseContext closes the scope like Close, but returns when the given
+// context is done before the shutdown finished. In this case, the
+// contexts of the running stop functions and the scope's context are
+// cancelled as a best effort, and an error of type *ShutdownTimeoutError
+// is returned, which lists the tasks that did not finish. The shutdown
+// proceeds in the background, so the shutdown report is not available
+// before all tasks have finished.
+func (s *Scope) CloseContext(ctx context.Context) error {
+	if d := s.opts.shutdownTimeout; d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+	if b := s.opts.shutdownBudget; b != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = b.context(ctx)
+		defer cancel()
+	}
+
+	s.mtx.Lock()
+	called := s.closeCalled
+	s.closeCalled = true
+	s.mtx.Unlock()
+	if called {
+		return s.awaitClosed(ctx)
+	}
+
+	// The end of a bounded shutdown may be reserved
+	// for the final functions (see WithFinalTimeout).
+	stopCtx := ctx
+	if deadline, ok := ctx.Deadline(); ok && s.opts.finalTimeout > 0 {
+		var cancel context.CancelFunc
+		stopCtx, cancel = context.WithDeadline(ctx, deadline.Add(-s.opts.finalTimeout))
+		defer cancel()
+	}
+
+	closed := make(chan error, 1)
+	s.spawn(func() { closed <- s.close(stopCtx, ctx) })
+
+	select {
+	case err := <-closed:
+		return err
+	case <-stopCtx.Done():
+		s.record("timeout", nil, "cause", context.Cause(stopCtx))
+		s.cancel(Manual{})
+		err := &ShutdownTimeoutError{Tasks: s.unfinishedTasks(), Err: context.Cause(stopCtx)}
+		s.log(slog.LevelError, "scope not closed", "error", err)
+		s.finalizeAsync(ctx)
+		return err
+	}
+}
+
+// awaitClosed waits until the shutdown, which was started by a previous
+// call of Close, completed and returns its result. If the given context
+// is done before, a *ShutdownTimeoutError is returned.
+func (s *Scope) awaitClosed(ctx context.Context) error {
+	select {
+	case <-s.closed:
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+		return s.closeErr
+	case <-ctx.Done():
+		return &ShutdownTimeoutError{Tasks: s.unfinishedTasks(), Err: context.Cause(ctx)}
+	}
+}
+
+// shutdownContext returns the context for the stop functions. It keeps
+// the values of the scope's context, but is not cancelled with it, since
+// the scope's context may already be done (e.g. because of a fatal error)
+// when the stop functions need to flush or drain. Instead, it carries the
+// deadline of the given shutdown context and is cancelled when the
+// shutdown context is done.
+func (s *Scope) shutdownContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	var (
+		stopCtx = context.WithoutCancel(s.ctx)
+		cancel  context.CancelFunc
+	)
+	if deadline, ok := ctx.Deadline(); ok {
+		stopCtx, cancel = context.WithDeadline(stopCtx, deadline)
+	} else {
+		stopCtx, cancel = context.WithCancel(stopCtx)
+	}
+	unwatch := context.AfterFunc(ctx, cancel)
+	return stopCtx, func() {
+		unwatch()
+		cancel()
+	}
+}
+
+// close shuts the scope down. The stop functions are bounded by ctx, the
+// final functions by finalCtx (see Finally).
+func (s *Scope) close(ctx, finalCtx context.Context) error {
 	s.mtx.Lock()
 	tasks := s.tasks
+	requested := s.cause
+	if !isClosed(s.closing) {
+		close(s.closing)
+	}
+	s.refuseLocked()
+	s.skipHeldLocked()
+	children := s.children
+	s.children = nil
 	s.mtx.Unlock()
+	if s.parent != nil {
+		s.parent.removeChild(s)
+	}
+	if s.opts.closeOnExit {
+		deregisterExit(s)
+	}
+	s.updateReadiness()
 
-	defer s.cancel()
+	// The context may already be done, e.g. because of a
+	// fatal error. Otherwise the scope is closed for the
+	// requested cause or manually.
+	cause := Cause(s.ctx)
+	switch {
+	case cause != nil:
+	case requested != nil:
+		cause = requested
+	default:
+		cause = Manual{}
+	}
+	s.log(slog.LevelInfo, "closing scope", "tasks", len(tasks), "cause", CauseLabel(cause))
+	s.record("closing", nil, "tasks", len(tasks), "cause", CauseLabel(cause))
+	report := &ShutdownReport{Started: time.Now()}
 
-	var errs errorlist
-	for i := len(tasks); i > 0; {
-		i--
+	if g, m := s.opts.grace, s.opts.warnMargin; g > 0 && m > 0 {
+		timer := time.AfterFunc(max(g-m, 0), s.warnDeadline)
+		defer timer.Stop()
+	}
+	if s.opts.strict {
+		timer := time.AfterFunc(s.opts.grace, s.failStrict)
+		defer timer.Stop()
+	}
+	stopStall := s.watchStall(report.Started)
 
-		// If the start function failed we don't
-		// want to call the deferred function.
-		if t := tasks[i]; t.stop != nil && !t.state.is(failed) {
-			errs.append(t.stop(s.ctx))
-		}
+	stopCtx, cancelStops := s.shutdownContext(ctx)
+	defer cancelStops()
+
+	if d := s.opts.softCancel; d > 0 {
+		end := s.phase(report, PhaseSoftCancel)
+		s.awaitSoftCancel(ctx, d)
+		end()
+	}
+
+	var errs Errors
+	if len(children) > 0 {
+		end := s.phase(report, PhaseChildren)
+		errs.append(s.closeChildren(ctx, children, cause))
+		end()
+	}
+
+	end := s.phase(report, PhaseStop)
+	errs = append(errs, s.stopTasks(stopCtx, tasks)...)
+	end()
+
+	if s.timer != nil {
+		s.timer.Stop()
 	}
+	end = s.phase(report, PhaseCancel)
+	report.Cancelled = time.Now()
+	s.cancel(cause)
+	report.Cause = Cause(s.ctx)
+	s.metrics.setCloseCause(report.Cause)
 	s.wg.Wait()
-	return errs.err()
+	end()
+	for _, t := range tasks {
+		// Paused tasks are never resumed.
+		if t.state.is(paused) {
+			t.finish()
+		}
+	}
+	errs = append(errs, s.finalize(finalCtx, report)...)
+	report.Finished = time.Now()
+	s.reportTasks(report, tasks)
+
+	s.mtx.Lock()
+	s.report = report
+	s.mtx.Unlock()
+
+	duration := report.Finished.Sub(report.Started)
+	s.metrics.setShutdownDuration(duration)
+	err := errs.err()
+	s.record("closed", nil, "duration", duration, "error", err)
+	s.opts.instruments.closeEnded(err)
+	if err != nil {
+		s.log(slog.LevelError, "scope closed", "duration", duration, "cause", report.Cause, "error", err)
+	} else {
+		s.log(slog.LevelInfo, "scope closed", "duration", duration, "cause", report.Cause)
+	}
+
+	stopStall()
+
+	s.mtx.Lock()
+	s.closeErr = err
+	close(s.closed)
+	s.mtx.Unlock()
+	return err
+}
+
+// stopTasks calls the stop functions of the given tasks in stop order
+// and returns their errors. With a parallel shutdown, the stop functions
+// of the same stop order are called concurrently.
+func (s *Scope) stopTasks(ctx context.Context, tasks []*task) Errors {
+	// By default, the stop function is not called if
+	// the start function failed or was never called
+	// (see StopPolicy).
+	tasks = slices.DeleteFunc(stopOrder(tasks), func(t *task) bool {
+		return t.stop == nil || t.retired.Load() || !s.shouldStop(t)
+	})
+
+	errs := make([]error, len(tasks))
+	stop := func(i int) {
+		if err := s.stopTask(ctx, tasks[i]); err != nil {
+			errs[i] = &TaskError{Task: tasks[i].info(), Err: err}
+		}
+	}
+
+	if n := s.opts.parallelStops; n > 1 {
+		sem := make(chan struct{}, n)
+		for start := 0; start < len(tasks); {
+			end := start + 1
+			for end < len(tasks) && tasks[end].svc.StopOrder == tasks[start].svc.StopOrder {
+				end++
+			}
+
+			var wg sync.WaitGroup
+			for i := start; i < end; i++ {
+				sem <- struct{}{}
+				wg.Add(1)
+				s.spawn(func() {
+					defer wg.Done()
+					defer func() { <-sem }()
+					stop(i)
+				})
+			}
+			wg.Wait()
+			start = end
+		}
+	} else {
+		for i := range tasks {
+			stop(i)
+		}
+	}
+
+	var res Errors
+	for _, err := range errs {
+		res.append(err)
+	}
+	return res
+}
+
+// stopOrder returns the tasks in the order their stop functions are
+// called, i.e. by ascending stop order and in reverse registration order
+// within the same stop order (see Service.StopOrder). Dependent services
+// are stopped before their dependencies (see Service.DependsOn).
+func stopOrder(tasks []*task) []*task {
+	ordered := dependencyOrder(tasks)
+	slices.Reverse(ordered)
+	slices.SortStableFunc(ordered, func(a, b *task) int {
+		return cmp.Compare(a.svc.StopOrder, b.svc.StopOrder)
+	})
+	return ordered
+}
+
+// ShutdownReport returns the report of the scope's shutdown. The
+// report is only available after the scope was closed.
+func (s *Scope) ShutdownReport() (ShutdownReport, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.report == nil {
+		return ShutdownReport{}, false
+	}
+	return *s.report, true
+}
+
+// Metrics returns a snapshot of the scope's metrics.
+func (s *Scope) Metrics() Metrics {
+	m := s.metrics.snapshot()
+	m.PeakRunning = int(s.peak.Load())
+	m.Leaked = s.leaked()
+	return m
+}
+
+// reportTasks adds the tasks' reports to the shutdown report. It must
+// not be called before all tasks have exited.
+func (s *Scope) reportTasks(r *ShutdownReport, tasks []*task) {
+	r.Tasks = make([]TaskReport, len(tasks))
+	for i, t := range tasks {
+		tr := TaskReport{Index: t.idx, Name: t.name, Exited: t.exited}
+		t.mtx.Lock()
+		tr.Stages = slices.Clone(t.stages)
+		t.mtx.Unlock()
+		if t.exited.After(r.Cancelled) {
+			tr.CancelLatency = t.exited.Sub(r.Cancelled)
+			tr.Slow = tr.CancelLatency > s.slow
+			s.metrics.observeCancelLatency(tr.CancelLatency)
+		}
+		if tr.Slow {
+			s.log(slog.LevelWarn, "task ignored cancellation", "task", t, "caller", (*lazyCaller)(t), "latency", tr.CancelLatency)
+			s.warn(Warning{Kind: WarnIgnoredCancel, Task: t.info(), Duration: tr.CancelLatency})
+		}
+		r.Tasks[i] = tr
+	}
+}
+
+func (s *Scope) log(level slog.Level, msg string, args ...any) {
+	if s.logger != nil {
+		s.logger.Log(context.Background(), level, msg, args...)
+	}
 }
 
 type state uint64
@@ -121,12 +1138,137 @@ const (
 	running state = iota
 	failed
 	succeeded
+	pending // waiting for a free slot
+	skipped // never started
+	paused  // stopped until it is resumed (see Admin.Pause)
 )
 
+func (s *state) String() string {
+	switch state(atomic.LoadUint64((*uint64)(s))) {
+	case running:
+		return "running"
+	case failed:
+		return "failed"
+	case succeeded:
+		return "succeeded"
+	case pending:
+		return "pending"
+	case skipped:
+		return "skipped"
+	case paused:
+		return "paused"
+	default:
+		return "unknown"
+	}
+}
+
 func (s *state) set(v state)     { atomic.StoreUint64((*uint64)(s), uint64(v)) }
 func (s *state) is(v state) bool { return state(atomic.LoadUint64((*uint64)(s))) == v }
 
 type task struct {
-	stop  Func
-	state state
+	idx    int    // position in registration order
+	scope  string // ID of the registering scope
+	name   string
+	opts   taskOptions
+	svc    Service
+	stop   Func
+	val    any // value of the cleanup function (see DeferVal)
+	site   callSite
+	state  state
+	exited time.Time // written by the task's goroutine before it is done
+	err    error     // written by the task's goroutine before it has failed
+
+	ready      bool                    // guarded by the scope's mutex
+	readyErr   error                   // guarded by the scope's mutex
+	done       chan struct{}           // closed when the current run ended, guarded by the scope's mutex
+	cancel     context.CancelCauseFunc // cancels the current run of a named task, guarded by the scope's mutex
+	quarantine string                  // reason of the quarantine, guarded by the scope's mutex (see Admin.Quarantine)
+	suspended  bool                    // set while the service is suspended, guarded by the scope's mutex (see Scope.Suspend)
+
+	stopped    atomic.Bool // set when the stop function was called
+	stopping   atomic.Bool // set while the stop function is running
+	restarting atomic.Bool // set while the task waits for a restart (see shouldRestart)
+	retired    atomic.Bool // set when the task was stopped for good before Close (see StartCanary and Admin.Stop)
+	isolated   bool        // the task gets its own context, even if it has no name (see launch)
+	try        bool        // rejections are returned instead of reported (see TryStart)
+	failures   int         // consecutive failures, only accessed by the task's goroutine (see recordFailure)
+
+	mtx        sync.Mutex
+	regions    []RegionInfo
+	regionsOff int              // number of regions dropped from the front (see WithBoundedMemory)
+	stages     []PhaseReport    // stages of the last stop function (see StopStage)
+	startTime  time.Time        // time of the last start
+	runSince   time.Time        // start of the current run, zero if not running (see WithTaskAccounting)
+	wallTime   time.Duration    // total time of the finished runs (see WithTaskAccounting)
+	finished   bool             // set when the task finished (see Task.Done)
+	finishedCh chan struct{}    // created on demand, closed when the task finished
+	subs       []chan TaskState // subscribers of state changes (see Task.Subscribe)
+	hasSubs    atomic.Bool      // set when the task had a subscriber
+	providers  []*task          // tasks, whose values the task looked up (see Get)
+}
+
+type taskKey struct{}
+
+// context returns a context for the task's functions, which allows to
+// find the task via taskFromContext.
+func (t *task) context(parent context.Context) context.Context {
+	return context.WithValue(parent, taskKey{}, t)
+}
+
+func taskFromContext(ctx context.Context) *task {
+	t, _ := ctx.Value(taskKey{}).(*task)
+	return t
+}
+
+// wallTimeLocked returns the total wall time of the task's runs at the
+// given time, including the current run. The task's mutex must be held.
+func (t *task) wallTimeLocked(now time.Time) time.Duration {
+	d := t.wallTime
+	if !t.runSince.IsZero() {
+		d += now.Sub(t.runSince)
+	}
+	return d
+}
+
+func (t *task) info() TaskInfo {
+	caller, stack := t.callSite()
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return TaskInfo{
+		Index:   t.idx,
+		Scope:   t.scope,
+		Name:    t.name,
+		Value:   t.val,
+		Regions: append([]RegionInfo(nil), t.regions...),
+		Caller:  caller,
+		Stack:   stack,
+	}
+}
+
+func (t *task) String() string {
+	if t.name != "" {
+		return t.name
+	}
+	return "#" + strconv.Itoa(t.idx)
+}
+
+func (t *task) started() bool {
+	return t.state.is(running) || t.state.is(succeeded)
+}
+
+// prunable reports whether the task finished and is not needed for the
+// shutdown anymore (see WithBoundedMemory).
+func (t *task) prunable() bool {
+	if t.stop != nil {
+		return false
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.finished
+}
+
+// deferred reports whether the task is a cleanup function (see Defer).
+func (t *task) deferred() bool {
+	return t.svc.Start == nil && t.state.is(succeeded)
 }
@@ -1,8 +1,13 @@
 package scope
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -134,6 +139,27 @@ func TestScopeStart(t *testing.T) {
 			t.Fatal("expected stop function to be called")
 		}
 	})
+
+	t.Run("no-start-func", func(t *testing.T) {
+		var reported error
+		stop := newCall(nil)
+
+		s := New(WithErrorHandler(func(err error) { reported = err }))
+		s.Start(Service{Name: "db", Stop: stop.f})
+
+		if !errors.Is(reported, ErrNoStartFunc) {
+			t.Fatalf("unexpected error: %v", reported)
+		}
+		if err := s.WaitFor(context.Background(), "db"); !errors.Is(err, ErrNoStartFunc) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if stop.called() {
+			t.Fatal("expected stop function not to be called")
+		}
+	})
 }
 
 func newScope(t *testing.T) *Scope {
@@ -188,3 +214,723 @@ func (c *call) wait(d time.Duration) error {
 		return ctx.Err()
 	}
 }
+
+func TestScopeLogger(t *testing.T) {
+	var buf bytes.Buffer
+	s := New(
+		WithLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		WithCancelLatencyThreshold(time.Millisecond),
+	)
+	s.Go(func(context.Context) error { return nil })
+	s.Defer(func(context.Context) error { return nil })
+	s.Start(Service{
+		Name: "slow",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Stop: func(context.Context) error {
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		},
+	})
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, msg := range []string{
+		"task started",
+		"task completed",
+		"task stopped",
+		`"stop function slow" task=slow`,
+		"closing scope",
+		"scope closed",
+	} {
+		if !strings.Contains(buf.String(), msg) {
+			t.Fatalf("expected log message %q, got:\n%s", msg, buf.String())
+		}
+	}
+}
+
+func TestScopeShutdownReport(t *testing.T) {
+	s := New(
+		WithErrorHandler(func(err error) { t.Fatalf("unexpected error: %v", err) }),
+		WithCancelLatencyThreshold(10*time.Millisecond),
+	)
+	if _, ok := s.ShutdownReport(); ok {
+		t.Fatal("expected no shutdown report before close")
+	}
+
+	s.Go(func(context.Context) error { return nil })
+	s.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report, ok := s.ShutdownReport()
+	switch {
+	case !ok:
+		t.Fatal("expected shutdown report")
+	case len(report.Tasks) != 2:
+		t.Fatalf("unexpected number of tasks: %d", len(report.Tasks))
+	case report.Tasks[0].Slow:
+		t.Fatal("expected first task not to be slow")
+	case !report.Tasks[1].Slow:
+		t.Fatal("expected second task to be slow")
+	}
+	if slow := report.Slow(); len(slow) != 1 || slow[0].Index != 1 {
+		t.Fatalf("unexpected slow tasks: %+v", slow)
+	}
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again, _ := s.ShutdownReport(); !again.Started.Equal(report.Started) || len(again.Tasks) != 2 {
+		t.Fatalf("unexpected report after second close: %+v", again)
+	}
+}
+
+func TestWithPhaseHook(t *testing.T) {
+	var phases []string
+	s := New(
+		WithErrorHandler(func(err error) { t.Fatalf("unexpected error: %v", err) }),
+		WithSoftCancel(time.Millisecond),
+		WithPhaseHook(func(p PhaseReport) { phases = append(phases, p.Name) }),
+	)
+	s.Child()
+	s.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The child inherits the phase hook, so its phases
+	// are reported during the parent's children phase.
+	expected := "[soft-cancel soft-cancel stop cancel children stop cancel]"
+	if got := fmt.Sprint(phases); got != expected {
+		t.Fatalf("unexpected phases: %s", got)
+	}
+	report, _ := s.ShutdownReport()
+	if len(report.Phases) != 4 || report.Phases[3].Duration < 10*time.Millisecond {
+		t.Fatalf("unexpected phase reports: %+v", report.Phases)
+	}
+}
+
+func TestScopeReset(t *testing.T) {
+	s := newScope(t)
+	s.Go(func(context.Context) error { return nil })
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s.Reset()
+	if err := s.Ctx().Err(); err != nil {
+		t.Fatalf("unexpected context error: %v", err)
+	}
+	if _, ok := s.ShutdownReport(); ok {
+		t.Fatal("expected no shutdown report after reset")
+	}
+
+	call := newCall(nil)
+	s.Defer(call.f)
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !call.called() {
+		t.Fatal("expected function to be called")
+	}
+	if report, _ := s.ShutdownReport(); len(report.Tasks) != 1 {
+		t.Fatalf("unexpected number of tasks: %d", len(report.Tasks))
+	}
+
+	t.Run("values and consumers", func(t *testing.T) {
+		type key struct{}
+		s := New(WithLimit(1))
+		s.SetValue(key{}, "value")
+		items := make(chan int, 1)
+		items <- 1
+		close(items)
+		Consume(s, items, func(context.Context, int) error { return nil })
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		s.Reset()
+		values := make(chan any, 1)
+		s.Go(func(ctx context.Context) error {
+			values <- ctx.Value(key{})
+			return nil
+		})
+		if v := <-values; v != nil {
+			t.Fatalf("unexpected value after reset: %v", v)
+		}
+		if st := s.Stats(); st.Work != nil || st.Limit != 1 {
+			t.Fatalf("unexpected stats after reset: %+v", st)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("child", func(t *testing.T) {
+		s := New()
+		child := s.Child()
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		s.Reset()
+		child.Reset()
+		if err := child.Ctx().Err(); err != nil {
+			t.Fatalf("unexpected context error of the child: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		select {
+		case <-child.Done():
+		default:
+			t.Fatal("child not closed with its parent")
+		}
+	})
+
+	t.Run("open", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected panic")
+			}
+		}()
+		New().Reset()
+	})
+}
+
+func TestDeferVal(t *testing.T) {
+	var rec recordingInstrumentor
+	s := New(
+		WithErrorHandler(func(err error) { t.Fatalf("unexpected error: %v", err) }),
+		WithInstrumentor(&valueInstrumentor{rec: &rec}),
+	)
+
+	var closed []string
+	for _, name := range []string{"a", "b", "c"} {
+		DeferVal(s, name, func(_ context.Context, name string) error {
+			closed = append(closed, name)
+			return nil
+		})
+	}
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(closed, "") != "cba" {
+		t.Fatalf("unexpected cleanup order: %v", closed)
+	}
+	if strings.Join(rec.events, ",") != "c,b,a" {
+		t.Fatalf("unexpected values: %v", rec.events)
+	}
+}
+
+type valueInstrumentor struct {
+	NopInstrumentor
+	rec *recordingInstrumentor
+}
+
+func (i *valueInstrumentor) StopStarted(ctx context.Context, t TaskInfo) context.Context {
+	i.rec.record("%v", t.Value)
+	return ctx
+}
+
+func TestScopeStopOrder(t *testing.T) {
+	s := newScope(t)
+
+	var stopped []string
+	stop := func(name string) Func {
+		return func(context.Context) error {
+			stopped = append(stopped, name)
+			return nil
+		}
+	}
+	run := func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}
+
+	s.Start(Service{Name: "db", Start: run, Stop: stop("db"), StopOrder: 2})
+	s.Start(Service{Name: "http", Start: run, Stop: stop("http"), StopOrder: -1})
+	s.Defer(stop("deferred"))
+	s.Start(Service{Name: "worker1", Start: run, Stop: stop("worker1")})
+	s.Start(Service{Name: "worker2", Start: run, Stop: stop("worker2")})
+	s.Start(Service{Name: "grpc", Start: run, Stop: stop("grpc"), StopOrder: -1})
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(stopped, ","); got != "grpc,http,worker2,worker1,deferred,db" {
+		t.Fatalf("unexpected stop order: %s", got)
+	}
+}
+
+func TestWithParallelShutdown(t *testing.T) {
+	s := New(WithParallelShutdown(3))
+
+	// The stop functions of the first phase only return when
+	// all of them were called.
+	var entered sync.WaitGroup
+	entered.Add(3)
+	errStop := errors.New("stop error")
+	for i := range 3 {
+		s.Defer(func(ctx context.Context) error {
+			entered.Done()
+			entered.Wait()
+			if i == 1 {
+				return errStop
+			}
+			return nil
+		})
+	}
+
+	var mtx sync.Mutex
+	var stopped []string
+	for _, name := range []string{"a", "b"} {
+		s.Start(Service{
+			Name:  name,
+			Start: func(ctx context.Context) error { <-ctx.Done(); return nil },
+			Stop: func(context.Context) error {
+				mtx.Lock()
+				defer mtx.Unlock()
+				stopped = append(stopped, name)
+				return nil
+			},
+			StopOrder: 1,
+		})
+	}
+
+	err := closeScope(s)
+	if errs, ok := err.(Errors); !ok || errs.Len() != 1 || !errors.Is(errs, errStop) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stopped) != 2 {
+		t.Fatalf("unexpected stopped services: %v", stopped)
+	}
+}
+
+func TestWithParallelShutdownLimit(t *testing.T) {
+	const n = 3
+	s := New(WithParallelShutdown(n))
+
+	var running, peak atomic.Int32
+	for range 4 * n {
+		s.Defer(func(context.Context) error {
+			cur := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if cur <= p || peak.CompareAndSwap(p, cur) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return nil
+		})
+	}
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p := peak.Load(); p < 2 || p > n {
+		t.Fatalf("unexpected number of concurrent stop functions: %d", p)
+	}
+}
+
+func TestWithTaskDefaults(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		s := New(WithTaskDefaults(StopTimeout(10 * time.Millisecond)))
+		s.Start(Service{
+			Start: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		})
+
+		err := closeScope(s)
+		var errs Errors
+		if !errors.As(err, &errs) || !errors.Is(errs.ByTask()["#0"][0], context.DeadlineExceeded) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("override", func(t *testing.T) {
+		stop := newCall(func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); ok {
+				return errors.New("unexpected deadline")
+			}
+			return nil
+		})
+		s := New(WithTaskDefaults(StopTimeout(time.Nanosecond)))
+		s.Start(Service{
+			Start: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			Stop: stop.f,
+		}, StopTimeout(0))
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !stop.called() {
+			t.Fatal("expected stop function to be called")
+		}
+	})
+}
+
+func TestScopeClosed(t *testing.T) {
+	var errs []error
+	s := New(WithErrorHandler(CollectErrors(&errs)))
+	if s.IsClosed() {
+		t.Fatal("expected scope to be open")
+	}
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !s.IsClosed() {
+		t.Fatal("expected scope to be closed")
+	}
+
+	f := func(context.Context) error {
+		t.Error("unexpected call")
+		return nil
+	}
+	task := s.Go(f)
+	s.Start(Service{Start: f, Stop: f})
+	s.Defer(f)
+	DeferVal(s, 1, func(ctx context.Context, _ int) error { return f(ctx) })
+	s.Pool(1).Go(f)
+
+	if err := task.Wait(context.Background()); err != ErrClosed {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(errs) != 5 {
+		t.Fatalf("unexpected number of errors: %d", len(errs))
+	}
+	for _, err := range errs {
+		if !errors.Is(err, ErrClosed) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := s.TryStart(Service{Start: f}); err != ErrClosed {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(errs) != 5 {
+		t.Fatalf("unexpected number of errors: %d", len(errs))
+	}
+}
+
+func TestScopeTryStartClosing(t *testing.T) {
+	// A Close racing with TryStart either rejects the service
+	// or stops it, but never reports the rejection.
+	for range 20 {
+		s := newScope(t)
+		var (
+			wg      sync.WaitGroup
+			started atomic.Int64
+			stopped atomic.Int64
+		)
+		for range 4 {
+			wg.Go(func() {
+				for range 100 {
+					_, err := s.TryStart(Service{
+						Start: func(ctx context.Context) error {
+							<-ctx.Done()
+							return nil
+						},
+						Stop: func(context.Context) error {
+							stopped.Add(1)
+							return nil
+						},
+					})
+					if err != nil {
+						if err != ErrClosed {
+							t.Errorf("unexpected error: %v", err)
+						}
+						return
+					}
+					started.Add(1)
+				}
+			})
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		wg.Wait()
+		if n, m := started.Load(), stopped.Load(); n != m {
+			t.Fatalf("%d services started, but %d stopped", n, m)
+		}
+	}
+}
+
+func TestScopeClosingDone(t *testing.T) {
+	s := newScope(t)
+	closing, done := s.Closing(), s.Done()
+
+	release := make(chan struct{})
+	s.Start(Service{
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Stop: func(context.Context) error {
+			<-release
+			return nil
+		},
+	})
+	select {
+	case <-closing:
+		t.Fatal("closing channel closed before close")
+	case <-done:
+		t.Fatal("done channel closed before close")
+	default:
+	}
+
+	closed := make(chan error, 1)
+	go func() { closed <- s.Close() }()
+	<-closing
+	select {
+	case <-done:
+		t.Fatal("done channel closed before the shutdown completed")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+	<-done
+	if err := <-closed; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestScopeCloseContext(t *testing.T) {
+	t.Run("finished", func(t *testing.T) {
+		s := newScope(t)
+		s.Defer(func(context.Context) error { return nil })
+		if err := s.CloseContext(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("hanging-stop", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		stopCancelled := make(chan struct{})
+		s := newScope(t)
+		s.Start(Service{
+			Name: "hanging",
+			Start: func(context.Context) error {
+				<-release
+				return nil
+			},
+		})
+		s.Start(Service{
+			Name: "stuck",
+			Start: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				<-ctx.Done()
+				close(stopCancelled)
+				<-release
+				return nil
+			},
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := s.CloseContext(ctx)
+
+		var te *ShutdownTimeoutError
+		if !errors.As(err, &te) || !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, ErrShutdownTimeout) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(err.Error(), "[hanging, stuck]") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		<-stopCancelled
+		if Cause(s.Ctx()) != (Manual{}) {
+			t.Fatalf("unexpected cause: %v", Cause(s.Ctx()))
+		}
+	})
+
+	t.Run("shutdown-timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		s := New(WithShutdownTimeout(10 * time.Millisecond))
+		s.Go(func(context.Context) error {
+			<-release
+			return nil
+		})
+
+		var te *ShutdownTimeoutError
+		if err := s.Close(); !errors.As(err, &te) || len(te.Tasks) != 1 {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestScopeShutdownContext(t *testing.T) {
+	t.Run("scope-done", func(t *testing.T) {
+		s := New(WithMaxLifetime(time.Millisecond))
+		<-s.Ctx().Done()
+
+		var stopErr error
+		s.Defer(func(ctx context.Context) error {
+			stopErr = ctx.Err()
+			if Stopping(ctx) == nil {
+				return errors.New("expected scope values")
+			}
+			return nil
+		})
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if stopErr != nil {
+			t.Fatalf("unexpected stop context error: %v", stopErr)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		s := newScope(t)
+		deadline := time.Now().Add(time.Hour)
+
+		var stopDeadline time.Time
+		s.Start(Service{
+			Start: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				stopDeadline, _ = ctx.Deadline()
+				return nil
+			},
+		})
+
+		ctx, cancel := context.WithDeadline(context.Background(), deadline)
+		defer cancel()
+		if err := s.CloseContext(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !stopDeadline.Equal(deadline) {
+			t.Fatalf("unexpected deadline: %v", stopDeadline)
+		}
+	})
+}
+
+func TestWithBoundedMemory(t *testing.T) {
+	const retain = 8
+	var errs []error
+	s := New(WithBoundedMemory(retain), WithErrorHandler(CollectErrors(&errs)))
+
+	// Sustained churn of workers, regions, and child scopes with
+	// deferred functions must not grow the retained history.
+	svc := s.Start(Service{
+		Name: "svc",
+		Start: func(ctx context.Context) error {
+			for range 10 * retain {
+				_, end := s.Region(ctx, "region")
+				end()
+			}
+			<-ctx.Done()
+			return nil
+		},
+		Stop: func(context.Context) error { return nil },
+	})
+	s.Defer(func(context.Context) error { return nil })
+	for i := range 1000 {
+		task := s.Go(func(context.Context) error {
+			if i%100 == 0 {
+				return errors.New("worker error")
+			}
+			return nil
+		})
+		c := s.Child()
+		c.Defer(func(context.Context) error { return nil })
+		if err := c.Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		<-task.Done()
+	}
+
+	// The workers, which are still running or not pruned yet, are
+	// retained in addition to the deferred function and the service.
+	s.mtx.Lock()
+	n := len(s.tasks)
+	s.mtx.Unlock()
+	if n > 2*retain+2 {
+		t.Fatalf("unexpected number of retained tasks: %d", n)
+	}
+	if regions := (*task)(svc).info().Regions; len(regions) != retain {
+		t.Fatalf("unexpected number of regions: %d", len(regions))
+	}
+	if len(s.Snapshot()) != n {
+		t.Fatal("expected snapshot to list the retained tasks")
+	}
+
+	for range 10 * retain {
+		s.Defer(func(context.Context) error { return nil })
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(errs) != 10 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if stops := s.Metrics().StopDurations; len(stops) > retain+1 {
+		t.Fatalf("unexpected stop durations: %v", stops)
+	}
+}
+
+func TestScopeCloseTwice(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	s := newScope(t)
+	s.Defer(func(context.Context) error {
+		calls.Add(1)
+		<-release
+		return nil
+	})
+
+	const n = 3
+	results := make(chan error, n)
+	for range n {
+		go func() { results <- s.Close() }()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	for range n {
+		if err := <-results; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c := calls.Load(); c != 1 {
+		t.Fatalf("unexpected number of calls: %d", c)
+	}
+}
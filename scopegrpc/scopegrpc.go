@@ -0,0 +1,83 @@
+// Package scopegrpc runs gRPC servers as services of a scope:
+//
+//	s.Start(scopegrpc.Server(func() *grpc.Server {
+//		srv := grpc.NewServer()
+//		pb.RegisterGreeterServer(srv, greeter)
+//		return srv
+//	}, func() (net.Listener, error) {
+//		return net.Listen("tcp", ":9090")
+//	}))
+package scopegrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/tsne/scope"
+	"google.golang.org/grpc"
+)
+
+// Server returns a service, which serves the servers created by newServer
+// on the listeners created by listen. Each run of the service, e.g. after
+// a restart (see scope.Scope.RollingRestart), creates a new server and
+// listener, since a server cannot serve again once it was stopped. The
+// stop function stops the server gracefully, i.e. it waits until the
+// pending RPCs are finished (see grpc.Server.GracefulStop). If the stop
+// function's context is done before, the server is stopped forcibly and
+// the context's error is returned. grpc.ErrServerStopped is not reported
+// as an error.
+func Server(newServer func() *grpc.Server, listen func() (net.Listener, error)) scope.Service {
+	if newServer == nil || listen == nil {
+		panic("scopegrpc: nil server or listen function")
+	}
+
+	var (
+		mtx sync.Mutex
+		cur *grpc.Server // server of the current run
+	)
+	return scope.Service{
+		Start: func(context.Context) error {
+			// The server is created first, so a stop function
+			// called in the meantime stops it.
+			srv := newServer()
+			mtx.Lock()
+			cur = srv
+			mtx.Unlock()
+
+			ln, err := listen()
+			if err != nil {
+				return err
+			}
+			err = srv.Serve(ln)
+			if errors.Is(err, grpc.ErrServerStopped) {
+				return nil
+			}
+			return err
+		},
+		Stop: func(ctx context.Context) error {
+			mtx.Lock()
+			srv := cur
+			mtx.Unlock()
+			if srv == nil {
+				return nil
+			}
+
+			stopped := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(stopped)
+			}()
+
+			select {
+			case <-stopped:
+				return nil
+			case <-ctx.Done():
+				srv.Stop()
+				<-stopped
+				return ctx.Err()
+			}
+		},
+	}
+}
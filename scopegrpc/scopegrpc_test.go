@@ -0,0 +1,66 @@
+package scopegrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/tsne/scope"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestServer(t *testing.T) {
+	addrs := make(chan string, 2)
+	svc := Server(func() *grpc.Server {
+		srv := grpc.NewServer()
+		healthpb.RegisterHealthServer(srv, health.NewServer())
+		return srv
+	}, func() (net.Listener, error) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err == nil {
+			addrs <- ln.Addr().String()
+		}
+		return ln, err
+	})
+	svc.Name = "grpc"
+
+	s := scope.New()
+	s.Start(svc)
+	check(t, <-addrs)
+
+	// The restarted service serves with a new server.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.RollingRestart(ctx, []string{"grpc"}, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check(t, <-addrs)
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func check(t *testing.T, addr string) {
+	t.Helper()
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("unexpected status: %v", resp.Status)
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/tsne/scope/scopelogr
+
+go 1.25.0
+
+require (
+	github.com/go-logr/logr v1.4.4
+	github.com/tsne/scope v0.0.0
+)
+
+replace github.com/tsne/scope => ../
@@ -0,0 +1,44 @@
+// Package scopelogr plugs the lifecycle logging of a scope into a
+// logr.Logger, e.g. the logger of a controller-runtime based operator.
+//
+// A controller manager and its webhook server can be managed by a scope.
+// Since the manager only returns when its context is cancelled, it is
+// started with a derived context, which will be cancelled by the
+// service's stop function:
+//
+//	s := scope.New(scopelogr.WithLogger(ctrl.Log.WithName("scope")))
+//	defer s.Close()
+//
+//	mgr, err := ctrl.NewManager(cfg, ctrl.Options{})
+//	if err != nil {
+//		return err
+//	}
+//
+//	ctx, cancel := context.WithCancel(s.Ctx())
+//	s.Start(scope.Service{
+//		Start: func(context.Context) error { return mgr.Start(ctx) },
+//		Stop: func(context.Context) error {
+//			cancel()
+//			return nil
+//		},
+//	})
+//
+// Webhooks are registered with the manager (mgr.GetWebhookServer())
+// and share its lifecycle. Additional servers, which are not owned by
+// the manager, should be added to the same scope as separate services
+// so they are stopped in reverse order of registration.
+package scopelogr
+
+import (
+	"log/slog"
+
+	"github.com/go-logr/logr"
+	"github.com/tsne/scope"
+)
+
+// WithLogger defines the logr.Logger, which will be used to log the
+// lifecycle of the scope. The logger's verbosity levels are mapped to
+// slog levels, i.e. debug messages are logged with V(4).
+func WithLogger(l logr.Logger) scope.Option {
+	return scope.WithLogger(slog.New(logr.ToSlogHandler(l)))
+}
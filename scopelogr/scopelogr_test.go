@@ -0,0 +1,35 @@
+package scopelogr
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/tsne/scope"
+)
+
+func TestWithLogger(t *testing.T) {
+	var (
+		mtx  sync.Mutex
+		logs []string
+	)
+	logger := funcr.New(func(prefix, args string) {
+		mtx.Lock()
+		logs = append(logs, args)
+		mtx.Unlock()
+	}, funcr.Options{})
+
+	s := scope.New(WithLogger(logger))
+	s.Go(func(context.Context) error { return nil })
+	if err := s.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if joined := strings.Join(logs, "\n"); !strings.Contains(joined, `"msg"="scope closed"`) {
+		t.Fatalf("expected scope closed message, got:\n%s", joined)
+	}
+}
@@ -0,0 +1,136 @@
+// Package scopeotel traces the lifecycle of a scope's services with
+// OpenTelemetry. Each run of a start function is covered by a span, and
+// each call of a stop function gets its own span, which links to the
+// span of the service's start function. Errors are recorded as span
+// events, so a slow or failed shutdown can be correlated with the
+// responsible service:
+//
+//	s := scope.New(scopeotel.WithTracerProvider(otel.GetTracerProvider()))
+//	defer s.Close()
+//
+// Regions of tasks (see scope.Scope.Region) are traced as child spans of
+// the task's span.
+package scopeotel
+
+import (
+	"context"
+	"sync"
+
+	"github.com/tsne/scope"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/tsne/scope/scopeotel"
+
+// WithTracerProvider traces the lifecycle of the scope's services with a
+// tracer of the given provider (see NewInstrumentor).
+func WithTracerProvider(tp trace.TracerProvider) scope.Option {
+	return scope.WithInstrumentor(NewInstrumentor(tp))
+}
+
+// Instrumentor is a scope.Instrumentor, which creates spans for the start
+// and stop functions of tasks as well as for their regions.
+type Instrumentor struct {
+	scope.NopInstrumentor
+	tracer trace.Tracer
+
+	mtx    sync.Mutex
+	starts map[taskKey]trace.SpanContext // spans of the running start functions
+}
+
+// taskKey identifies a task. Scopes may share an instrumentor, e.g.
+// child scopes share the one of their parent, so the index alone is not
+// unique.
+type taskKey struct {
+	scope string
+	index int
+}
+
+// NewInstrumentor returns an instrumentor, which creates its spans with a
+// tracer of the given provider.
+func NewInstrumentor(tp trace.TracerProvider) *Instrumentor {
+	if tp == nil {
+		panic("scopeotel: no tracer provider specified")
+	}
+	return &Instrumentor{
+		tracer: tp.Tracer(tracerName),
+		starts: make(map[taskKey]trace.SpanContext),
+	}
+}
+
+// TaskStarted starts the span of the task's start function.
+func (i *Instrumentor) TaskStarted(ctx context.Context, t scope.TaskInfo) context.Context {
+	ctx, span := i.tracer.Start(ctx, "scope.start "+t.String(), trace.WithAttributes(attributes(t)...))
+
+	i.mtx.Lock()
+	i.starts[key(t)] = span.SpanContext()
+	i.mtx.Unlock()
+	return ctx
+}
+
+// TaskEnded ends the span of the task's start function.
+func (i *Instrumentor) TaskEnded(ctx context.Context, t scope.TaskInfo, err error) {
+	i.mtx.Lock()
+	delete(i.starts, key(t))
+	i.mtx.Unlock()
+
+	end(trace.SpanFromContext(ctx), err)
+}
+
+// StopStarted starts the span of the task's stop function. The span links
+// to the span of the task's start function, if it is still running.
+func (i *Instrumentor) StopStarted(ctx context.Context, t scope.TaskInfo) context.Context {
+	opts := []trace.SpanStartOption{trace.WithAttributes(attributes(t)...)}
+	i.mtx.Lock()
+	sc, ok := i.starts[key(t)]
+	i.mtx.Unlock()
+	if ok {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: sc}))
+	}
+
+	ctx, _ = i.tracer.Start(ctx, "scope.stop "+t.String(), opts...)
+	return ctx
+}
+
+// StopEnded ends the span of the task's stop function.
+func (i *Instrumentor) StopEnded(ctx context.Context, _ scope.TaskInfo, err error) {
+	end(trace.SpanFromContext(ctx), err)
+}
+
+// RegionStarted starts the span of a region as a child of the current
+// span.
+func (i *Instrumentor) RegionStarted(ctx context.Context, t scope.TaskInfo, name string) context.Context {
+	ctx, _ = i.tracer.Start(ctx, "scope.region "+name, trace.WithAttributes(attributes(t)...))
+	return ctx
+}
+
+// RegionEnded ends the span of a region.
+func (i *Instrumentor) RegionEnded(ctx context.Context, _ scope.TaskInfo, _ scope.RegionInfo) {
+	trace.SpanFromContext(ctx).End()
+}
+
+func key(t scope.TaskInfo) taskKey {
+	return taskKey{scope: t.Scope, index: t.Index}
+}
+
+func attributes(t scope.TaskInfo) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attribute.Int("scope.task.index", t.Index)}
+	if t.Name != "" {
+		attrs = append(attrs, attribute.String("scope.task.name", t.Name))
+	}
+	if t.Caller != "" {
+		attrs = append(attrs, attribute.String("scope.task.caller", t.Caller))
+	}
+	return attrs
+}
+
+// end records the error, if any, and ends the span.
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
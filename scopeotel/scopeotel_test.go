@@ -0,0 +1,60 @@
+package scopeotel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tsne/scope"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestWithTracerProvider(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	var errs []error
+	s := scope.New(WithTracerProvider(tp), scope.WithErrorHandler(scope.CollectErrors(&errs)))
+
+	errStop := errors.New("stop error")
+	s.Start(scope.Service{
+		Name: "db",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Stop: func(context.Context) error { return errStop },
+	})
+	ctx := context.Background()
+	if err := s.WaitFor(ctx, "db"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.Close(); !errors.Is(err, errStop) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range rec.Ended() {
+		spans[span.Name()] = span
+	}
+	start, stop := spans["scope.start db"], spans["scope.stop db"]
+	if start == nil || stop == nil {
+		t.Fatalf("unexpected spans: %v", rec.Ended())
+	}
+
+	links := stop.Links()
+	if len(links) != 1 || links[0].SpanContext.SpanID() != start.SpanContext().SpanID() {
+		t.Fatalf("unexpected links: %v", links)
+	}
+	if st := stop.Status(); st.Code != codes.Error || st.Description != errStop.Error() {
+		t.Fatalf("unexpected status: %v", st)
+	}
+	if ev := stop.Events(); len(ev) != 1 || ev[0].Name != "exception" {
+		t.Fatalf("unexpected events: %v", ev)
+	}
+	if st := start.Status(); st.Code != codes.Unset {
+		t.Fatalf("unexpected status: %v", st)
+	}
+}
@@ -0,0 +1,184 @@
+package scopetest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tsne/scope"
+)
+
+// Fake is a fake scope, which records the calls of its methods without
+// running any Goroutines (see scope.Interface). The start functions
+// passed to Go and Start are only called when the test runs them (see
+// Call.Run). Like a scope, Close calls the deferred functions and the
+// stop functions of the services in reverse order, and cancels the
+// fake's context afterwards. Tasks, which were not run before, report
+// scope.ErrNotStarted.
+type Fake struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mtx    sync.Mutex
+	calls  []*Call
+	closed bool
+	done   chan struct{} // closed when the first Close completed
+	err    error         // result of the first Close, guarded by mtx
+}
+
+var _ scope.Interface = (*Fake)(nil)
+
+// Call is a recorded method call of a fake scope.
+type Call struct {
+	Method  string        // name of the called method, e.g. "Go" or "Start"
+	Func    scope.Func    // function passed to Go or Defer
+	Service scope.Service // service passed to Start
+	Task    *scope.Task   // task returned by Go or Start, nil otherwise
+
+	finish func(error)
+	state  atomic.Int32
+}
+
+// The states of a call's start function (see Call.Run).
+const (
+	notRun int32 = iota
+	running
+	succeeded
+	failed
+)
+
+// Run calls the start function of a recorded Go or Start call with the
+// given context, and finishes the call's task with the returned error.
+// It panics for calls of other methods.
+func (c *Call) Run(ctx context.Context) error {
+	f := c.Func
+	if c.Method == "Start" {
+		f = c.Service.Start
+	}
+	if c.Task == nil || f == nil {
+		panic("scopetest: " + c.Method + " call cannot be run")
+	}
+	c.state.Store(running)
+	err := f(ctx)
+	if err != nil {
+		c.state.Store(failed)
+	} else {
+		c.state.Store(succeeded)
+	}
+	c.finish(err)
+	return err
+}
+
+// stop returns the stop function, which Close has to call for the call.
+// Like with a scope, the stop function of a service is skipped if its
+// start function failed or was never run, unless the service's stop
+// policy is scope.AlwaysStop. The fake does not track readiness, so
+// scope.StopOnlyOnSuccess is treated like the default policy.
+func (c *Call) stop() scope.Func {
+	switch c.Method {
+	case "Defer":
+		return c.Func
+	case "Start":
+		if c.Service.StopPolicy == scope.AlwaysStop {
+			return c.Service.Stop
+		}
+		if s := c.state.Load(); s == running || s == succeeded {
+			return c.Service.Stop
+		}
+	}
+	return nil
+}
+
+// NewFake creates a fake scope, whose context is cancelled by Close.
+func NewFake() *Fake {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Fake{ctx: ctx, cancel: cancel, done: make(chan struct{})}
+}
+
+// Calls returns the recorded calls in order of occurrence.
+func (f *Fake) Calls() []*Call {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return slices.Clone(f.calls)
+}
+
+// Ctx returns the fake's context. It is cancelled by Close.
+func (f *Fake) Ctx() context.Context {
+	return f.ctx
+}
+
+// Go records the call and returns its task without calling f.
+func (f *Fake) Go(fn scope.Func, _ ...scope.StartOption) *scope.Task {
+	return f.record(&Call{Method: "Go", Func: fn}).Task
+}
+
+// Defer records the call. The function is called by Close.
+func (f *Fake) Defer(fn scope.Func) {
+	f.record(&Call{Method: "Defer", Func: fn})
+}
+
+// Start records the call and returns its task without calling the
+// service's start function. The service's stop function is called by
+// Close, if the service was run (see Call.Run).
+func (f *Fake) Start(svc scope.Service, _ ...scope.StartOption) *scope.Task {
+	return f.record(&Call{Method: "Start", Service: svc}).Task
+}
+
+// Close records the call, calls the deferred functions and the stop
+// functions of the services in reverse order, and cancels the fake's
+// context afterwards. Their errors are joined. Like with a scope,
+// subsequent calls wait until the first one completed and return its
+// result.
+func (f *Fake) Close() error {
+	f.mtx.Lock()
+	f.calls = append(f.calls, &Call{Method: "Close"})
+	if f.closed {
+		f.mtx.Unlock()
+		<-f.done
+		f.mtx.Lock()
+		defer f.mtx.Unlock()
+		return f.err
+	}
+	f.closed = true
+	calls := slices.Clone(f.calls)
+	f.mtx.Unlock()
+
+	var errs []error
+	for _, c := range slices.Backward(calls) {
+		if stop := c.stop(); stop != nil {
+			if err := stop(context.Background()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	f.cancel()
+	for _, c := range calls {
+		if c.finish != nil && c.state.Load() == notRun {
+			c.finish(scope.ErrNotStarted)
+		}
+	}
+	err := errors.Join(errs...)
+	f.mtx.Lock()
+	f.err = err
+	close(f.done)
+	f.mtx.Unlock()
+	return err
+}
+
+func (f *Fake) record(c *Call) *Call {
+	if c.Method != "Defer" {
+		c.Task, c.finish = scope.NewTask()
+	}
+
+	f.mtx.Lock()
+	f.calls = append(f.calls, c)
+	closed := f.closed
+	f.mtx.Unlock()
+
+	if closed && c.finish != nil {
+		c.finish(scope.ErrNotStarted)
+	}
+	return c
+}
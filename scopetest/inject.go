@@ -0,0 +1,70 @@
+package scopetest
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/tsne/scope"
+)
+
+// FlakyService wraps the given service, so every n-th call of its start
+// function fails immediately with the given error instead of starting the
+// service. The other calls start the service as usual. This exercises the
+// restart and escalation paths of real services (see scope.Service).
+func FlakyService(svc scope.Service, n int, err error) scope.Service {
+	if n <= 0 {
+		panic("scopetest: invalid failure interval")
+	}
+
+	var calls atomic.Int64
+	start := svc.Start
+	svc.Start = func(ctx context.Context) error {
+		if calls.Add(1)%int64(n) == 0 {
+			return err
+		}
+		return start(ctx)
+	}
+	return svc
+}
+
+// FailAfter wraps the given service, so each run of its start function
+// fails with the given error after it ran for the given duration. The
+// context of the wrapped start function is cancelled at that time and
+// the error is returned as soon as the start function returned. If the
+// start function returns before, its result is returned unchanged.
+func FailAfter(svc scope.Service, d time.Duration, err error) scope.Service {
+	start := svc.Start
+	svc.Start = func(ctx context.Context) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var failed atomic.Bool
+		timer := time.AfterFunc(d, func() {
+			failed.Store(true)
+			cancel()
+		})
+		defer timer.Stop()
+
+		res := start(ctx)
+		if failed.Load() {
+			return err
+		}
+		return res
+	}
+	return svc
+}
+
+// FailStop wraps the given service, so its stop function returns the
+// given error. The original stop function (if any) is called before, so
+// the service is still stopped.
+func FailStop(svc scope.Service, err error) scope.Service {
+	stop := svc.Stop
+	svc.Stop = func(ctx context.Context) error {
+		if stop != nil {
+			stop(ctx)
+		}
+		return err
+	}
+	return svc
+}
@@ -0,0 +1,35 @@
+package scopetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tsne/scope"
+)
+
+// NewScope creates a scope with the given options, which is bound to the
+// lifetime of the given test. Errors reported by the scope's tasks fail
+// the test, unless the options define another error handler. The scope
+// is closed when the test ends, and the test fails if the shutdown takes
+// longer than Timeout or returns an error. The scope may be closed by the
+// test before.
+func NewScope(t testing.TB, opts ...scope.Option) *scope.Scope {
+	t.Helper()
+	handler := scope.WithErrorHandler(func(err error) {
+		t.Errorf("scopetest: unexpected error: %v", err)
+	})
+	s := scope.New(append([]scope.Option{handler}, opts...)...)
+	t.Cleanup(func() { Close(t, s) })
+	return s
+}
+
+// Close closes the given scope and fails the test if the shutdown takes
+// longer than Timeout or returns an error.
+func Close(t testing.TB, s *scope.Scope) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	if err := s.CloseContext(ctx); err != nil {
+		t.Errorf("scopetest: closing scope: %v", err)
+	}
+}
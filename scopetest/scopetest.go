@@ -0,0 +1,341 @@
+// Package scopetest provides a harness for testing lifecycle scenarios of
+// a scope end-to-end. A harness runs the scope like scope.RunUntilSignal,
+// but takes the signals from the test, and records the starts and stops
+// of scripted services:
+//
+//	h := scopetest.New(t)
+//	h.Run(func(s *scope.Scope) error {
+//		db := h.Service("db")
+//		db.StopOrder = 1
+//		s.Start(db)
+//		s.Start(h.Service("api"))
+//		return nil
+//	})
+//	h.AwaitReady("db", "api")
+//	h.Signal(syscall.SIGTERM)
+//	if err := h.Wait(); err != nil {
+//		t.Fatal(err)
+//	}
+//	h.AssertStopOrder("api", "db")
+//	h.AssertShutdownWithin(time.Second)
+//
+// Real services can be wrapped to fail at controlled points, e.g. to
+// exercise restarts and shutdown paths in integration tests (see
+// FlakyService, FailAfter, and FailStop).
+//
+// Unit tests, which use a scope directly, can bind it to the test with
+// NewScope, and assert the order of starts and stops with the scripted
+// services of a harness, which does not run a scenario itself.
+// Libraries, which accept a scope.Interface, can be tested with a Fake,
+// which records the calls without running any Goroutines.
+package scopetest
+
+import (
+	"context"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tsne/scope"
+)
+
+// Timeout bounds all waiting operations of a harness, so a hanging
+// scenario fails the test instead of blocking it.
+const Timeout = 10 * time.Second
+
+// EventKind describes what happened to a scripted service.
+type EventKind int
+
+// The kinds of events recorded by a harness.
+const (
+	Started EventKind = iota // the start function was called
+	Failed                   // the start function returned an injected error
+	Stopped                  // the stop function returned
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case Started:
+		return "started"
+	case Failed:
+		return "failed"
+	case Stopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a recorded lifecycle event of a scripted service.
+type Event struct {
+	Time    time.Time
+	Service string
+	Kind    EventKind
+}
+
+func (e Event) String() string {
+	return e.Service + " " + e.Kind.String()
+}
+
+// Harness runs a scope and records the lifecycle events of its scripted
+// services (see Harness.Service). A harness is created with New and runs
+// a single scenario. Without a scenario, its scripted services can be
+// started on any scope, e.g. one created with NewScope.
+type Harness struct {
+	t    testing.TB
+	sigs chan os.Signal
+	done chan struct{}
+
+	mtx      sync.Mutex
+	s        *scope.Scope
+	services map[string]*service
+	events   []Event
+	signaled time.Time
+	finished time.Time
+	err      error
+}
+
+type service struct {
+	fail      chan error
+	stopDelay time.Duration
+}
+
+// New creates a harness for the given test. If the scenario is still
+// running when the test ends, it is shut down with os.Interrupt.
+func New(t testing.TB) *Harness {
+	h := &Harness{
+		t:        t,
+		sigs:     make(chan os.Signal),
+		done:     make(chan struct{}),
+		services: make(map[string]*service),
+	}
+	t.Cleanup(func() {
+		if h.Scope() == nil {
+			return
+		}
+		select {
+		case <-h.done:
+		default:
+			h.Signal(os.Interrupt)
+			h.Wait()
+		}
+	})
+	return h
+}
+
+// Run runs a new scope with the given options in the background and calls
+// setup to register the scope's functions (see scope.RunUntil). The scope
+// is closed when a signal is sent (see Signal) or its context is done.
+func (h *Harness) Run(setup func(*scope.Scope) error, opts ...scope.Option) {
+	h.t.Helper()
+	if h.Scope() != nil {
+		h.t.Fatal("scopetest: harness already running")
+	}
+
+	ready := make(chan struct{})
+	go func() {
+		err := scope.RunUntil(h.sigs, func(s *scope.Scope) error {
+			h.mtx.Lock()
+			h.s = s
+			h.mtx.Unlock()
+			close(ready)
+			return setup(s)
+		}, opts...)
+
+		h.mtx.Lock()
+		h.err = err
+		h.finished = time.Now()
+		h.mtx.Unlock()
+		close(h.done)
+	}()
+	<-ready
+}
+
+// Scope returns the scope of the running scenario, or nil if Run was not
+// called yet.
+func (h *Harness) Scope() *scope.Scope {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.s
+}
+
+// Service returns a scripted service with the given name. Its start
+// function records the event Started and blocks until its context is
+// done or a failure is injected (see Fail). Its stop function records
+// the event Stopped. The returned service can be adjusted, e.g. its
+// stop order or restart policy, before it is started.
+func (h *Harness) Service(name string) scope.Service {
+	svc := h.service(name)
+	return scope.Service{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			h.record(name, Started)
+			select {
+			case <-ctx.Done():
+				return nil
+			case err := <-svc.fail:
+				h.record(name, Failed)
+				return err
+			}
+		},
+		Stop: func(ctx context.Context) error {
+			h.mtx.Lock()
+			delay := svc.stopDelay
+			h.mtx.Unlock()
+			if delay > 0 {
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+				}
+			}
+			h.record(name, Stopped)
+			return nil
+		},
+	}
+}
+
+// DelayStop lets the stop function of the scripted service with the
+// given name take the given duration, e.g. to test shutdown timeouts.
+func (h *Harness) DelayStop(name string, d time.Duration) {
+	svc := h.service(name)
+	h.mtx.Lock()
+	svc.stopDelay = d
+	h.mtx.Unlock()
+}
+
+// Fail lets the start function of the scripted service with the given
+// name return the given error. The call blocks until the start function
+// took the error.
+func (h *Harness) Fail(name string, err error) {
+	h.t.Helper()
+	select {
+	case h.service(name).fail <- err:
+	case <-time.After(Timeout):
+		h.t.Fatalf("scopetest: service %q did not take the failure", name)
+	}
+}
+
+// Signal sends the given signal to the running scenario. The first signal
+// closes the scope, a second one forces the shutdown (see
+// scope.RunUntilSignal). Signals sent after the scope was closed are
+// dropped.
+func (h *Harness) Signal(sig os.Signal) {
+	h.mtx.Lock()
+	if h.signaled.IsZero() {
+		h.signaled = time.Now()
+	}
+	h.mtx.Unlock()
+
+	select {
+	case h.sigs <- sig:
+	case <-h.done:
+	}
+}
+
+// AwaitReady fails the test if the services with the given names do not
+// report ready in time (see scope.Scope.WaitFor).
+func (h *Harness) AwaitReady(names ...string) {
+	h.t.Helper()
+	s := h.Scope()
+	if s == nil {
+		h.t.Fatal("scopetest: harness not running")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	if err := s.WaitFor(ctx, names...); err != nil {
+		h.t.Fatalf("scopetest: services %v not ready: %v", names, err)
+	}
+}
+
+// Wait waits until the scenario finished and returns the combined error
+// of the setup and the shutdown. It fails the test if the scenario does
+// not finish in time.
+func (h *Harness) Wait() error {
+	h.t.Helper()
+	select {
+	case <-h.done:
+	case <-time.After(Timeout):
+		h.t.Fatal("scopetest: scenario did not finish")
+	}
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.err
+}
+
+// Events returns the recorded events in the order they happened.
+func (h *Harness) Events() []Event {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return slices.Clone(h.events)
+}
+
+// AssertStopOrder fails the test if the scripted services were not
+// stopped in exactly the given order.
+func (h *Harness) AssertStopOrder(names ...string) {
+	h.t.Helper()
+	var stopped []string
+	for _, e := range h.Events() {
+		if e.Kind == Stopped {
+			stopped = append(stopped, e.Service)
+		}
+	}
+	if !slices.Equal(stopped, names) {
+		h.t.Fatalf("scopetest: unexpected stop order\n got: %v\nwant: %v", stopped, names)
+	}
+}
+
+// AssertOrder fails the test unless the given events were recorded in
+// the given order. The events are given in their string representation,
+// e.g. "db started" or "api stopped" (see Event). Other events may be
+// recorded in between.
+func (h *Harness) AssertOrder(events ...string) {
+	h.t.Helper()
+	recorded := h.Events()
+	i := 0
+	for _, e := range recorded {
+		if i < len(events) && e.String() == events[i] {
+			i++
+		}
+	}
+	if i < len(events) {
+		h.t.Fatalf("scopetest: event %q not recorded in order: %v", events[i], recorded)
+	}
+}
+
+// AssertShutdownWithin fails the test if the scenario took longer than
+// the given duration to finish after the first signal was sent.
+func (h *Harness) AssertShutdownWithin(d time.Duration) {
+	h.t.Helper()
+	h.Wait()
+
+	h.mtx.Lock()
+	signaled, finished := h.signaled, h.finished
+	h.mtx.Unlock()
+	if signaled.IsZero() {
+		h.t.Fatal("scopetest: no signal sent")
+	}
+	if took := finished.Sub(signaled); took > d {
+		h.t.Fatalf("scopetest: shutdown took %v, want at most %v", took, d)
+	}
+}
+
+func (h *Harness) service(name string) *service {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	svc := h.services[name]
+	if svc == nil {
+		svc = &service{fail: make(chan error)}
+		h.services[name] = svc
+	}
+	return svc
+}
+
+func (h *Harness) record(name string, kind EventKind) {
+	h.mtx.Lock()
+	h.events = append(h.events, Event{Time: time.Now(), Service: name, Kind: kind})
+	h.mtx.Unlock()
+}
@@ -0,0 +1,276 @@
+package scopetest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tsne/scope"
+)
+
+func TestHarness(t *testing.T) {
+	t.Run("signal", func(t *testing.T) {
+		h := New(t)
+		h.Run(func(s *scope.Scope) error {
+			db := h.Service("db")
+			db.StopOrder = 1
+			s.Start(db)
+			s.Start(h.Service("api"))
+			return nil
+		})
+		h.AwaitReady("db", "api")
+
+		h.Signal(os.Interrupt)
+		if err := h.Wait(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		h.AssertStopOrder("api", "db")
+		h.AssertShutdownWithin(time.Second)
+		if c := scope.Cause(h.Scope().Ctx()); c != (scope.SignalReceived{Signal: os.Interrupt}) {
+			t.Fatalf("unexpected cause: %v", c)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		var errs []error
+		h := New(t)
+		h.Run(func(s *scope.Scope) error {
+			s.Start(h.Service("db"))
+			return nil
+		}, scope.WithFailFast(), scope.WithErrorHandler(scope.CollectErrors(&errs)))
+		h.AwaitReady("db")
+
+		errDB := errors.New("connection lost")
+		h.Fail("db", errDB)
+		if err := h.Wait(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(errs) != 1 || !errors.Is(errs[0], errDB) {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+
+		var kinds []EventKind
+		for _, e := range h.Events() {
+			kinds = append(kinds, e.Kind)
+		}
+		if len(kinds) != 2 || kinds[0] != Started || kinds[1] != Failed {
+			t.Fatalf("unexpected events: %v", h.Events())
+		}
+	})
+
+	t.Run("forced", func(t *testing.T) {
+		h := New(t)
+		h.DelayStop("db", time.Hour)
+		h.Run(func(s *scope.Scope) error {
+			s.Start(h.Service("db"))
+			return nil
+		})
+		h.AwaitReady("db")
+
+		h.Signal(os.Interrupt)
+		h.Signal(os.Interrupt)
+		var te *scope.ShutdownTimeoutError
+		if err := h.Wait(); !errors.As(err, &te) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		h.AssertShutdownWithin(time.Second)
+	})
+}
+
+func TestFailureInjection(t *testing.T) {
+	errInjected := errors.New("injected error")
+
+	t.Run("flaky", func(t *testing.T) {
+		h := New(t)
+		h.Run(func(s *scope.Scope) error {
+			svc := FlakyService(h.Service("db"), 2, errInjected)
+			svc.Restart = scope.RestartAlways
+			svc.Backoff = scope.Backoff{Initial: time.Millisecond}
+			s.Start(svc)
+			return nil
+		}, scope.WithErrorHandler(func(err error) { t.Errorf("unexpected error: %v", err) }))
+
+		// The second start fails and the service is restarted.
+		h.AwaitReady("db")
+		h.Fail("db", errInjected)
+		waitEvents(t, h, 2)
+		h.Signal(os.Interrupt)
+		if err := h.Wait(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("fail-after", func(t *testing.T) {
+		errs := make(chan error, 1)
+		h := New(t)
+		h.Run(func(s *scope.Scope) error {
+			s.Start(FailAfter(h.Service("db"), time.Millisecond, errInjected))
+			return nil
+		}, scope.WithErrorHandler(func(err error) { errs <- err }))
+
+		select {
+		case err := <-errs:
+			if !errors.Is(err, errInjected) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		case <-time.After(Timeout):
+			t.Fatal("expected service to fail")
+		}
+		h.Signal(os.Interrupt)
+		if err := h.Wait(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("fail-stop", func(t *testing.T) {
+		h := New(t)
+		h.Run(func(s *scope.Scope) error {
+			s.Start(FailStop(h.Service("db"), errInjected))
+			return nil
+		})
+
+		h.AwaitReady("db")
+		h.Signal(os.Interrupt)
+		if err := h.Wait(); !errors.Is(err, errInjected) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		h.AssertStopOrder("db")
+	})
+}
+
+// waitEvents waits until the harness recorded the given number of
+// start events.
+func waitEvents(t *testing.T, h *Harness, starts int) {
+	t.Helper()
+	deadline := time.Now().Add(Timeout)
+	for time.Now().Before(deadline) {
+		n := 0
+		for _, e := range h.Events() {
+			if e.Kind == Started {
+				n++
+			}
+		}
+		if n >= starts {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d starts: %v", starts, h.Events())
+}
+
+func TestNewScope(t *testing.T) {
+	h := New(t)
+	s := NewScope(t)
+	db := h.Service("db")
+	db.StopOrder = 1
+	s.Start(db)
+	s.Start(h.Service("api"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	if err := s.WaitFor(ctx, "db", "api"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	Close(t, s)
+	h.AssertOrder("db started", "api stopped", "db stopped")
+	h.AssertOrder("api started", "api stopped")
+	h.AssertStopOrder("api", "db")
+}
+
+func TestFake(t *testing.T) {
+	errStop := errors.New("stop failed")
+	var (
+		mtx     sync.Mutex
+		stopped []string
+	)
+	stop := func(name string, err error) scope.Func {
+		return func(context.Context) error {
+			mtx.Lock()
+			stopped = append(stopped, name)
+			mtx.Unlock()
+			return err
+		}
+	}
+
+	f := NewFake()
+	task := f.Go(func(context.Context) error { return nil })
+	f.Defer(stop("defer", nil))
+	db := f.Start(scope.Service{
+		Name: "db",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			if f.Ctx().Err() != nil {
+				t.Error("context cancelled before stop")
+			}
+			return stop("db", errStop)(ctx)
+		},
+	})
+	f.Start(scope.Service{
+		Name:  "failed",
+		Start: func(context.Context) error { return errors.New("start failed") },
+		Stop:  stop("failed", nil),
+	})
+	unused := f.Start(scope.Service{
+		Name:  "unused",
+		Start: func(context.Context) error { return nil },
+		Stop:  stop("unused", nil),
+	})
+	f.Start(scope.Service{
+		Name:       "always",
+		Start:      func(context.Context) error { return nil },
+		Stop:       stop("always", nil),
+		StopPolicy: scope.AlwaysStop,
+	})
+
+	calls := f.Calls()
+	if len(calls) != 6 || calls[0].Method != "Go" || calls[1].Method != "Defer" || calls[2].Service.Name != "db" {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+	if err := calls[0].Run(f.Ctx()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case <-task.Done():
+	default:
+		t.Fatal("task not finished")
+	}
+	if err := calls[3].Run(f.Ctx()); err == nil {
+		t.Fatal("expected error")
+	}
+	go calls[2].Run(f.Ctx())
+	for calls[2].state.Load() != running {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := f.Close(); !errors.Is(err, errStop) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.Ctx().Err() == nil {
+		t.Fatal("context not cancelled")
+	}
+	if !slices.Equal(stopped, []string{"always", "db", "defer"}) {
+		t.Fatalf("unexpected stops: %v", stopped)
+	}
+	if err := f.Close(); !errors.Is(err, errStop) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stopped) != 3 {
+		t.Fatalf("unexpected stops: %v", stopped)
+	}
+	if err := task.Err(); err != nil {
+		t.Fatalf("unexpected task error: %v", err)
+	}
+	if err := unused.Err(); err != scope.ErrNotStarted {
+		t.Fatalf("unexpected service error: %v", err)
+	}
+	if err := db.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected service error: %v", err)
+	}
+}
@@ -0,0 +1,304 @@
+//go:build unix
+
+// Package scopeupgrade supports zero-downtime binary upgrades of processes
+// managed by a scope. The listening sockets of the running process are
+// passed to a re-executed child process. Once the child reported ready,
+// the old process drains by closing its scope:
+//
+//	s := scope.New()
+//	u, err := scopeupgrade.New(s)
+//	if err != nil {
+//		return err
+//	}
+//
+//	ln, err := u.Listen("tcp", ":8080")
+//	if err != nil {
+//		return err
+//	}
+//	srv := &http.Server{Handler: handler}
+//	s.Start(scope.Service{
+//		Start: func(context.Context) error { srv.Serve(ln); return nil },
+//		Stop:  srv.Shutdown,
+//	})
+//	if err := u.Ready(); err != nil {
+//		return err
+//	}
+//
+//	hup, stop := scope.SignalChan(syscall.SIGHUP)
+//	defer stop()
+//	for {
+//		select {
+//		case <-hup:
+//			if err := u.Upgrade(s.Ctx()); err != nil {
+//				log.Printf("upgrade failed: %v", err)
+//			}
+//			continue
+//		case <-u.Exit():
+//		case <-s.Ctx().Done():
+//		}
+//		return s.Close()
+//	}
+package scopeupgrade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tsne/scope"
+)
+
+const (
+	listenersEnv = "SCOPE_UPGRADE_LISTENERS"
+	readyEnv     = "SCOPE_UPGRADE_READY"
+)
+
+// Upgrader hands over listening sockets to a new process.
+type Upgrader struct {
+	command func() *exec.Cmd // creates the child process, can be replaced in tests
+
+	mtx       sync.Mutex
+	inherited map[string]*os.File     // inherited but unused sockets
+	listeners map[string]net.Listener // sockets passed to the child on upgrade
+	ready     *os.File                // signals the parent, nil if the process was not upgraded
+	upgrading bool
+	exit      chan struct{}
+}
+
+// New creates an upgrader, which adopts the sockets inherited from a
+// parent process, if any. Inherited sockets, which are not requested
+// via Listen, are closed when the scope is closed.
+func New(s *scope.Scope) (*Upgrader, error) {
+	u := &Upgrader{
+		command:   defaultCommand,
+		inherited: make(map[string]*os.File),
+		listeners: make(map[string]net.Listener),
+		exit:      make(chan struct{}),
+	}
+	if err := u.inherit(os.Getenv(listenersEnv), os.Getenv(readyEnv)); err != nil {
+		return nil, err
+	}
+	s.Defer(func(context.Context) error {
+		u.mtx.Lock()
+		defer u.mtx.Unlock()
+
+		for key, f := range u.inherited {
+			f.Close()
+			delete(u.inherited, key)
+		}
+		return nil
+	})
+	return u, nil
+}
+
+// inherit adopts the sockets and the readiness pipe passed by a parent
+// process. The listeners are encoded as comma-separated "fd:network:addr"
+// entries.
+func (u *Upgrader) inherit(listeners, ready string) error {
+	if listeners != "" {
+		for _, entry := range strings.Split(listeners, ",") {
+			fdstr, key, ok := strings.Cut(entry, ":")
+			fd, err := strconv.Atoi(fdstr)
+			if !ok || err != nil {
+				return fmt.Errorf("scopeupgrade: invalid inherited listener %q", entry)
+			}
+			u.inherited[key] = os.NewFile(uintptr(fd), key)
+		}
+	}
+	if ready != "" {
+		fd, err := strconv.Atoi(ready)
+		if err != nil {
+			return fmt.Errorf("scopeupgrade: invalid readiness pipe %q", ready)
+		}
+		u.ready = os.NewFile(uintptr(fd), "ready")
+	}
+	return nil
+}
+
+// Listen returns a listener for the given network and address. If the
+// parent process passed a socket for the same network and address, the
+// socket is adopted. Otherwise a new socket is created (see net.Listen).
+// The listener is passed to the child process on upgrade.
+func (u *Upgrader) Listen(network, addr string) (net.Listener, error) {
+	key := network + ":" + addr
+
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	if _, ok := u.listeners[key]; ok {
+		return nil, fmt.Errorf("scopeupgrade: duplicate listener %s", key)
+	}
+
+	var (
+		l   net.Listener
+		err error
+	)
+	if f, ok := u.inherited[key]; ok {
+		delete(u.inherited, key)
+		l, err = net.FileListener(f)
+		f.Close()
+	} else {
+		l, err = net.Listen(network, addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	u.listeners[key] = l
+	return l, nil
+}
+
+// Ready reports the parent process, that the process is ready to serve.
+// The parent then drains (see Exit). If the process was not started by
+// an upgrade, Ready does nothing.
+func (u *Upgrader) Ready() error {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	if u.ready == nil {
+		return nil
+	}
+	_, err := u.ready.Write([]byte{1})
+	if cerr := u.ready.Close(); err == nil {
+		err = cerr
+	}
+	u.ready = nil
+	return err
+}
+
+// Upgrade starts a new instance of the current executable with the same
+// arguments and passes all listeners to it. It blocks until the child
+// reported ready (see Ready), the child exited, or the context is done.
+// In the latter case the child is killed. After a successful upgrade the
+// exit channel is closed (see Exit) and the process should close its
+// scope to drain.
+func (u *Upgrader) Upgrade(ctx context.Context) error {
+	u.mtx.Lock()
+	if u.upgrading {
+		u.mtx.Unlock()
+		return errors.New("scopeupgrade: upgrade in progress")
+	}
+	u.upgrading = true
+	cmd, files, err := u.prepare()
+	u.mtx.Unlock()
+
+	defer func() {
+		u.mtx.Lock()
+		u.upgrading = false
+		u.mtx.Unlock()
+	}()
+	if err != nil {
+		return err
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		closeFiles(files)
+		return err
+	}
+	defer r.Close()
+
+	// The child's extra files start at descriptor 3.
+	cmd.ExtraFiles = append(files, w)
+	cmd.Env = append(cmd.Env, readyEnv+"="+strconv.Itoa(3+len(files)))
+	err = cmd.Start()
+	closeFiles(cmd.ExtraFiles)
+	if err != nil {
+		return fmt.Errorf("scopeupgrade: start child: %w", err)
+	}
+
+	ready := make(chan bool, 1)
+	go func() {
+		var buf [1]byte
+		n, _ := r.Read(buf[:])
+		ready <- n == 1
+	}()
+
+	select {
+	case ok := <-ready:
+		if !ok {
+			cmd.Wait()
+			return errors.New("scopeupgrade: child exited before it was ready")
+		}
+		go cmd.Wait()
+		close(u.exit)
+		return nil
+	case <-ctx.Done():
+		cmd.Process.Kill()
+		cmd.Wait()
+		return ctx.Err()
+	}
+}
+
+// prepare creates the child's command and duplicates the sockets of all
+// listeners. It must be called with the mutex held.
+func (u *Upgrader) prepare() (*exec.Cmd, []*os.File, error) {
+	select {
+	case <-u.exit:
+		return nil, nil, errors.New("scopeupgrade: already upgraded")
+	default:
+	}
+
+	var (
+		files   []*os.File
+		entries []string
+	)
+	for key, l := range u.listeners {
+		fl, ok := l.(interface{ File() (*os.File, error) })
+		if !ok {
+			closeFiles(files)
+			return nil, nil, fmt.Errorf("scopeupgrade: cannot pass listener %s of type %T", key, l)
+		}
+		f, err := fl.File()
+		if err != nil {
+			closeFiles(files)
+			return nil, nil, fmt.Errorf("scopeupgrade: listener %s: %w", key, err)
+		}
+		// The child's extra files start at descriptor 3.
+		entries = append(entries, strconv.Itoa(3+len(files))+":"+key)
+		files = append(files, f)
+	}
+
+	cmd := u.command()
+	for _, env := range cmd.Env {
+		if strings.HasPrefix(env, listenersEnv+"=") || strings.HasPrefix(env, readyEnv+"=") {
+			closeFiles(files)
+			return nil, nil, errors.New("scopeupgrade: child environment already contains upgrade variables")
+		}
+	}
+	cmd.Env = append(cmd.Env, listenersEnv+"="+strings.Join(entries, ","))
+	return cmd, files, nil
+}
+
+// Exit returns a channel, which is closed when the process was upgraded
+// successfully. The process should close its scope afterwards.
+func (u *Upgrader) Exit() <-chan struct{} {
+	return u.exit
+}
+
+func defaultCommand() *exec.Cmd {
+	exe, err := os.Executable()
+	if err != nil {
+		exe = os.Args[0]
+	}
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, listenersEnv+"=") && !strings.HasPrefix(env, readyEnv+"=") {
+			cmd.Env = append(cmd.Env, env)
+		}
+	}
+	return cmd
+}
+
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
@@ -0,0 +1,133 @@
+//go:build unix
+
+package scopeupgrade
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/tsne/scope"
+)
+
+const helperEnv = "SCOPEUPGRADE_TEST_HELPER"
+
+func TestUpgrade(t *testing.T) {
+	s := scope.New()
+	u, err := New(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ln, err := u.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	u.command = func() *exec.Cmd {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+		cmd.Env = append(os.Environ(), helperEnv+"=1")
+		cmd.Stderr = os.Stderr
+		return cmd
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := u.Upgrade(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case <-u.Exit():
+	default:
+		t.Fatal("expected exit channel to be closed")
+	}
+
+	// The parent stops accepting, so the connection
+	// must be served by the child.
+	addr := ln.Addr().String()
+	ln.Close()
+	if err := s.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if line != "child\n" {
+		t.Fatalf("unexpected response: %q", line)
+	}
+}
+
+func TestUpgradeNotInherited(t *testing.T) {
+	s := scope.New()
+	u, err := New(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := u.Ready(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := u.Listen("tcp", "127.0.0.1:0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := u.Listen("tcp", "127.0.0.1:0"); err == nil {
+		t.Fatal("expected error for duplicate listener")
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestHelperProcess is the child process of TestUpgrade. It serves a
+// single connection on the inherited listener.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv(helperEnv) != "1" {
+		t.Skip("helper process")
+	}
+
+	u, err := New(scope.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(u.inherited) != 1 {
+		t.Fatalf("unexpected inherited listeners: %v", u.inherited)
+	}
+
+	var key string
+	for key = range u.inherited {
+	}
+	network, addr, _ := cutKey(key)
+	ln, err := u.Listen(network, addr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := u.Ready(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conn.Write([]byte("child\n"))
+	conn.Close()
+	os.Exit(0)
+}
+
+func cutKey(key string) (string, string, bool) {
+	for i := range key {
+		if key[i] == ':' {
+			return key[:i], key[i+1:], true
+		}
+	}
+	return "", "", false
+}
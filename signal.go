@@ -0,0 +1,166 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"time"
+)
+
+// SignalPolicy maps signals to the actions, which a scope takes when it
+// receives them (see WithSignalPolicy):
+//
+//	scope.WithSignalPolicy(scope.SignalPolicy{
+//		syscall.SIGHUP:  scope.Reload(reloadConfig),
+//		syscall.SIGTERM: scope.Graceful,
+//		syscall.SIGINT:  scope.Graceful,
+//		syscall.SIGQUIT: scope.Immediate,
+//		syscall.SIGUSR2: scope.Upgrade(upgrader.Upgrade),
+//	})
+type SignalPolicy map[os.Signal]SignalAction
+
+// SignalAction defines how a scope reacts to a signal (see SignalPolicy).
+type SignalAction struct {
+	kind signalKind
+	f    Func
+}
+
+type signalKind int
+
+const (
+	gracefulSignal signalKind = iota + 1
+	drainSignal
+	immediateSignal
+	ignoreSignal
+	reloadSignal
+	upgradeSignal
+)
+
+var (
+	// Graceful ends the scope's context with the cause SignalReceived,
+	// so the owner of the scope closes it gracefully (see CloseOn).
+	Graceful = SignalAction{kind: gracefulSignal}
+	// GracefulDrain drains the scope (see Drain) and ends the scope's
+	// context with the cause SignalReceived as soon as all tasks have
+	// finished.
+	GracefulDrain = SignalAction{kind: drainSignal}
+	// Immediate ends the scope's context with the cause SignalReceived
+	// and closes the scope right away without waiting for its stop
+	// functions: they are called with a done context, so they should
+	// abandon work in progress. A subsequent Close of the owner waits
+	// until the shutdown completed.
+	Immediate = SignalAction{kind: immediateSignal}
+	// Ignore drops the signal.
+	Ignore = SignalAction{kind: ignoreSignal}
+)
+
+// Reload returns an action, which calls f with the scope's context, e.g.
+// to reload the configuration. If f returns an error, it will be reported
+// by the scope's error handler. The scope keeps running in either case.
+// Signals, which are received while f is running, are handled afterwards.
+func Reload(f Func) SignalAction {
+	if f == nil {
+		panic("scope: nil reload function")
+	}
+	return SignalAction{kind: reloadSignal, f: f}
+}
+
+// Upgrade returns an action, which calls f with the scope's context, e.g.
+// to hand the work over to a new process (see package scopeupgrade). If
+// f succeeds, the scope's context ends with the cause SignalReceived like
+// with Graceful. Otherwise the error will be reported by the scope's
+// error handler and the scope keeps running.
+func Upgrade(f Func) SignalAction {
+	if f == nil {
+		panic("scope: nil upgrade function")
+	}
+	return SignalAction{kind: upgradeSignal, f: f}
+}
+
+func (a SignalAction) String() string {
+	switch a.kind {
+	case gracefulSignal:
+		return "graceful"
+	case drainSignal:
+		return "drain"
+	case immediateSignal:
+		return "immediate"
+	case ignoreSignal:
+		return "ignore"
+	case reloadSignal:
+		return "reload"
+	case upgradeSignal:
+		return "upgrade"
+	default:
+		return "unknown"
+	}
+}
+
+// watchSignals handles the signals of the scope's signal policy until the
+// scope is closed. Signals, which are received after the scope's context
+// is done, are left to the owner of the scope (see RunUntil). The context
+// and its cancel function are captured up front, so a later Reset does
+// not affect the Goroutine.
+func (s *Scope) watchSignals() {
+	if len(s.opts.signals) == 0 || isClosed(s.closing) {
+		return
+	}
+
+	ctx, cancel, closing := s.ctx, s.cancel, s.closing
+	ch, stop := SignalChan(slices.Collect(maps.Keys(s.opts.signals))...)
+	s.spawn(func() {
+		defer stop()
+		for {
+			select {
+			case sig := <-ch:
+				if ctx.Err() == nil {
+					s.handleSignal(ctx, cancel, sig)
+				}
+			case <-closing:
+				return
+			}
+		}
+	})
+}
+
+func (s *Scope) handleSignal(ctx context.Context, cancel context.CancelCauseFunc, sig os.Signal) {
+	action := s.opts.signals[sig]
+	s.record("signal", nil, "signal", sig, "action", action)
+	s.log(slog.LevelInfo, "signal received", "signal", sig, "action", action)
+
+	switch action.kind {
+	case ignoreSignal:
+	case reloadSignal:
+		if err := protect(ctx, action.f); err != nil && ctx.Err() == nil {
+			s.onError(fmt.Errorf("scope: reload on signal %v: %w", sig, err))
+		}
+	case upgradeSignal:
+		if err := protect(ctx, action.f); err != nil {
+			if ctx.Err() == nil {
+				s.onError(fmt.Errorf("scope: upgrade on signal %v: %w", sig, err))
+			}
+			return
+		}
+		cancel(SignalReceived{Signal: sig})
+	case drainSignal:
+		s.spawn(func() {
+			// The errors of the tasks were reported already.
+			s.Drain(ctx)
+			cancel(SignalReceived{Signal: sig})
+		})
+	case immediateSignal:
+		cancel(SignalReceived{Signal: sig})
+		s.spawn(func() {
+			// The shutdown context is expired already, so Close
+			// returns right away. The error was logged by Close.
+			expired, cancel := context.WithDeadline(context.Background(), time.Now())
+			defer cancel()
+			s.CloseContext(expired)
+		})
+	default:
+		cancel(SignalReceived{Signal: sig})
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package scope
+
+import (
+	"os"
+	"syscall"
+)
+
+// stopSignal asks a subprocess to stop (see Command).
+var stopSignal os.Signal = syscall.SIGTERM
@@ -0,0 +1,199 @@
+//go:build unix
+
+package scope
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestWithSignalPolicy(t *testing.T) {
+	kill := func(t *testing.T, sig syscall.Signal) {
+		if err := syscall.Kill(syscall.Getpid(), sig); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	awaitDone := func(t *testing.T, s *Scope, sig os.Signal) {
+		select {
+		case <-s.Ctx().Done():
+		case <-time.After(time.Second):
+			t.Fatal("context not done")
+		}
+		var cause SignalReceived
+		if !errors.As(Cause(s.Ctx()), &cause) || cause.Signal != sig {
+			t.Fatalf("unexpected cause: %v", Cause(s.Ctx()))
+		}
+	}
+
+	t.Run("graceful", func(t *testing.T) {
+		s := New(WithSignalPolicy(SignalPolicy{syscall.SIGUSR2: Graceful}))
+		kill(t, syscall.SIGUSR2)
+		awaitDone(t, s, syscall.SIGUSR2)
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		errReload := errors.New("reload failed")
+		errs := make(chan error, 1)
+		reloaded := make(chan error)
+		s := New(
+			WithErrorHandler(func(err error) { errs <- err }),
+			WithSignalPolicy(SignalPolicy{syscall.SIGUSR2: Reload(func(context.Context) error {
+				return <-reloaded
+			})}),
+		)
+		kill(t, syscall.SIGUSR2)
+		reloaded <- nil
+		kill(t, syscall.SIGUSR2)
+		reloaded <- errReload
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := <-errs; !errors.Is(err, errReload) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := Cause(s.Ctx()).(SignalReceived); ok {
+			t.Fatal("unexpected signal cause")
+		}
+	})
+
+	t.Run("children", func(t *testing.T) {
+		reloaded := make(chan struct{}, 3)
+		s := New(WithSignalPolicy(SignalPolicy{syscall.SIGUSR2: Reload(func(context.Context) error {
+			reloaded <- struct{}{}
+			return nil
+		})}))
+		s.Child()
+		s.Child()
+
+		kill(t, syscall.SIGUSR2)
+		select {
+		case <-reloaded:
+		case <-time.After(time.Second):
+			t.Fatal("scope not reloaded")
+		}
+		select {
+		case <-reloaded:
+			t.Fatal("unexpected reload")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("upgrade", func(t *testing.T) {
+		errUpgrade := errors.New("upgrade failed")
+		errs := make(chan error, 1)
+		upgraded := make(chan error)
+		s := New(
+			WithErrorHandler(func(err error) { errs <- err }),
+			WithSignalPolicy(SignalPolicy{syscall.SIGUSR2: Upgrade(func(context.Context) error {
+				return <-upgraded
+			})}),
+		)
+		kill(t, syscall.SIGUSR2)
+		upgraded <- errUpgrade
+		kill(t, syscall.SIGUSR2)
+		if s.Ctx().Err() != nil {
+			t.Fatal("unexpected done context after failed upgrade")
+		}
+		upgraded <- nil
+		awaitDone(t, s, syscall.SIGUSR2)
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := <-errs; !errors.Is(err, errUpgrade) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("drain", func(t *testing.T) {
+		s := New(WithSignalPolicy(SignalPolicy{syscall.SIGUSR2: GracefulDrain}))
+		finish := make(chan struct{})
+		s.Go(func(context.Context) error {
+			<-finish
+			return nil
+		})
+		kill(t, syscall.SIGUSR2)
+		for !s.IsDraining() {
+			time.Sleep(time.Millisecond)
+		}
+		if s.Ctx().Err() != nil {
+			t.Fatal("unexpected done context while draining")
+		}
+		close(finish)
+		awaitDone(t, s, syscall.SIGUSR2)
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("immediate", func(t *testing.T) {
+		s := New(WithSignalPolicy(SignalPolicy{syscall.SIGUSR2: Immediate}))
+		stopped := make(chan error, 1)
+		s.Defer(func(ctx context.Context) error {
+			stopped <- ctx.Err()
+			return nil
+		})
+		kill(t, syscall.SIGUSR2)
+		awaitDone(t, s, syscall.SIGUSR2)
+
+		select {
+		case err := <-stopped:
+			if err == nil {
+				t.Fatal("expected done stop context")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("scope not closed")
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("run", func(t *testing.T) {
+		reloaded := make(chan struct{}, 1)
+		sigs := make(chan os.Signal)
+		done := make(chan error, 1)
+		go func() {
+			done <- RunUntil(sigs, func(*Scope) error { return nil }, WithSignalPolicy(SignalPolicy{
+				syscall.SIGUSR2: Reload(func(context.Context) error {
+					reloaded <- struct{}{}
+					return nil
+				}),
+			}))
+		}()
+
+		// The policy's signals are left to the scope.
+		sigs <- syscall.SIGUSR2
+		select {
+		case err := <-done:
+			t.Fatalf("unexpected return: %v", err)
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		sigs <- syscall.SIGTERM
+		if err := <-done; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected panic")
+			}
+		}()
+		New(WithSignalPolicy(SignalPolicy{syscall.SIGUSR2: {}}))
+	})
+}
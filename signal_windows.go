@@ -0,0 +1,7 @@
+package scope
+
+import "os"
+
+// stopSignal asks a subprocess to stop (see Command). Windows does not
+// support sending signals to other processes, so it is killed.
+var stopSignal = os.Kill
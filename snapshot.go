@@ -0,0 +1,77 @@
+package scope
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TaskSnapshot describes a registered task at the time of a snapshot
+// (see Scope.Snapshot).
+type TaskSnapshot struct {
+	Index    int
+	Name     string
+	State    string        // "pending", "running", "paused", "quarantined", "succeeded", "failed", or "skipped"
+	Deferred bool          // true for cleanup functions (see Scope.Defer)
+	HasStop  bool          // true if the task has a stop function
+	Stopped  bool          // true if the stop function was called
+	Started  time.Time     // time the start function was called last (zero if never started)
+	Err      error         // error of the failed start function
+	Reason   string        // reason of the quarantine (see Admin.Quarantine)
+	WallTime time.Duration // total run time of the start function (see WithTaskAccounting)
+}
+
+// String returns a stable, human-readable representation of the task.
+func (ts TaskSnapshot) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#%d", ts.Index)
+	if ts.Name != "" {
+		fmt.Fprintf(&b, " %s", ts.Name)
+	}
+	if ts.Deferred {
+		b.WriteString(" deferred")
+	} else {
+		fmt.Fprintf(&b, " %s", ts.State)
+	}
+	if ts.HasStop {
+		b.WriteString(" stop")
+	}
+	if ts.Stopped {
+		b.WriteString(" stopped")
+	}
+	return b.String()
+}
+
+// Snapshot returns the registered tasks in order of registration. It can
+// be used to find failed or hanging services, e.g. during a shutdown.
+// The states are only deterministic while the scope is frozen (see
+// Freeze). The string representation of a snapshot does not include
+// times or errors, so it can be compared against a golden file.
+func (s *Scope) Snapshot() []TaskSnapshot {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := time.Now()
+	snap := make([]TaskSnapshot, len(s.tasks))
+	for i, t := range s.tasks {
+		snap[i] = TaskSnapshot{
+			Index:    t.idx,
+			Name:     t.name,
+			State:    t.state.String(),
+			Deferred: t.deferred(),
+			HasStop:  t.stop != nil,
+			Stopped:  t.stopped.Load(),
+		}
+		if t.state.is(failed) {
+			snap[i].Err = t.err
+		}
+		if t.quarantine != "" && t.state.is(paused) {
+			snap[i].State, snap[i].Reason = "quarantined", t.quarantine
+		}
+		t.mtx.Lock()
+		snap[i].Started = t.startTime
+		snap[i].WallTime = t.wallTimeLocked(now)
+		t.mtx.Unlock()
+	}
+	return snap
+}
@@ -0,0 +1,43 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestScopeSnapshot(t *testing.T) {
+	errStart := errors.New("start error")
+	s := New(WithErrorHandler(func(error) {}))
+	s.Start(Service{Name: "db", Start: func(context.Context) error { return errStart }})
+	s.Start(Service{
+		Name: "api",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Stop: nopFunc,
+	})
+	if err := s.WaitFor(context.Background(), "api"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.WaitFor(context.Background(), "db"); !errors.Is(err, errStart) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	snap := s.Snapshot()
+	if got := fmt.Sprint(snap); got != "[#0 db failed #1 api running stop]" {
+		t.Fatalf("unexpected snapshot: %s", got)
+	}
+	if snap[0].Err != errStart || snap[0].Started.IsZero() || snap[1].Err != nil {
+		t.Fatalf("unexpected snapshot: %+v", snap)
+	}
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := fmt.Sprint(s.Snapshot()); got != "[#0 db failed #1 api succeeded stop stopped]" {
+		t.Fatalf("unexpected snapshot: %s", got)
+	}
+}
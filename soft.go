@@ -0,0 +1,39 @@
+package scope
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type closingKey struct{}
+
+// Stopping returns a channel, which is closed when the scope of the given
+// context starts closing. It is closed before the context is cancelled,
+// so tasks can finish their current work, e.g. a batch item, and return
+// afterwards (see WithSoftCancel). If the context does not belong to a
+// scope, the returned channel is never closed.
+func Stopping(ctx context.Context) <-chan struct{} {
+	ch, _ := ctx.Value(closingKey{}).(chan struct{})
+	return ch
+}
+
+// awaitSoftCancel waits until all start functions returned, the given
+// duration elapsed, or the context is done.
+func (s *Scope) awaitSoftCancel(ctx context.Context, d time.Duration) {
+	idle := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(idle)
+	}()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-idle:
+	case <-timer.C:
+		s.log(slog.LevelWarn, "soft cancel period exceeded", "period", d, "running", s.running.Load())
+		s.record("timeout", nil, "phase", PhaseSoftCancel, "period", d)
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,60 @@
+package scope
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStopping(t *testing.T) {
+	t.Run("soft-cancel", func(t *testing.T) {
+		items := make(chan int, 3)
+		for i := range cap(items) {
+			items <- i
+		}
+
+		processed := 0
+		s := New(WithSoftCancel(time.Second))
+		s.Go(func(ctx context.Context) error {
+			for {
+				select {
+				case <-Stopping(ctx):
+					return nil
+				case <-items:
+					if ctx.Err() != nil {
+						t.Errorf("unexpected context error: %v", ctx.Err())
+					}
+					processed++
+				}
+			}
+		})
+
+		for len(items) > 0 {
+			time.Sleep(time.Millisecond)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if processed != 3 {
+			t.Fatalf("unexpected number of processed items: %d", processed)
+		}
+	})
+
+	t.Run("hard-cancel", func(t *testing.T) {
+		s := New(WithSoftCancel(time.Millisecond))
+		s.Go(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("no-scope", func(t *testing.T) {
+		if ch := Stopping(context.Background()); ch != nil {
+			t.Fatal("expected nil channel")
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package scope
+
+// StopPolicy defines whether the stop function of a service is called
+// when the scope is closed, depending on the outcome of its start
+// function (see Service and WithStopPolicy).
+type StopPolicy int
+
+const (
+	// SkipStopOnFailure calls the stop function unless the start function
+	// failed or was never called. This is the default policy.
+	SkipStopOnFailure StopPolicy = iota + 1
+	// AlwaysStop calls the stop function regardless of the outcome of the
+	// start function, even if it failed or was never called, e.g. to
+	// release resources, which were acquired before the service was
+	// started.
+	AlwaysStop
+	// StopOnlyOnSuccess calls the stop function only if the service
+	// started successfully, i.e. it reported ready (see Service.Ready)
+	// and its start function did not fail.
+	StopOnlyOnSuccess
+)
+
+// shouldStop reports whether the stop function of the task has to be
+// called according to its stop policy. Deferred functions are always
+// called, and services without a start function never.
+func (s *Scope) shouldStop(t *task) bool {
+	switch {
+	case t.deferred():
+		return true
+	case t.svc.Start == nil:
+		return false
+	}
+
+	policy := t.svc.StopPolicy
+	if policy == 0 {
+		policy = s.opts.stopPolicy
+	}
+	switch policy {
+	case AlwaysStop:
+		return true
+	case StopOnlyOnSuccess:
+		s.mtx.Lock()
+		ready := t.ready
+		s.mtx.Unlock()
+		return ready && t.started()
+	default:
+		return t.started()
+	}
+}
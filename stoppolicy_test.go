@@ -0,0 +1,103 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStopPolicy(t *testing.T) {
+	errStart := errors.New("start failed")
+	failing := func(context.Context) error { return errStart }
+	blocking := func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}
+	neverReady := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := map[string]struct {
+		opts    []Option
+		svc     Service
+		failed  bool
+		stopped bool
+	}{
+		"default-failed": {
+			svc:     Service{Start: failing},
+			failed:  true,
+			stopped: false,
+		},
+		"default-running": {
+			svc:     Service{Start: blocking},
+			stopped: true,
+		},
+		"always-failed": {
+			opts:    []Option{WithStopPolicy(AlwaysStop)},
+			svc:     Service{Start: failing},
+			failed:  true,
+			stopped: true,
+		},
+		"success-not-ready": {
+			opts:    []Option{WithStopPolicy(StopOnlyOnSuccess)},
+			svc:     Service{Start: blocking, Ready: neverReady},
+			stopped: false,
+		},
+		"success-ready": {
+			opts:    []Option{WithStopPolicy(StopOnlyOnSuccess)},
+			svc:     Service{Start: blocking},
+			stopped: true,
+		},
+		"service-override": {
+			opts:    []Option{WithStopPolicy(AlwaysStop)},
+			svc:     Service{Start: failing, StopPolicy: SkipStopOnFailure},
+			failed:  true,
+			stopped: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var errs []error
+			s := New(append(test.opts, WithErrorHandler(CollectErrors(&errs)))...)
+
+			stop := newCall(nil)
+			svc := test.svc
+			svc.Name = "svc"
+			svc.Stop = stop.f
+			waiting := make(chan struct{})
+			if ready := svc.Ready; ready != nil {
+				svc.Ready = func(ctx context.Context) error {
+					close(waiting)
+					return ready(ctx)
+				}
+			}
+			h := s.Start(svc)
+			switch {
+			case test.failed:
+				<-h.Done()
+			case svc.Ready != nil:
+				<-waiting
+			default:
+				if err := s.WaitFor(context.Background(), "svc"); err != nil {
+					t.Fatalf("unexpected wait error: %v", err)
+				}
+			}
+
+			closeScope(s)
+			if called := stop.called(); called != test.stopped {
+				t.Fatalf("unexpected stop call: %v", called)
+			}
+		})
+	}
+}
+
+func TestWithStopPolicy(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	New(WithStopPolicy(StopPolicy(42)))
+}
@@ -0,0 +1,94 @@
+package scope
+
+import (
+	"context"
+	"slices"
+)
+
+// TaskState is the state of a task as reported by Task.Subscribe. The
+// values match the states of a TaskSnapshot.
+type TaskState string
+
+// The states of a task.
+const (
+	TaskPending   TaskState = "pending"   // waiting for a free slot or a worker
+	TaskRunning   TaskState = "running"   // the start function is running
+	TaskPaused    TaskState = "paused"    // stopped until it is resumed (see Admin.Pause)
+	TaskSucceeded TaskState = "succeeded" // the start function returned without an error
+	TaskFailed    TaskState = "failed"    // the start function returned an error
+	TaskSkipped   TaskState = "skipped"   // the task was never started
+)
+
+// Subscribe returns a channel, which receives the state of the task on
+// each transition, starting with the current state. This allows other
+// components to react when a dependency fails or stops. A slow receiver
+// does not block the task: undelivered states are replaced by the latest
+// one, so the last value received is always the current state. The
+// channel is closed when the task finished (see Done) or the context is
+// done.
+func (h *Task) Subscribe(ctx context.Context) <-chan TaskState {
+	t := (*task)(h)
+	ch := make(chan TaskState, 1)
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	ch <- TaskState(t.state.String())
+	if t.finished || ctx.Err() != nil {
+		close(ch)
+		return ch
+	}
+	t.subs = append(t.subs, ch)
+	t.hasSubs.Store(true)
+
+	context.AfterFunc(ctx, func() {
+		t.mtx.Lock()
+		defer t.mtx.Unlock()
+		if i := slices.Index(t.subs, ch); i >= 0 {
+			t.subs = slices.Delete(t.subs, i, i+1)
+			close(ch)
+		}
+	})
+	return ch
+}
+
+// setState sets the state of the task and notifies the subscribers (see
+// Task.Subscribe).
+func (t *task) setState(v state) {
+	t.state.set(v)
+	if !t.hasSubs.Load() {
+		return
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	st := TaskState(t.state.String())
+	for _, ch := range t.subs {
+		publish(ch, st)
+	}
+}
+
+// closeSubsLocked closes the channels of all subscribers. The task's
+// mutex must be held.
+func (t *task) closeSubsLocked() {
+	for _, ch := range t.subs {
+		close(ch)
+	}
+	t.subs = nil
+}
+
+// publish sends the state to the channel with a buffer of one, replacing
+// an undelivered state. Only one goroutine may publish at a time.
+func publish(ch chan TaskState, st TaskState) {
+	for {
+		select {
+		case ch <- st:
+			return
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+		}
+	}
+}
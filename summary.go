@@ -0,0 +1,100 @@
+package scope
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// Summary describes what a scope manages, e.g. to log it at startup (see
+// Scope.Summary and WithStartupSummary).
+type Summary struct {
+	Name            string           // name of the scope (see WithName)
+	Phases          []string         // phases of Close in order of execution (see OptionsView)
+	ShutdownTimeout time.Duration    // maximum duration of Close, zero if unbounded
+	Services        []ServiceSummary // named services in registration order
+}
+
+// ServiceSummary describes a single service of a scope (see Summary).
+type ServiceSummary struct {
+	Name        string
+	State       string        // state of the task (see TaskSnapshot)
+	StopOrder   int           // see Service.StopOrder
+	DependsOn   []string      // see Service.DependsOn
+	Restart     RestartPolicy // see Service.Restart
+	StopTimeout time.Duration // see StopTimeout, zero if unbounded
+}
+
+// LogValue returns the summary as a single group, so it can be logged as
+// one record, e.g. logger.Info("scope ready", "summary", s.Summary()).
+func (sum Summary) LogValue() slog.Value {
+	services := make([]slog.Attr, 0, len(sum.Services))
+	for _, svc := range sum.Services {
+		attrs := []slog.Attr{
+			slog.String("state", svc.State),
+			slog.Int("stop_order", svc.StopOrder),
+			slog.String("restart", svc.Restart.String()),
+		}
+		if len(svc.DependsOn) > 0 {
+			attrs = append(attrs, slog.Any("depends_on", svc.DependsOn))
+		}
+		if svc.StopTimeout > 0 {
+			attrs = append(attrs, slog.Duration("stop_timeout", svc.StopTimeout))
+		}
+		services = append(services, slog.Attr{Key: svc.Name, Value: slog.GroupValue(attrs...)})
+	}
+
+	attrs := []slog.Attr{slog.Any("phases", sum.Phases)}
+	if sum.Name != "" {
+		attrs = append(attrs, slog.String("name", sum.Name))
+	}
+	if sum.ShutdownTimeout > 0 {
+		attrs = append(attrs, slog.Duration("shutdown_timeout", sum.ShutdownTimeout))
+	}
+	attrs = append(attrs, slog.Attr{Key: "services", Value: slog.GroupValue(services...)})
+	return slog.GroupValue(attrs...)
+}
+
+// Summary returns a summary of the services, which are currently
+// registered with the scope. Unnamed functions (see Go) and deferred
+// functions are not included.
+func (s *Scope) Summary() Summary {
+	view := s.Options()
+	sum := Summary{
+		Name:            view.Name,
+		Phases:          view.Phases,
+		ShutdownTimeout: view.ShutdownTimeout,
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, t := range s.tasks {
+		if t.name == "" || t.deferred() {
+			continue
+		}
+		sum.Services = append(sum.Services, ServiceSummary{
+			Name:        t.name,
+			State:       t.state.String(),
+			StopOrder:   t.svc.StopOrder,
+			DependsOn:   slices.Clone(t.svc.DependsOn),
+			Restart:     t.svc.Restart,
+			StopTimeout: t.opts.stopTimeout,
+		})
+	}
+	return sum
+}
+
+// summarizeLocked emits the startup summary when the scope becomes ready for
+// the first time (see WithStartupSummary). The ready mutex must be held.
+func (s *Scope) summarizeLocked() {
+	if s.summarized || !s.opts.summary {
+		return
+	}
+	s.summarized = true
+
+	sum := s.Summary()
+	s.log(slog.LevelInfo, "scope ready", "summary", sum)
+	if s.opts.onSummary != nil {
+		s.opts.onSummary(sum)
+	}
+}
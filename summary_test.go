@@ -0,0 +1,70 @@
+package scope
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithStartupSummary(t *testing.T) {
+	var buf bytes.Buffer
+	summaries := make(chan Summary, 2)
+	s := New(
+		WithName("app"),
+		WithLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+		WithStartupSummary(func(sum Summary) { summaries <- sum }),
+	)
+
+	ready := make(chan struct{})
+	block := func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}
+	s.Start(Service{Name: "db", Start: block, Ready: func(context.Context) error {
+		<-ready
+		return nil
+	}, StopOrder: 1})
+	s.Start(Service{Name: "api", Start: block, DependsOn: []string{"db"}, Restart: RestartOnFailure}, StopTimeout(time.Second))
+	s.Go(block)
+	s.Defer(func(context.Context) error { return nil })
+	// The children do not emit a summary of their own.
+	s.Child().Start(Service{Name: "cache", Start: block})
+	close(ready)
+
+	var sum Summary
+	select {
+	case sum = <-summaries:
+	case <-time.After(time.Second):
+		t.Fatal("no summary emitted")
+	}
+	if sum.Name != "app" || len(sum.Services) != 2 {
+		t.Fatalf("unexpected summary: %+v", sum)
+	}
+	db, api := sum.Services[0], sum.Services[1]
+	switch {
+	case db.Name != "db" || db.StopOrder != 1 || db.Restart != RestartNever:
+		t.Fatalf("unexpected service summary: %+v", db)
+	case api.Name != "api" || !slices.Equal(api.DependsOn, []string{"db"}) || api.Restart != RestartOnFailure || api.StopTimeout != time.Second:
+		t.Fatalf("unexpected service summary: %+v", api)
+	}
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(summaries) != 0 {
+		t.Fatal("unexpected second summary")
+	}
+	var line string
+	for _, l := range strings.Split(buf.String(), "\n") {
+		if strings.Contains(l, "scope ready") {
+			line = l
+		}
+	}
+	if !strings.Contains(line, "summary.services.api.restart=on-failure") || !strings.Contains(line, "summary.services.db.stop_order=1") {
+		t.Fatalf("unexpected log record: %q", line)
+	}
+}
@@ -0,0 +1,168 @@
+package scope
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// RestartPolicy defines when the start function of a service is called
+// again (see Service).
+type RestartPolicy int
+
+const (
+	// RestartNever never restarts a service.
+	RestartNever RestartPolicy = iota
+	// RestartOnFailure restarts a service if its start function
+	// returned an error.
+	RestartOnFailure
+	// RestartAlways restarts a service whenever its start function
+	// returned before the scope was closed.
+	RestartAlways
+)
+
+func (p RestartPolicy) String() string {
+	switch p {
+	case RestartNever:
+		return "never"
+	case RestartOnFailure:
+		return "on-failure"
+	case RestartAlways:
+		return "always"
+	default:
+		return "unknown"
+	}
+}
+
+// Backoff defines the delays between the restarts of a service or the
+// retries of a function (see Retry). The delay starts at Initial and is
+// multiplied by Multiplier after each restart, up to Max. Each delay is
+// randomized by up to ±Jitter of the delay using the scope's source of
+// randomness (see WithRand).
+type Backoff struct {
+	Initial     time.Duration // delay before the first restart (default 100ms)
+	Max         time.Duration // maximum delay (default 30s)
+	Multiplier  float64       // growth factor of the delay (default 2)
+	Jitter      float64       // randomization factor in [0,1] (default 0)
+	MaxAttempts int           // maximum number of restarts (0 means unlimited, see ErrRestartBudgetExceeded)
+}
+
+// delay returns the delay before the given restart, starting at 0.
+func (b Backoff) delay(restart int, rand *random) time.Duration {
+	initial := cmp.Or(b.Initial, 100*time.Millisecond)
+	limit := cmp.Or(b.Max, 30*time.Second)
+	mult := cmp.Or(b.Multiplier, 2)
+
+	d := float64(initial)
+	for range restart {
+		if d *= mult; d >= float64(limit) {
+			d = float64(limit)
+			break
+		}
+	}
+	return rand.jitter(time.Duration(d), b.Jitter)
+}
+
+// shouldRestart reports whether the task has to be restarted according
+// to its restart policy after its start function returned the given
+// error. It waits for the backoff delay and aborts if the task's context
+// is done before.
+func (s *Scope) shouldRestart(ctx context.Context, t *task, err error, restarts int) bool {
+	switch {
+	case ctx.Err() != nil || s.IsDraining():
+		return false
+	case t.svc.Restart == RestartOnFailure && err == nil:
+		return false
+	case t.svc.Restart != RestartOnFailure && t.svc.Restart != RestartAlways:
+		return false
+	case t.svc.Backoff.MaxAttempts > 0 && restarts >= t.svc.Backoff.MaxAttempts:
+		return false
+	}
+
+	// Persisted failures of previous processes continue
+	// the backoff (see WithBackoffStore).
+	delay := t.svc.Backoff.delay(max(restarts, t.failures-1), s.rand)
+	s.log(slog.LevelWarn, "task restarting", "task", t, "attempt", restarts+1, "delay", delay, "error", err)
+	s.record("restart", t, "attempt", restarts+1, "delay", delay, "error", err)
+
+	// The task is not ready before it was restarted.
+	s.mtx.Lock()
+	t.ready, t.readyErr = false, nil
+	s.notifyLocked()
+	s.mtx.Unlock()
+	s.updateReadiness()
+
+	t.restarting.Store(true)
+	defer t.restarting.Store(false)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// BackoffStore persists the number of consecutive failures of services
+// by their names. This allows a process, which is restarted by its
+// orchestrator after a fatal error, to back off before it starts a
+// failing service again (see WithBackoffStore). The implementation
+// must be safe for concurrent use.
+type BackoffStore interface {
+	// Load returns the number of consecutive failures of the service
+	// with the given name, or zero if nothing was stored.
+	Load(name string) (int, error)
+	// Save stores the number of consecutive failures of the service
+	// with the given name.
+	Save(name string, failures int) error
+}
+
+// awaitFailures loads the persisted failures of the task and waits for
+// the backoff delay before the task is started again. It aborts if the
+// task's context is done before.
+func (s *Scope) awaitFailures(ctx context.Context, t *task) {
+	store := s.opts.backoffStore
+	if store == nil || t.name == "" {
+		return
+	}
+
+	failures, err := store.Load(t.name)
+	if err != nil {
+		s.log(slog.LevelWarn, "loading task failures failed", "task", t, "error", err)
+	}
+	if t.failures = max(failures, 0); t.failures == 0 {
+		return
+	}
+
+	delay := t.svc.Backoff.delay(t.failures-1, s.rand)
+	s.log(slog.LevelWarn, "task start delayed", "task", t, "failures", t.failures, "delay", delay)
+	s.record("delayed", t, "failures", t.failures, "delay", delay)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// recordFailure counts the consecutive failures of the task and persists
+// them (see WithBackoffStore). The failures are reset when the start
+// function succeeded or was stopped.
+func (s *Scope) recordFailure(ctx context.Context, t *task, err error) {
+	if err != nil && ctx.Err() == nil {
+		t.failures++
+	} else {
+		t.failures = 0
+	}
+
+	store := s.opts.backoffStore
+	if store == nil || t.name == "" {
+		return
+	}
+	if err := store.Save(t.name, t.failures); err != nil {
+		s.log(slog.LevelWarn, "saving task failures failed", "task", t, "error", err)
+	}
+}
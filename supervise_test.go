@@ -0,0 +1,120 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestServiceRestart(t *testing.T) {
+	t.Run("exhausted", func(t *testing.T) {
+		var errs []error
+		errStart := errors.New("start error")
+
+		starts := 0
+		s := New(WithErrorHandler(CollectErrors(&errs)))
+		s.Start(Service{
+			Name: "flaky",
+			Start: func(context.Context) error {
+				starts++
+				return errStart
+			},
+			Restart: RestartOnFailure,
+			Backoff: Backoff{Initial: time.Millisecond, MaxAttempts: 2},
+		})
+
+		if err := s.WaitFor(context.Background(), "flaky"); !errors.Is(err, errStart) || !errors.Is(err, ErrStartupFailed) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if starts != 3 {
+			t.Fatalf("unexpected number of starts: %d", starts)
+		}
+		if len(errs) != 1 || !errors.Is(errs[0], errStart) || !errors.Is(errs[0], ErrRestartBudgetExceeded) {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+	})
+
+	t.Run("recovered", func(t *testing.T) {
+		starts := 0
+		s := newScope(t)
+		s.Start(Service{
+			Name: "flaky",
+			Start: func(ctx context.Context) error {
+				if starts++; starts < 3 {
+					return errors.New("start error")
+				}
+				<-ctx.Done()
+				return nil
+			},
+			Restart: RestartOnFailure,
+			Backoff: Backoff{Initial: time.Millisecond},
+		})
+
+		if err := s.WaitFor(context.Background(), "flaky"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if starts != 3 {
+			t.Fatalf("unexpected number of starts: %d", starts)
+		}
+	})
+}
+
+func TestBackoffDelay(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: 5 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, d := range expected {
+		if got := b.delay(i, newRandom(nil)); got != d {
+			t.Fatalf("unexpected delay of restart %d: %v", i, got)
+		}
+	}
+}
+
+func TestWithBackoffStore(t *testing.T) {
+	store := NewFileBackoffStore(filepath.Join(t.TempDir(), "backoff.json"))
+	backoff := Backoff{Initial: 50 * time.Millisecond}
+	errStart := errors.New("start error")
+
+	// The first process crashes.
+	var errs []error
+	s := New(WithBackoffStore(store), WithErrorHandler(CollectErrors(&errs)))
+	s.Start(Service{
+		Name:    "db",
+		Start:   func(context.Context) error { return errStart },
+		Backoff: backoff,
+	})
+	if err := s.WaitFor(context.Background(), "db"); !errors.Is(err, errStart) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if failures, err := store.Load("db"); err != nil || failures != 1 {
+		t.Fatalf("unexpected failures: %d (%v)", failures, err)
+	}
+
+	// The next process backs off before it starts the service.
+	s = New(WithBackoffStore(store))
+	started := time.Now()
+	start := newCall(nil)
+	s.Start(Service{Name: "db", Start: start.f, Backoff: backoff})
+	if err := start.wait(time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := time.Since(started); d < backoff.Initial {
+		t.Fatalf("service started after %v, expected a backoff of %v", d, backoff.Initial)
+	}
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if failures, err := store.Load("db"); err != nil || failures != 0 {
+		t.Fatalf("unexpected failures: %d (%v)", failures, err)
+	}
+}
@@ -0,0 +1,109 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// Suspend calls the suspend functions of the running services with the
+// given names, or of all running services if no name is given (see
+// Service). Unlike Admin.Pause, the services keep running, but are
+// expected to stop pulling work, e.g. during a maintenance window or a
+// configuration reload, until they are unsuspended (see Unsuspend). The
+// services are suspended in stop order, so consumers are suspended
+// before their providers. Services, which are suspended already, are
+// skipped. It is an error to suspend a service by name, which has no
+// suspend function. The errors of the suspend functions are returned as
+// Errors of type *TaskError.
+func (s *Scope) Suspend(ctx context.Context, names ...string) error {
+	tasks, err := s.suspendable(names)
+	if err != nil {
+		return err
+	}
+
+	var errs Errors
+	for _, t := range stopOrder(tasks) {
+		if !s.setSuspended(t, true) {
+			continue
+		}
+		s.log(slog.LevelInfo, "suspending task", "task", t)
+		if err := protect(s.withValues(t.context(ctx)), t.svc.Suspend); err != nil {
+			s.setSuspended(t, false)
+			errs.append(&TaskError{Task: t.info(), Err: err})
+		}
+	}
+	return errs.err()
+}
+
+// Unsuspend calls the unsuspend functions of the suspended services with
+// the given names, or of all suspended services if no name is given (see
+// Suspend). The services are unsuspended in the reverse order they were
+// suspended in, so providers are unsuspended before their consumers.
+// Services, which are not suspended, are skipped. The errors of the
+// unsuspend functions are returned as Errors of type *TaskError; the
+// respective services stay suspended.
+func (s *Scope) Unsuspend(ctx context.Context, names ...string) error {
+	tasks, err := s.suspendable(names)
+	if err != nil {
+		return err
+	}
+
+	tasks = stopOrder(tasks)
+	slices.Reverse(tasks)
+	var errs Errors
+	for _, t := range tasks {
+		if !s.setSuspended(t, false) {
+			continue
+		}
+		s.log(slog.LevelInfo, "unsuspending task", "task", t)
+		if t.svc.Unsuspend == nil {
+			continue
+		}
+		if err := protect(s.withValues(t.context(ctx)), t.svc.Unsuspend); err != nil {
+			s.setSuspended(t, true)
+			errs.append(&TaskError{Task: t.info(), Err: err})
+		}
+	}
+	return errs.err()
+}
+
+// suspendable returns the running services with the given names, or all
+// running services with a suspend function if no name is given.
+func (s *Scope) suspendable(names []string) ([]*task, error) {
+	if len(names) > 0 {
+		tasks, err := s.lookup(names)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tasks {
+			if t.svc.Suspend == nil {
+				return nil, fmt.Errorf("scope: service %q has no suspend function", t)
+			}
+		}
+		return slices.DeleteFunc(tasks, func(t *task) bool { return !t.state.is(running) }), nil
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var tasks []*task
+	for _, t := range s.tasks {
+		if t.svc.Suspend != nil && t.state.is(running) {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks, nil
+}
+
+// setSuspended marks the task as suspended or unsuspended. It reports
+// whether the mark changed.
+func (s *Scope) setSuspended(t *task, suspended bool) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if t.suspended == suspended {
+		return false
+	}
+	t.suspended = suspended
+	return true
+}
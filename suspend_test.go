@@ -0,0 +1,108 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestScopeSuspend(t *testing.T) {
+	s := newScope(t)
+	defer closeScope(s)
+
+	var events []string
+	service := func(name string) Service {
+		return Service{
+			Name: name,
+			Start: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			Suspend: func(context.Context) error {
+				events = append(events, "suspend "+name)
+				return nil
+			},
+			Unsuspend: func(context.Context) error {
+				events = append(events, "unsuspend "+name)
+				return nil
+			},
+		}
+	}
+	s.Start(service("db"))
+	s.Start(service("api"))
+	s.Start(Service{
+		Name: "plain",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+	})
+
+	ctx := context.Background()
+	if err := s.WaitFor(ctx, "db", "api", "plain"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := s.Suspend(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.Suspend(ctx, "api"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.Unsuspend(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.Unsuspend(ctx, "db"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(events, []string{"suspend api", "suspend db", "unsuspend db", "unsuspend api"}) {
+		t.Fatalf("unexpected events: %v", events)
+	}
+
+	if err := s.Suspend(ctx, "plain"); err == nil {
+		t.Fatal("error expected")
+	}
+	if err := s.Suspend(ctx, "unknown"); err == nil {
+		t.Fatal("error expected")
+	}
+}
+
+func TestScopeSuspendError(t *testing.T) {
+	s := newScope(t)
+	defer closeScope(s)
+
+	errSuspend := errors.New("suspend error")
+	fail := true
+	s.Start(Service{
+		Name: "worker",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Suspend: func(context.Context) error {
+			if fail {
+				return errSuspend
+			}
+			return nil
+		},
+	})
+
+	ctx := context.Background()
+	if err := s.WaitFor(ctx, "worker"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var taskErr *TaskError
+	if err := s.Suspend(ctx); !errors.As(err, &taskErr) || !errors.Is(err, errSuspend) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The service is not suspended, so it can be suspended again.
+	fail = false
+	if err := s.Suspend(ctx, "worker"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.Unsuspend(ctx, "worker"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrTopicClosed is returned when a message is published to a topic
+// whose scope is closing.
+var ErrTopicClosed = errors.New("scope: topic closed")
+
+// Topic is a typed publish/subscribe channel between the tasks of a
+// scope. Each subscription is a consumer managed by the scope (see
+// Consume), which is stopped when the scope is closed. Publishing
+// never blocks beyond the closing of the scope, so publishers and
+// subscribers can be stopped in any order without a deadlock.
+//
+// A topic is bound to the scope it was created with and must not be
+// used after the scope was reset.
+type Topic[T any] struct {
+	s      *Scope
+	buffer int
+
+	mtx  sync.RWMutex
+	subs []chan T
+}
+
+// NewTopic creates a new topic for the given scope. Each subscription
+// buffers up to the given number of messages.
+func NewTopic[T any](s *Scope, buffer int) *Topic[T] {
+	return &Topic[T]{s: s, buffer: max(buffer, 0)}
+}
+
+// Subscribe starts a consumer, which calls handle for each message
+// published to the topic. Only messages, which are published after
+// Subscribe returned, are received. The options define how the consumer
+// treats buffered messages when the scope is closed (see Consume).
+func (t *Topic[T]) Subscribe(handle func(context.Context, T) error, o ...ConsumeOption) *Consumer {
+	ch := make(chan T, t.buffer)
+
+	t.mtx.Lock()
+	t.subs = append(t.subs, ch)
+	t.mtx.Unlock()
+
+	return Consume(t.s, ch, handle, o...)
+}
+
+// Publish delivers the given message to all subscribers. It blocks until
+// the message was passed to every subscription. If the scope is closing,
+// ErrTopicClosed is returned. If the context is done before, the
+// context's error is returned. In both cases the message may have been
+// delivered to some of the subscribers.
+func (t *Topic[T]) Publish(ctx context.Context, v T) error {
+	t.s.mtx.Lock()
+	closing := t.s.closing
+	t.s.mtx.Unlock()
+
+	t.mtx.RLock()
+	subs := t.subs
+	t.mtx.RUnlock()
+
+	for _, ch := range subs {
+		if isClosed(closing) {
+			return ErrTopicClosed
+		}
+
+		select {
+		case ch <- v:
+		case <-closing:
+			return ErrTopicClosed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
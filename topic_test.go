@@ -0,0 +1,84 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestTopic(t *testing.T) {
+	t.Run("publish", func(t *testing.T) {
+		var (
+			wg  sync.WaitGroup
+			mtx sync.Mutex
+			got = map[string]int{}
+		)
+		s := newScope(t)
+		topic := NewTopic[int](s, 0)
+		for _, name := range []string{"a", "b"} {
+			topic.Subscribe(func(_ context.Context, v int) error {
+				mtx.Lock()
+				got[name] += v
+				mtx.Unlock()
+				wg.Done()
+				return nil
+			})
+		}
+
+		wg.Add(6)
+		for v := 1; v <= 3; v++ {
+			if err := topic.Publish(context.Background(), v); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		wg.Wait()
+
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got["a"] != 6 || got["b"] != 6 {
+			t.Fatalf("unexpected messages: %v", got)
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		s := newScope(t)
+		topic := NewTopic[int](s, 0)
+		topic.Subscribe(func(context.Context, int) error { return nil })
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := topic.Publish(context.Background(), 1); !errors.Is(err, ErrTopicClosed) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("blocked-publisher", func(t *testing.T) {
+		block := make(chan struct{})
+		s := newScope(t)
+		topic := NewTopic[int](s, 0)
+		s.Go(func(ctx context.Context) error {
+			for {
+				if err := topic.Publish(ctx, 1); err != nil {
+					if errors.Is(err, ErrTopicClosed) {
+						return nil
+					}
+					return err
+				}
+			}
+		})
+		topic.Subscribe(func(context.Context, int) error {
+			<-block
+			return nil
+		}, WithDrainPolicy(Abandon))
+
+		closed := make(chan error, 1)
+		go func() { closed <- closeScope(s) }()
+		close(block)
+		if err := <-closed; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package scope
+
+import (
+	"context"
+	"log/slog"
+)
+
+// CloseOn ends the scope's context with the cause Triggered as soon as
+// the given channel is closed or receives a value. This allows to drive
+// the shutdown by arbitrary events, e.g. the loss of a leadership or an
+// upgrade notification. Like with other causes (see WithMaxLifetime), the
+// owner of the scope still needs to call Close once the context is done,
+// which RunUntilSignal does. If the scope is closed first, the trigger is
+// ignored.
+func (s *Scope) CloseOn(trigger <-chan struct{}) {
+	s.watchTrigger(func(ctx context.Context) error {
+		select {
+		case <-trigger:
+		case <-ctx.Done():
+		}
+		return nil
+	})
+}
+
+// CloseOnFunc calls f in a new Goroutine with the scope's context and
+// ends the context with the cause Triggered when f returns. This allows
+// to wait for events, which are not available as a channel, e.g. a
+// control-plane RPC (see CloseOn). The returned error, if any, is recorded
+// in the cause. The function must return when its context is done, in
+// which case its result is ignored. Close does not wait for f.
+func (s *Scope) CloseOnFunc(f Func) {
+	s.watchTrigger(f)
+}
+
+// watchTrigger calls wait in a new Goroutine and cancels the scope's
+// context with the cause Triggered when wait returns before the scope
+// is closed. The context and its cancel function are captured up front,
+// so a later Reset does not affect the Goroutine.
+func (s *Scope) watchTrigger(wait func(ctx context.Context) error) {
+	s.mtx.Lock()
+	ctx, cancel, closing := s.ctx, s.cancel, s.closing
+	s.mtx.Unlock()
+	if isClosed(closing) {
+		return
+	}
+
+	s.spawn(func() {
+		err := wait(ctx)
+		if isClosed(closing) || ctx.Err() != nil {
+			return
+		}
+		s.log(slog.LevelInfo, "close triggered", "error", err)
+		cancel(Triggered{Err: err})
+	})
+}
@@ -0,0 +1,89 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScopeCloseOn(t *testing.T) {
+	t.Run("triggered", func(t *testing.T) {
+		s := newScope(t)
+		trigger := make(chan struct{})
+		s.CloseOn(trigger)
+
+		stop := newCall(nil)
+		s.Defer(stop.f)
+
+		close(trigger)
+		select {
+		case <-s.Ctx().Done():
+		case <-time.After(time.Second):
+			t.Fatal("expected scope context to be done")
+		}
+		if c := Cause(s.Ctx()); c != (Triggered{}) {
+			t.Fatalf("unexpected cause: %v", c)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !stop.called() {
+			t.Fatal("expected stop function to be called")
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		s := newScope(t)
+		trigger := make(chan struct{})
+		s.CloseOn(trigger)
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		close(trigger)
+		if c := Cause(s.Ctx()); c != (Manual{}) {
+			t.Fatalf("unexpected cause: %v", c)
+		}
+	})
+}
+
+func TestScopeCloseOnFunc(t *testing.T) {
+	t.Run("triggered", func(t *testing.T) {
+		s := newScope(t)
+		errLeader := errors.New("leadership lost")
+		s.CloseOnFunc(func(context.Context) error { return errLeader })
+
+		select {
+		case <-s.Ctx().Done():
+		case <-time.After(time.Second):
+			t.Fatal("expected scope context to be done")
+		}
+		c := Cause(s.Ctx())
+		if c != (Triggered{Err: errLeader}) || !errors.Is(c, errLeader) {
+			t.Fatalf("unexpected cause: %v", c)
+		}
+		if l := CauseLabel(c); l != "triggered" {
+			t.Fatalf("unexpected label: %s", l)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		s := newScope(t)
+		returned := make(chan struct{})
+		s.CloseOnFunc(func(ctx context.Context) error {
+			defer close(returned)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		<-returned
+		if c := Cause(s.Ctx()); c != (Manual{}) {
+			t.Fatalf("unexpected cause: %v", c)
+		}
+	})
+}
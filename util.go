@@ -1,21 +1,82 @@
 package scope
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"syscall"
 )
 
+// defaultSignals holds the signals, which are handled if no signals are
+// provided. On Windows, the runtime delivers the console control events
+// as these signals (see SignalChan).
+var defaultSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+
 // AwaitSignal blocks until a certain signal is received from the
 // operating system and returns the received signal. If no signals
-// are provided, it waits for SIGINT and SIGTERM.
+// are provided, it waits for the default termination signals (SIGINT
+// and SIGTERM, see SignalChan).
 func AwaitSignal(sigs ...os.Signal) os.Signal {
+	ch, stop := SignalChan(sigs...)
+	defer stop()
+	return <-ch
+}
+
+// AwaitSignalContext is like AwaitSignal, but returns the context's
+// error without a signal if the context is done before a signal is
+// received.
+func AwaitSignalContext(ctx context.Context, sigs ...os.Signal) (os.Signal, error) {
+	ch, stop := SignalChan(sigs...)
+	defer stop()
+	select {
+	case sig := <-ch:
+		return sig, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// AwaitSignal is like the function AwaitSignal, but returns nil if the
+// scope is closed or its context is done before a signal is received.
+// This allows to shut down on either a signal or a programmatic Close.
+// A received signal is recorded as the cause for closing the scope (see
+// Cause and SignalReceived).
+func (s *Scope) AwaitSignal(sigs ...os.Signal) os.Signal {
+	s.mtx.Lock()
+	ctx, closing := s.ctx, s.closing
+	s.mtx.Unlock()
+
+	ch, stop := SignalChan(sigs...)
+	defer stop()
+	select {
+	case sig := <-ch:
+		s.setCloseCause(SignalReceived{Signal: sig})
+		return sig
+	case <-closing:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+// SignalChan returns a channel, which receives the given signals from
+// the operating system, and a function to stop the notification. If no
+// signals are provided, SIGINT and SIGTERM are relayed. This allows to
+// select on signals together with other channels.
+//
+// On Windows, Ctrl+C and Ctrl+Break in the console are delivered as
+// SIGINT (os.Interrupt), and closing the console window, logging off,
+// or shutting down the system are delivered as SIGTERM. In the latter
+// cases, Windows terminates the process a few seconds after the signal
+// was received, so the shutdown should be bounded accordingly (see
+// WithShutdownTimeout). Stop requests of the Windows service control
+// manager are not signals and have to be handled by the service
+// implementation.
+func SignalChan(sigs ...os.Signal) (<-chan os.Signal, func()) {
 	if len(sigs) == 0 {
-		sigs = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+		sigs = defaultSignals
 	}
 
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, sigs...)
-	defer signal.Stop(ch)
-	return <-ch
+	return ch, func() { signal.Stop(ch) }
 }
@@ -0,0 +1,119 @@
+//go:build unix
+
+package scope
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSignalChan(t *testing.T) {
+	ch, stop := SignalChan(syscall.SIGUSR1)
+	defer stop()
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case sig := <-ch:
+		if sig != syscall.SIGUSR1 {
+			t.Fatalf("unexpected signal: %v", sig)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for signal")
+	}
+}
+
+func TestWithCancelOnSignal(t *testing.T) {
+	s := New(WithCancelOnSignal(syscall.SIGUSR2))
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case <-s.Ctx().Done():
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the context")
+	}
+	if c, ok := Cause(s.Ctx()).(SignalReceived); !ok || c.Signal != syscall.SIGUSR2 {
+		t.Fatalf("unexpected cause: %v", Cause(s.Ctx()))
+	}
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAwaitSignalContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	sig, err := AwaitSignalContext(ctx, syscall.SIGUSR1)
+	if sig != nil || err != context.DeadlineExceeded {
+		t.Fatalf("unexpected result: %v, %v", sig, err)
+	}
+
+	defer sendSignals(t, syscall.SIGUSR1)()
+	sig, err = AwaitSignalContext(context.Background(), syscall.SIGUSR1)
+	if sig != syscall.SIGUSR1 || err != nil {
+		t.Fatalf("unexpected result: %v, %v", sig, err)
+	}
+}
+
+func TestScopeAwaitSignal(t *testing.T) {
+	t.Run("signal", func(t *testing.T) {
+		s := newScope(t)
+		defer sendSignals(t, syscall.SIGUSR1)()
+		if sig := s.AwaitSignal(syscall.SIGUSR1); sig != syscall.SIGUSR1 {
+			t.Fatalf("unexpected signal: %v", sig)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c, ok := Cause(s.Ctx()).(SignalReceived); !ok || c.Signal != syscall.SIGUSR1 {
+			t.Fatalf("unexpected cause: %v", Cause(s.Ctx()))
+		}
+	})
+
+	t.Run("close", func(t *testing.T) {
+		// No test sends SIGVTALRM, so a signal sent to another
+		// test cannot be received, even if delivered late.
+		s := newScope(t)
+		time.AfterFunc(10*time.Millisecond, func() { s.Close() })
+		if sig := s.AwaitSignal(syscall.SIGVTALRM); sig != nil {
+			t.Fatalf("unexpected signal: %v", sig)
+		}
+	})
+}
+
+// sendSignals sends the given signal to the process repeatedly until
+// the returned function is called, since the receiver may not be
+// notified yet when the first signal is sent.
+func sendSignals(t *testing.T, sig syscall.Signal) func() {
+	// Keep the signal from terminating the process.
+	_, stopNotify := SignalChan(sig)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			if err := syscall.Kill(syscall.Getpid(), sig); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		stopNotify()
+	}
+}
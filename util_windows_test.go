@@ -0,0 +1,75 @@
+package scope
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const signalHelperEnv = "SCOPE_TEST_SIGNAL"
+
+func TestConsoleCtrlBreak(t *testing.T) {
+	// The helper runs in its own process group, so the console
+	// control event does not reach the test process itself.
+	cmd := exec.Command(os.Args[0], "-test.run=^TestConsoleCtrlBreakHelper$")
+	cmd.Env = append(os.Environ(), signalHelperEnv+"=1")
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cmd.Process.Kill()
+
+	r := bufio.NewReader(stdout)
+	if line, err := r.ReadString('\n'); err != nil || line != "ready\n" {
+		t.Fatalf("unexpected output: %q, %v", line, err)
+	}
+	if err := generateConsoleCtrlEvent(syscall.CTRL_BREAK_EVENT, cmd.Process.Pid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	timer := time.AfterFunc(5*time.Second, func() { cmd.Process.Kill() })
+	defer timer.Stop()
+	out, _ := io.ReadAll(r)
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out)
+	}
+	if msg := "signal=" + os.Interrupt.String(); !strings.Contains(string(out), msg) {
+		t.Fatalf("expected %q, got:\n%s", msg, out)
+	}
+}
+
+func TestConsoleCtrlBreakHelper(t *testing.T) {
+	if os.Getenv(signalHelperEnv) == "" {
+		return
+	}
+
+	// Ctrl+Break is delivered as os.Interrupt, which is one of the
+	// default signals, so the scope is closed gracefully.
+	s := New(WithCancelOnSignal())
+	fmt.Println("ready")
+	<-s.Ctx().Done()
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c, ok := Cause(s.Ctx()).(SignalReceived); ok {
+		fmt.Printf("signal=%s\n", c.Signal)
+	}
+}
+
+func generateConsoleCtrlEvent(event uint32, pid int) error {
+	proc := syscall.NewLazyDLL("kernel32.dll").NewProc("GenerateConsoleCtrlEvent")
+	if r, _, err := proc.Call(uintptr(event), uintptr(pid)); r == 0 {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package scope
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Validate checks the wiring of the services registered so far for
+// configuration problems without starting anything, e.g. in a smoke test
+// of the wiring code in CI. Together with Freeze, the services can be
+// registered without being started:
+//
+//	s.Freeze()
+//	setup(s)
+//	if err := s.Validate(); err != nil {
+//		t.Fatal(err)
+//	}
+//
+// It reports services without a start function (ErrNoStartFunc),
+// dependency cycles (ErrDependencyCycle), duplicate service names,
+// dependencies on unknown services, and services without a ready
+// function, which other services depend on (see Service.DependsOn). The
+// problems are returned as Errors.
+func (s *Scope) Validate() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var (
+		errs   Errors
+		tasks  []*task
+		byName = make(map[string][]*task)
+	)
+	for _, t := range s.tasks {
+		switch {
+		case t.deferred():
+		case t.svc.Start == nil:
+			errs.append(fmt.Errorf("%w: %s", ErrNoStartFunc, t))
+		case errors.Is(t.err, ErrDependencyCycle):
+			errs.append(t.err)
+		default:
+			tasks = append(tasks, t)
+			if t.name != "" {
+				byName[t.name] = append(byName[t.name], t)
+			}
+		}
+	}
+
+	duplicate, unready := make(map[string]bool), make(map[string]bool)
+	for _, t := range tasks {
+		if t.name != "" && len(byName[t.name]) > 1 && !duplicate[t.name] {
+			errs.append(fmt.Errorf("scope: duplicate service name %q", t.name))
+			duplicate[t.name] = true
+		}
+		for _, dep := range t.svc.DependsOn {
+			deps, ok := byName[dep]
+			switch {
+			case !ok:
+				errs.append(fmt.Errorf("scope: service %q depends on unknown service %q", t, dep))
+			case deps[0].svc.Ready == nil && !unready[dep]:
+				errs.append(fmt.Errorf("scope: service %q has no ready function, but other services depend on it", dep))
+				unready[dep] = true
+			}
+		}
+	}
+	return errs.err()
+}
@@ -0,0 +1,68 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestScopeValidate(t *testing.T) {
+	ready := func(context.Context) error { return nil }
+	start := func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		s := newScope(t)
+		s.Freeze()
+		s.Start(Service{Name: "db", Start: start, Ready: ready})
+		s.Start(Service{Name: "api", Start: start, DependsOn: []string{"db"}})
+		s.Defer(func(context.Context) error { return nil })
+
+		if err := s.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var reported []error
+		s := New(WithErrorHandler(CollectErrors(&reported)))
+		s.Freeze()
+		s.Start(Service{Name: "db", Start: start})
+		s.Start(Service{Name: "db", Start: start})
+		s.Start(Service{Name: "api", Start: start, DependsOn: []string{"db", "cache"}})
+		s.Start(Service{Name: "worker"})
+		s.Start(Service{Name: "loop", Start: start, DependsOn: []string{"loop"}})
+
+		err := s.Validate()
+		var errs Errors
+		switch {
+		case !errors.As(err, &errs) || errs.Len() != 5:
+			t.Fatalf("unexpected errors: %v", err)
+		case !errors.Is(err, ErrNoStartFunc):
+			t.Fatalf("missing start function not reported: %v", err)
+		case !errors.Is(err, ErrDependencyCycle):
+			t.Fatalf("dependency cycle not reported: %v", err)
+		}
+		for _, msg := range []string{
+			`scope: duplicate service name "db"`,
+			`scope: service "api" depends on unknown service "cache"`,
+			`scope: service "db" has no ready function, but other services depend on it`,
+		} {
+			found := false
+			for _, e := range errs {
+				found = found || e.Error() == msg
+			}
+			if !found {
+				t.Fatalf("expected error %q, got: %v", msg, err)
+			}
+		}
+		if err := closeScope(s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package scope
+
+import (
+	"context"
+	"maps"
+	"reflect"
+)
+
+// SetValue attaches a value to the contexts of all start and stop
+// functions of the scope and its child scopes (see Scope.Child), e.g. a
+// logger, trace baggage, or a tenant ID. The value is visible to the
+// functions registered before and after it was set, as soon as they
+// look it up. A value set for the same key replaces the previous one,
+// and values of the scope take precedence over the ones of its parent
+// scopes and of the base context (see WithContext). Like the keys of
+// context.WithValue, the key must be comparable and should not be of a
+// built-in type.
+func (s *Scope) SetValue(key, val any) {
+	switch {
+	case key == nil:
+		panic("scope: nil value key")
+	case !reflect.TypeOf(key).Comparable():
+		panic("scope: value key is not comparable")
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	values := make(map[any]any)
+	if m := s.values.Load(); m != nil {
+		values = maps.Clone(*m)
+	}
+	values[key] = val
+	s.values.Store(&values)
+}
+
+// withValues returns a context, which looks up the values of the scope
+// and its parents before the ones of the given context (see SetValue).
+func (s *Scope) withValues(ctx context.Context) context.Context {
+	return valuesContext{Context: ctx, s: s}
+}
+
+type valuesContext struct {
+	context.Context
+	s *Scope
+}
+
+func (c valuesContext) Value(key any) any {
+	if key == (scopeKey{}) {
+		return c.s
+	}
+	for s := c.s; s != nil; s = s.parent {
+		if m := s.values.Load(); m != nil {
+			if v, ok := (*m)[key]; ok {
+				return v
+			}
+		}
+	}
+	return c.Context.Value(key)
+}
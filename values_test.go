@@ -0,0 +1,62 @@
+package scope
+
+import (
+	"context"
+	"testing"
+)
+
+func TestScopeSetValue(t *testing.T) {
+	type key string
+
+	s := New(WithContext(context.WithValue(context.Background(), key("base"), "base")))
+	s.SetValue(key("tenant"), "a")
+
+	child := s.Child()
+	child.SetValue(key("tenant"), "b")
+
+	values := func(ctx context.Context) [3]any {
+		return [3]any{ctx.Value(key("base")), ctx.Value(key("tenant")), ctx.Value(key("later"))}
+	}
+
+	started := make(chan struct{})
+	lookup := make(chan struct{})
+	startValues := make(chan [3]any, 1)
+	s.Go(func(ctx context.Context) error {
+		close(started)
+		<-lookup
+		startValues <- values(ctx)
+		return nil
+	})
+	var stopValues, childValues [3]any
+	s.Start(Service{
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			stopValues = values(ctx)
+			return nil
+		},
+	})
+	child.Defer(func(ctx context.Context) error {
+		childValues = values(ctx)
+		return nil
+	})
+
+	<-started
+	s.SetValue(key("later"), "later")
+	close(lookup)
+	if v := <-startValues; v != [3]any{"base", "a", "later"} {
+		t.Fatalf("unexpected start values: %v", v)
+	}
+
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stopValues != [3]any{"base", "a", "later"} {
+		t.Fatalf("unexpected stop values: %v", stopValues)
+	}
+	if childValues != [3]any{"base", "b", "later"} {
+		t.Fatalf("unexpected child values: %v", childValues)
+	}
+}
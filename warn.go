@@ -0,0 +1,32 @@
+package scope
+
+import "time"
+
+// The kinds of warnings (see Warning).
+const (
+	WarnSlowStop        = "slow_stop"        // a stop function exceeded the latency threshold (see WithCancelLatencyThreshold)
+	WarnIgnoredCancel   = "ignored_cancel"   // a start function exceeded the latency threshold after the cancellation
+	WarnIgnoredContext  = "ignored_context"  // a start function did not observe its cancelled context (see WithContextCheck)
+	WarnSuppressedError = "suppressed_error" // an error was suppressed by sampling (see ReportEvery)
+	WarnQuarantined     = "quarantined"      // a service was quarantined (see Admin.Quarantine)
+	WarnMissedRun       = "missed_run"       // a scheduled run was missed, e.g. while the process was down (see Persist)
+)
+
+// Warning describes a non-fatal condition, which the scope detected for
+// a task (see WithWarningHandler). Unlike errors, warnings do not mean
+// that a task failed.
+type Warning struct {
+	Kind     string        // kind of the condition, e.g. WarnSlowStop
+	Task     TaskInfo      // task the condition was detected for
+	Duration time.Duration // duration of the slow stop function, the cancellation latency, or how long a missed run is overdue
+	Err      error         // error suppressed by sampling
+	Reason   string        // reason of the quarantine
+}
+
+// warn reports the warning to the warning handler, if any. The warning
+// has been logged before.
+func (s *Scope) warn(w Warning) {
+	if h := s.opts.warningHandler; h != nil {
+		h(w)
+	}
+}
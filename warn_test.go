@@ -0,0 +1,79 @@
+package scope
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithWarningHandler(t *testing.T) {
+	var (
+		mtx      sync.Mutex
+		warnings []Warning
+		errs     []error
+	)
+	s := New(
+		WithErrorHandler(CollectErrors(&errs)),
+		WithWarningHandler(func(w Warning) {
+			mtx.Lock()
+			warnings = append(warnings, w)
+			mtx.Unlock()
+		}),
+		WithCancelLatencyThreshold(time.Millisecond),
+	)
+
+	errTask := errors.New("task error")
+	every := ReportEvery(2)
+	for range 2 {
+		s.Go(func(context.Context) error { return errTask }, every)
+	}
+	if err := s.Wait(context.Background()); err == nil {
+		t.Fatal("error expected")
+	}
+
+	s.Start(Service{
+		Name: "worker",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Stop: func(context.Context) error {
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		},
+	})
+	ctx := context.Background()
+	if err := s.WaitFor(ctx, "worker"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.Admin().Quarantine(ctx, "worker", "misbehaving"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	kinds := make([]string, len(warnings))
+	for i, w := range warnings {
+		kinds[i] = w.Kind
+	}
+	if !slices.Equal(kinds, []string{WarnSuppressedError, WarnQuarantined, WarnSlowStop}) {
+		t.Fatalf("unexpected warnings: %v", kinds)
+	}
+	switch {
+	case warnings[0].Err != errTask:
+		t.Fatalf("unexpected suppressed error: %v", warnings[0].Err)
+	case warnings[1].Reason != "misbehaving" || warnings[1].Task.Name != "worker":
+		t.Fatalf("unexpected quarantine warning: %+v", warnings[1])
+	case warnings[2].Duration < 5*time.Millisecond:
+		t.Fatalf("unexpected stop duration: %v", warnings[2].Duration)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+}
@@ -0,0 +1,65 @@
+package scope
+
+import "sync/atomic"
+
+// Kinds of the work containers of a scope (see Stats).
+const (
+	WorkPool     = "pool"
+	WorkConsumer = "consumer"
+)
+
+// WorkStats holds the pending work of a work container, e.g. a pool or
+// a consumer. A container without pending work, i.e. whose counts are
+// zero, can be stopped without losing items.
+type WorkStats struct {
+	Queued   int // number of items waiting to be processed
+	InFlight int // number of items currently being processed
+}
+
+// Pending returns the total number of items, which are queued or in
+// flight.
+func (w WorkStats) Pending() int {
+	return w.Queued + w.InFlight
+}
+
+func (w WorkStats) add(o WorkStats) WorkStats {
+	return WorkStats{Queued: w.Queued + o.Queued, InFlight: w.InFlight + o.InFlight}
+}
+
+// workCounts counts the pending work of all containers of a kind.
+type workCounts struct {
+	queued   atomic.Int64
+	inFlight atomic.Int64
+}
+
+func (c *workCounts) stats() WorkStats {
+	return WorkStats{Queued: int(c.queued.Load()), InFlight: int(c.inFlight.Load())}
+}
+
+// work returns the pending work of the scope's containers by kind. Kinds
+// without pending work are omitted. If there is no pending work at all,
+// nil is returned.
+func (s *Scope) work() map[string]WorkStats {
+	s.mtx.Lock()
+	consumers := make([]*Consumer, 0, len(s.consumers))
+	for c := range s.consumers {
+		consumers = append(consumers, c)
+	}
+	s.mtx.Unlock()
+
+	var consumed WorkStats
+	for _, c := range consumers {
+		consumed = consumed.add(c.Stats())
+	}
+
+	var work map[string]WorkStats
+	for kind, w := range map[string]WorkStats{WorkPool: s.pools.stats(), WorkConsumer: consumed} {
+		if w.Pending() > 0 {
+			if work == nil {
+				work = make(map[string]WorkStats, 2)
+			}
+			work[kind] = w
+		}
+	}
+	return work
+}
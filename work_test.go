@@ -0,0 +1,64 @@
+package scope
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestScopeWork(t *testing.T) {
+	s := newScope(t)
+
+	block := make(chan struct{})
+	running := make(chan struct{}, 6)
+	pool := s.Pool(1)
+	for range 3 {
+		pool.Go(func(context.Context) error {
+			running <- struct{}{}
+			<-block
+			return nil
+		})
+	}
+
+	ch := make(chan int, 4)
+	for i := range 3 {
+		ch <- i
+	}
+	consumer := Consume(s, ch, func(context.Context, int) error {
+		running <- struct{}{}
+		<-block
+		return nil
+	})
+	<-running
+	<-running
+
+	if w := pool.Stats(); w != (WorkStats{Queued: 2, InFlight: 1}) {
+		t.Fatalf("unexpected pool stats: %+v", w)
+	}
+	if w := consumer.Stats(); w != (WorkStats{Queued: 2, InFlight: 1}) {
+		t.Fatalf("unexpected consumer stats: %+v", w)
+	}
+	work := s.Stats().Work
+	if w := work[WorkPool]; w != (WorkStats{Queued: 2, InFlight: 1}) {
+		t.Fatalf("unexpected pool stats: %+v", w)
+	}
+	if w := work[WorkConsumer]; w.Pending() != 3 {
+		t.Fatalf("unexpected consumer stats: %+v", w)
+	}
+
+	close(block)
+	close(ch)
+	deadline := time.Now().Add(time.Second)
+	for {
+		if work = s.Stats().Work; work == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected pending work: %+v", work)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := closeScope(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}